@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/amplia-iiot/yutil/pkg/edit"
+	"github.com/amplia-iiot/yutil/pkg/path"
+	"github.com/spf13/cobra"
+)
+
+var dOptions editOptions
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete [FILE...] PATH",
+	Short: "Delete the value in a path of yaml files",
+	Long: `Delete the value in a path of a yaml file (an entry of a mapping or
+an element of a list) with the comments right above it, changing only those
+lines: comments, key order and the rest of the format of the file are kept. The
+edited file (or stdin) is written to stdout, or several files are edited in
+place.
+
+For example:
+
+yutil delete config.yml server.debug
+yutil delete -i config.yml 'spec.containers[1]'
+yutil delete -i dev.yml prod.yml metadata.annotations
+cat config.yml | yutil delete 'metadata.labels."app.kubernetes.io/version"'
+
+A path is a list of keys separated by dots, with [n] selecting the n-th element
+of a list, and must not have wildcards. A mapping or list left empty is written
+as {} or [].
+
+The command exits with status 2 if the path is not found in a file, without
+modifying any file.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return errors.New("requires a path")
+		}
+		if _, err := path.Parse(args[len(args)-1]); err != nil {
+			return err
+		}
+		return dOptions.validate(args[:len(args)-1])
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expr := args[len(args)-1]
+		return dOptions.run(args[:len(args)-1], func(content string) (string, error) {
+			return edit.Delete(content, expr, edit.WithDocument(dOptions.document))
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+
+	dOptions.addFlags(deleteCmd)
+}
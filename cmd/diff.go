@@ -0,0 +1,210 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/pkg/diff"
+	"github.com/spf13/cobra"
+)
+
+type diffOptions struct {
+	outputFormat string
+	color        string
+	quiet        bool
+	strategy     strategyOptions
+	list         fileListOptions
+	from, to     []string
+}
+
+// Output formats of the diff command.
+const (
+	textOutput  = "text"
+	patchOutput = "patch"
+)
+
+// Color modes of the diff command.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// Exit codes of the diff command
+const (
+	exitEqual     = 0 // Both sides are equal
+	exitDifferent = 1 // There are changes
+	exitDiffError = 2 // Some file could not be read, parsed or merged
+)
+
+var dfOptions diffOptions
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff FROM TO | diff FROM... -- TO...",
+	Short: "Compare yaml files semantically",
+	Long: `Compare two yaml files semantically, writing the values added, removed
+and changed regardless of their format: key order, quotes, comments and anchors
+are ignored, aliases and merge keys are expanded and scalars are compared in
+their canonical form (0x10 is 16). Mappings are compared by key and lists by
+index, the documents of multi-document files by position.
+
+For example:
+
+yutil diff config.yml config.new.yml
+yutil diff config.yml config.json --output-format json
+yutil diff base.yml dev.yml -- base.yml prod.yml
+yutil diff conf.d -- conf.d local.yml
+yutil diff old.yml new.yml --output-format patch > changes.json
+yutil diff -q expected.yml actual.yml || echo "different"
+
+Each side can be a set of files separated by --, which are merged (as with the
+merge command and the merge section of the config file) before comparing them,
+so a merged environment can be compared with another one. Flags must go before
+the --. Directories and glob patterns are expanded into their files as with the
+merge command.
+
+The changes are written as text ("+ path: value", "- path: value" and
+"~ path: from -> to", colored when writing to a terminal), as json or as a
+RFC 6902 json patch that transforms the first side into the second one (only
+for single document files).
+
+The command exits with status 0 if both sides are equal, 1 if there are
+changes or 2 if the arguments are not valid or some file could not be read,
+parsed or merged.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return withExitCode(diffArgs(cmd, args), exitDiffError)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		changes, err := diff.CompareFiles(dfOptions.from, dfOptions.to, dfOptions.strategy.mergeOptions()...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitDiffError)
+		}
+		if !dfOptions.quiet {
+			var output string
+			switch dfOptions.outputFormat {
+			case jsonOutput:
+				output, err = diff.JSON(changes)
+			case patchOutput:
+				output, err = diff.Patch(changes)
+			default:
+				output = diff.Text(changes, colorEnabled(dfOptions.color))
+			}
+			if err == nil {
+				err = io.WriteToStdout(output)
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitDiffError)
+			}
+		}
+		if len(changes) > 0 {
+			os.Exit(exitDifferent)
+		}
+		os.Exit(exitEqual)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&dfOptions.outputFormat, "output-format", textOutput, "format of the changes (text, json or patch)")
+	diffCmd.Flags().StringVar(&dfOptions.color, "color", colorAuto, "color the text changes (auto, always or never), auto colors them when writing to a terminal unless NO_COLOR is set")
+	diffCmd.Flags().BoolVarP(&dfOptions.quiet, "quiet", "q", false, "do not write the changes, only exit with status 1 if there are changes")
+	dfOptions.strategy.addFlags(diffCmd)
+	dfOptions.list.addFlags(diffCmd, "file-", "merge")
+	diffCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(err, exitDiffError)
+	})
+}
+
+// diffArgs validates the flags and the files of the diff command.
+func diffArgs(cmd *cobra.Command, args []string) error {
+	if err := dfOptions.strategy.load(); err != nil {
+		return err
+	}
+	switch dfOptions.outputFormat {
+	case textOutput, jsonOutput, patchOutput:
+	default:
+		return fmt.Errorf("unknown output format %s, valid formats are [%s %s %s]", dfOptions.outputFormat, textOutput, jsonOutput, patchOutput)
+	}
+	switch dfOptions.color {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return fmt.Errorf("unknown color mode %s, valid modes are [%s %s %s]", dfOptions.color, colorAuto, colorAlways, colorNever)
+	}
+	if cmd.ArgsLenAtDash() < 0 && len(args) != 2 {
+		return errors.New("requires two files, or two sets of files separated by --")
+	}
+	from, to := diffSides(cmd, args)
+	var err error
+	if dfOptions.from, err = dfOptions.list.listFiles(from); err != nil {
+		return err
+	}
+	if dfOptions.to, err = dfOptions.list.listFiles(to); err != nil {
+		return err
+	}
+	if len(dfOptions.from) == 0 || len(dfOptions.to) == 0 {
+		return errors.New("requires at least one file on each side of --")
+	}
+	for _, file := range append(dfOptions.from, dfOptions.to...) {
+		if !io.Exists(file) {
+			return fmt.Errorf("file %s does not exist", file)
+		}
+	}
+	return nil
+}
+
+// diffSides returns the files of each side of the diff: the two arguments or
+// the sets of files separated by --.
+func diffSides(cmd *cobra.Command, args []string) ([]string, []string) {
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		return args[:dash], args[dash:]
+	}
+	if len(args) != 2 {
+		return nil, nil
+	}
+	return args[:1], args[1:]
+}
+
+// colorEnabled returns whether the output is colored in a color mode: always,
+// never or (auto) when stdout is a terminal and NO_COLOR is not set.
+func colorEnabled(mode string) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
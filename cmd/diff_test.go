@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"io"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestDiffInvalidArgsExitCode(t *testing.T) {
+	file := itesting.WriteFile(t, t.TempDir(), "a.yml", "a: 1\n")
+	for name, args := range map[string][]string{
+		"missing file":          {file, "missing.yml"},
+		"missing side":          {file},
+		"unknown output format": {"--output-format", "xml", file, file},
+		"unknown color mode":    {"--color", "sometimes", file, file},
+		"unknown flag":          {"--unknown", file, file},
+		"invalid strategy":      {"--list-strategy", "bogus", file, file},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := execute(t, append([]string{"diff", "--no-input"}, args...)...)
+			itesting.AssertEqual(t, exitDiffError, exitCode(err))
+		})
+	}
+}
+
+// execute runs the command line tool with some arguments, discarding its
+// output, and returns its error. The flags are reset to their defaults after
+// the test.
+func execute(t *testing.T, args ...string) error {
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	t.Cleanup(func() {
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		for _, cmd := range rootCmd.Commands() {
+			resetFlags(t, cmd)
+		}
+		resetFlags(t, rootCmd)
+	})
+	return rootCmd.Execute()
+}
+
+// resetFlags sets the changed flags of a command to their defaults.
+func resetFlags(t *testing.T, cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		var err error
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			err = slice.Replace(nil)
+		} else {
+			err = f.Value.Set(f.DefValue)
+		}
+		itesting.AssertEqual(t, nil, err)
+		f.Changed = false
+	})
+}
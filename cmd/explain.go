@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/pkg/merge"
+	"github.com/spf13/cobra"
+)
+
+type explainOptions struct {
+	strategy strategyOptions
+	list     fileListOptions
+	env      envOptions
+	files    []string
+}
+
+var xOptions explainOptions
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain PATH FILE [FILE...]",
+	Short: "Show which files set a value when merging yaml files",
+	Long: `Show the files (and lines) that set the value in a path when merging
+yaml files, from the least important to the one that prevails. Files are
+merged as with the merge command (and the merge section of the config file),
+expanding directories and glob patterns into their files.
+
+For example:
+
+yutil explain spec.replicas base.yml dev.yml prod.yml
+yutil explain 'spec.template.spec.containers[*].image' base.yml prod.yml
+yutil explain --strategy spec.containers=key base.yml prod.yml
+yutil explain --env-prefix APP db.host base.yml
+yutil explain db.host conf.d
+
+A path is a list of keys separated by dots, with [n] selecting the n-th element
+of a list and * (or [*]) matching any key or element. Dots in keys are escaped
+with a backslash or quoting the key.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := xOptions.strategy.load(); err != nil {
+			return err
+		}
+		if len(args) < 1 {
+			return errors.New("requires a path")
+		}
+		if _, err := path.Parse(args[0]); err != nil {
+			return err
+		}
+		files, err := xOptions.list.listFiles(args[1:])
+		if err != nil {
+			return err
+		}
+		xOptions.files = files
+		if len(files) < 1 && xOptions.env.prefix != "" {
+			return errors.New("requires a path and at least one file to be merged with the environment variables")
+		} else if len(files) < 2 && xOptions.env.prefix == "" {
+			return errors.New("requires a path and at least two files to be merged")
+		}
+		for _, file := range files {
+			if !io.Exists(file) {
+				return fmt.Errorf("file %s does not exist", file)
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merged, err := merge.MergeAllFilesWithProvenance(xOptions.files, append(xOptions.strategy.mergeOptions(), merge.WithEnv(xOptions.env.env()))...)
+		if err != nil {
+			return err
+		}
+		explained, err := explainPath(path.MustParse(args[0]), merged)
+		if err != nil {
+			return err
+		}
+		return io.WriteToStdout(explained)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	xOptions.strategy.addFlags(explainCmd)
+	xOptions.list.addFlags(explainCmd, "file-", "merge")
+	xOptions.env.addFlags(explainCmd, "merge")
+}
+
+// explainPath writes the origins of the values matching a path in the merged
+// documents, an error if there is none.
+func explainPath(p path.Path, merged []merge.Merged) (string, error) {
+	var sb strings.Builder
+	for i, doc := range merged {
+		var paths []string
+		for concrete := range doc.Provenance {
+			if c, err := path.Parse(concrete); err == nil && p.Match(c) {
+				paths = append(paths, concrete)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		sort.Strings(paths)
+		if len(merged) > 1 {
+			fmt.Fprintf(&sb, "# document %d\n", i+1)
+		}
+		for _, concrete := range paths {
+			if concrete == "" {
+				concrete = "."
+			}
+			fmt.Fprintf(&sb, "%s\n", concrete)
+			for _, origin := range doc.Provenance[concrete] {
+				fmt.Fprintf(&sb, "  %s: %s\n", origin, origin.Value)
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("path %s not found", p)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestMergedFilesExpandDirectories(t *testing.T) {
+	dir := t.TempDir()
+	itesting.WriteFile(t, dir, "conf.d/1-base.yml", "v: 1\n")
+	itesting.WriteFile(t, dir, "conf.d/2-dev.yml", "v: 2\n")
+	confDir := filepath.Join(dir, "conf.d")
+	local := itesting.WriteFile(t, dir, "local.yml", "v: 3\n")
+	emptyDir := t.TempDir()
+	for name, test := range map[string]struct {
+		args     []string
+		expected string
+	}{
+		"explain directory":             {args: []string{"explain", "v", confDir}},
+		"explain directory and file":    {args: []string{"explain", "v", confDir, local}},
+		"explain glob":                  {args: []string{"explain", "v", filepath.Join(confDir, "*.yml")}},
+		"explain single file":           {args: []string{"explain", "v", local}, expected: "requires a path and at least two files to be merged"},
+		"explain empty directory":       {args: []string{"explain", "v", emptyDir, local}, expected: "no files to merge in " + emptyDir},
+		"get directory":                 {args: []string{"get", "v", confDir}},
+		"get empty directory":           {args: []string{"get", "v", emptyDir}, expected: "no files to merge in " + emptyDir},
+		"diff empty directory":          {args: []string{"diff", emptyDir, local}, expected: "no files to merge in " + emptyDir},
+		"diff empty directory after --": {args: []string{"diff", local, "--", emptyDir}, expected: "no files to merge in " + emptyDir},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := execute(t, append([]string{test.args[0], "--no-input"}, test.args[1:]...)...)
+			itesting.AssertError(t, test.expected, err)
+		})
+	}
+	t.Run("diff sides", func(t *testing.T) {
+		t.Cleanup(func() { dfOptions.from, dfOptions.to = nil, nil })
+		itesting.AssertEqual(t, nil, diffArgs(diffCmd, []string{confDir, local}))
+		itesting.AssertDeepEqual(t, []string{filepath.Join(confDir, "1-base.yml"), filepath.Join(confDir, "2-dev.yml")}, dfOptions.from)
+		itesting.AssertDeepEqual(t, []string{local}, dfOptions.to)
+	})
+}
@@ -25,26 +25,56 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/amplia-iiot/yutil/internal/io"
 	"github.com/amplia-iiot/yutil/pkg/format"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 type formatOptions struct {
-	outputFile string
-	inPlace    bool
-	suffix     string
+	outputFile   string
+	outputFormat string
+	inPlace      bool
+	suffix       string
+	check        bool
+	include      []string
+	exclude      []string
+	jobs         int
+	style        styleOptions
 }
 
+// styleOptions are the options that configure how yaml is written, shared by
+// the commands that write formatted yaml. They are configured in the format
+// section of the config file.
+type styleOptions struct {
+	order           string
+	priority        []string
+	indent          int
+	indentSequences bool
+	width           int
+	quote           string
+	escapeNonASCII  bool
+	anchors         string
+	options         format.Options
+}
+
+// Exit codes of the check mode
+const (
+	exitFormatted    = 0 // All files are formatted
+	exitNotFormatted = 1 // Some file needs formatting
+	exitCheckError   = 2 // The options are not valid or some file could not be read or parsed
+)
+
 var fOptions formatOptions
 
 // formatCmd represents the format command
 var formatCmd = &cobra.Command{
-	Use:   "format [FILE...]",
+	Use:   "format [FILE|DIRECTORY...]",
 	Short: "Format a yaml file",
-	Long: `Format a yaml file ordering its keys alphabetically and
-cleaning it.
+	Long: `Format a yaml file ordering its keys (alphabetically by default)
+and cleaning it.
 
 For example:
 
@@ -52,49 +82,73 @@ yutil format file.yml
 yutil format file.yml -o file.formatted.yml
 cat file.yml | yutil format > file.formatted.yml
 echo "this is not a yaml" | yutil --no-input format file.yml > file.formatted.yml
+yutil format --check file1.yml file2.yml
+yutil format -i ./deploy
+yutil format -i -j 4 ./deploy
+yutil format -i README.md
+yutil format --check --include '*.md' ./docs
+yutil format --check ./deploy --exclude '*/generated/*'
+yutil format --order source --priority apiVersion,kind,metadata file.yml
+yutil format --indent 4 --indent-sequences --width 80 --quote double file.yml
+yutil format --anchors preserve file.yml
+yutil format --output-format json file.yml
+
+The check mode does not modify any file, it prints the diff needed to format
+each file and exits with status 0 if all files are formatted, 1 if some file
+needs formatting or 2 if the options are not valid or some file could not be
+read or parsed.
+
+Directories can be formatted in place or checked, formatting the files inside
+them (recursively) that match the include patterns (*.yml and *.yaml by
+default) and do not match the exclude patterns. Files are formatted in place in
+parallel (--jobs), every file is formatted regardless of errors in others.
+
+Json and toml files (detected by their extension or content) are formatted
+keeping their format, and any file can be written as yaml, json or toml with
+--output-format. Json and toml lose the comments, toml also the key order.
+
+Only the yaml of markdown files (.md or .markdown) is formatted: the front
+matter and the fenced code blocks with yaml or yml info string.
+
+Keys are ordered alphabetically or kept in source order (--order), after the
+priority keys (--priority). Key orders for specific paths can be configured in
+the format section of the config file, as any other format option.
 `,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if inPlaceEnabled(cmd) {
-			if canAccessStdin() {
-				return errors.New("stdin not compatible with in place format")
-			}
-			if fOptions.outputFile != "" {
-				return errors.New("output option not compatible with in place format")
-			}
-		} else {
-			if canAccessStdin() && len(args) != 0 {
-				return errors.New("only one yaml can be formatted to output, stdin is active")
-			} else if !canAccessStdin() && len(args) == 0 {
-				if stdinBlocked() {
-					return errors.New("requires one file to be formatted, stdin is blocked")
-				} else {
-					return errors.New("requires one file to be formatted")
-				}
-			} else if !canAccessStdin() && len(args) != 1 {
-				return errors.New("only one file can be formatted to output")
-			}
-		}
-		for _, file := range args {
-			if !io.Exists(file) {
-				return fmt.Errorf("file %s does not exist", file)
-			}
+		err := formatArgs(cmd, args)
+		if fOptions.check {
+			// Invalid options are check errors
+			return withExitCode(err, exitCheckError)
 		}
-		return nil
+		return err
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if fOptions.check {
+			os.Exit(checkFormat(args))
+		}
 		var err error
+		opts := fOptions.style.formatOptions()
 		if inPlaceEnabled(cmd) {
+			var files []string
+			files, err = format.ListFiles(args, fOptions.include, fOptions.exclude)
+			if err != nil {
+				panic(err)
+			}
+			opts = append(opts, format.WithWorkers(fOptions.jobs))
 			if fOptions.suffix == "" {
-				err = format.FormatFilesInPlace(args)
+				_, err = format.FormatFilesInPlace(files, opts...)
 			} else {
-				err = format.FormatFilesInPlaceB(args, fOptions.suffix)
+				_, err = format.FormatFilesInPlaceB(files, fOptions.suffix, opts...)
 			}
 		} else {
+			if fOptions.outputFormat != "" {
+				opts = append(opts, format.WithOutputFormat(format.FileFormat(fOptions.outputFormat)))
+			}
 			var formatted string
 			if canAccessStdin() {
-				formatted, err = format.FormatStdin()
+				formatted, err = format.FormatStdin(opts...)
 			} else {
-				formatted, err = format.FormatFile(args[0])
+				formatted, err = format.FormatFile(args[0], opts...)
 			}
 			if err != nil {
 				panic(err)
@@ -115,11 +169,175 @@ func init() {
 	rootCmd.AddCommand(formatCmd)
 
 	formatCmd.Flags().StringVarP(&fOptions.outputFile, "output", "o", "", "format yaml to output file instead of stdout (not compatible in place format)")
+	formatCmd.Flags().StringVar(&fOptions.outputFormat, "output-format", "", "format of the output (yaml, json or toml), by default files keep their format and stdin is written as yaml")
 	formatCmd.Flags().BoolVarP(&fOptions.inPlace, "in-place", "i", false, "format yaml files in place (makes backup if suffix is supplied)")
 	formatCmd.Flags().StringVarP(&fOptions.suffix, "suffix", "s", "", "format yaml files in place making a backup with the given suffix (-i is not necessary if suffix is passed)")
+	formatCmd.Flags().BoolVarP(&fOptions.check, "check", "c", false, "check whether yaml files are formatted printing the needed changes as a diff, without modifying them (exit status 1 if not formatted, 2 on error)")
+	formatCmd.Flags().StringSliceVar(&fOptions.include, "include", []string{}, "format the files inside directories that match the filter/s (defaults to *.yml and *.yaml)")
+	formatCmd.Flags().StringSliceVar(&fOptions.exclude, "exclude", []string{}, "do not format the files inside directories that match the filter/s (takes precedence over include)")
+	formatCmd.Flags().IntVarP(&fOptions.jobs, "jobs", "j", 0, "number of files formatted in place in parallel (defaults to the number of CPUs)")
+	fOptions.style.addFlags(formatCmd)
+	formatCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		if fOptions.check {
+			return withExitCode(err, exitCheckError)
+		}
+		return err
+	})
+	onViperInitialize(func() {
+		bindViperC(formatCmd, "jobs", "format.jobs")
+		bindViperC(formatCmd, "include", "format.include")
+		bindViperC(formatCmd, "exclude", "format.exclude")
+	})
+}
+
+// formatArgs validates the flags and the files of the format command.
+func formatArgs(cmd *cobra.Command, args []string) error {
+	if err := fOptions.style.load(); err != nil {
+		return err
+	}
+	if err := format.FileFormat(fOptions.outputFormat).Validate(); err != nil {
+		return err
+	}
+	if fOptions.outputFormat != "" && (fOptions.check || inPlaceEnabled(cmd)) {
+		return errors.New("output format not compatible with check nor in place format, files keep their format")
+	}
+	if fOptions.check {
+		if inPlaceEnabled(cmd) {
+			return errors.New("check not compatible with in place format")
+		}
+		if fOptions.outputFile != "" {
+			return errors.New("output option not compatible with check")
+		}
+		if canAccessStdin() && len(args) != 0 {
+			return errors.New("only stdin can be checked, stdin is active")
+		} else if !canAccessStdin() && len(args) == 0 {
+			if stdinBlocked() {
+				return errors.New("requires at least one file to be checked, stdin is blocked")
+			} else {
+				return errors.New("requires at least one file to be checked")
+			}
+		}
+		// Missing files are reported as check errors
+		return nil
+	}
+	if inPlaceEnabled(cmd) {
+		if canAccessStdin() {
+			return errors.New("stdin not compatible with in place format")
+		}
+		if fOptions.outputFile != "" {
+			return errors.New("output option not compatible with in place format")
+		}
+	} else {
+		if canAccessStdin() && len(args) != 0 {
+			return errors.New("only one yaml can be formatted to output, stdin is active")
+		} else if !canAccessStdin() && len(args) == 0 {
+			if stdinBlocked() {
+				return errors.New("requires one file to be formatted, stdin is blocked")
+			} else {
+				return errors.New("requires one file to be formatted")
+			}
+		} else if !canAccessStdin() && len(args) != 1 {
+			return errors.New("only one file can be formatted to output")
+		}
+	}
+	for _, file := range args {
+		if !io.Exists(file) {
+			return fmt.Errorf("file %s does not exist", file)
+		}
+		if !inPlaceEnabled(cmd) && isDir(file) {
+			return fmt.Errorf("%s is a directory, directories can only be formatted in place or checked", file)
+		}
+	}
+	return nil
+}
+
+// addFlags adds the style flags to a command, binding them to the format
+// section of the config file.
+func (s *styleOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.order, "order", string(format.Alphabetical), "order of the keys (alphabetical or source)")
+	cmd.Flags().StringSliceVar(&s.priority, "priority", []string{}, "keys that go first, in the given order, before the rest of keys")
+	cmd.Flags().IntVar(&s.indent, "indent", 2, "spaces of each indentation level (from 2 to 9)")
+	cmd.Flags().BoolVar(&s.indentSequences, "indent-sequences", false, "indent sequences inside mappings (by default the dash is aligned with the parent key)")
+	cmd.Flags().IntVar(&s.width, "width", format.DefaultWidth, "maximum line width, longer strings are folded when possible (0 never folds)")
+	cmd.Flags().StringVar(&s.quote, "quote", string(format.AutoQuote), "preferred quotes for strings that need them (auto, single or double)")
+	cmd.Flags().BoolVar(&s.escapeNonASCII, "escape-non-ascii", false, "escape non ASCII characters in strings")
+	cmd.Flags().StringVar(&s.anchors, "anchors", string(format.ExpandAnchors), "how anchors, aliases and merge keys are written (expand, preserve or dedupe)")
+	onViperInitialize(func() {
+		bindViperC(cmd, "order", "format.order")
+		bindViperC(cmd, "priority", "format.priority")
+		bindViperC(cmd, "indent", "format.indent")
+		bindViperC(cmd, "indent-sequences", "format.indent-sequences")
+		bindViperC(cmd, "width", "format.width")
+		bindViperC(cmd, "quote", "format.quote")
+		bindViperC(cmd, "escape-non-ascii", "format.escape-non-ascii")
+		bindViperC(cmd, "anchors", "format.anchors")
+	})
+}
+
+// load builds and validates the format options from the flags and the key
+// orders of specific paths in the config file.
+func (s *styleOptions) load() error {
+	s.options = format.Options{
+		KeyOrder: format.KeyOrder{
+			Order:    format.Order(s.order),
+			Priority: s.priority,
+		},
+		Indent:          s.indent,
+		IndentSequences: s.indentSequences,
+		Width:           s.width,
+		Quote:           format.Quote(s.quote),
+		EscapeNonASCII:  s.escapeNonASCII,
+		Anchors:         format.Anchors(s.anchors),
+	}
+	if err := viper.UnmarshalKey("format.paths", &s.options.KeyOrder.Paths); err != nil {
+		return fmt.Errorf("invalid format.paths config: %w", err)
+	}
+	return s.options.Validate()
+}
+
+// formatOptions returns the loaded format options.
+func (s *styleOptions) formatOptions() []format.Option {
+	return []format.Option{format.WithOptions(s.options)}
+}
+
+// isDir returns whether a path is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
 
 // Whether in place format is enabled
 func inPlaceEnabled(cmd *cobra.Command) bool {
 	return fOptions.inPlace || cmd.Flags().Changed("suffix")
 }
+
+// checkFormat prints the diff needed to format each file (or stdin) and
+// returns the exit code.
+func checkFormat(files []string) int {
+	var results []format.FileResult
+	if canAccessStdin() {
+		results = append(results, format.CheckStdin(fOptions.style.formatOptions()...))
+	} else {
+		files, err := format.ListFiles(files, fOptions.include, fOptions.exclude)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitCheckError
+		}
+		results, _ = format.CheckFiles(files, fOptions.style.formatOptions()...)
+	}
+	code := exitFormatted
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s - %s\n", r.File, r.Err)
+			code = exitCheckError
+		} else if !r.Formatted {
+			if err := io.WriteToStdout(r.Diff); err != nil {
+				panic(err)
+			}
+			if code == exitFormatted {
+				code = exitNotFormatted
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestFormatCheckInvalidOptionsExitCode(t *testing.T) {
+	file := itesting.WriteFile(t, t.TempDir(), "a.yml", "a: 1\n")
+	for name, args := range map[string][]string{
+		"invalid indent":        {"--indent", "1"},
+		"unknown order":         {"--order", "bogus"},
+		"unknown output format": {"--output-format", "xml"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := execute(t, append([]string{"format", "--no-input", "--check", file}, args...)...)
+			itesting.AssertEqual(t, exitCheckError, exitCode(err))
+		})
+		t.Run(name+" without check", func(t *testing.T) {
+			err := execute(t, append([]string{"format", "--no-input", file}, args...)...)
+			itesting.AssertEqual(t, 1, exitCode(err))
+		})
+	}
+	t.Run("unknown flag", func(t *testing.T) {
+		err := execute(t, "format", "--no-input", "--check", "--bogus", file)
+		itesting.AssertEqual(t, exitCheckError, exitCode(err))
+	})
+	t.Run("unknown flag without check", func(t *testing.T) {
+		err := execute(t, "format", "--no-input", "--bogus", file)
+		itesting.AssertEqual(t, 1, exitCode(err))
+	})
+	t.Run("invalid flag value", func(t *testing.T) {
+		err := execute(t, "format", "--no-input", "-c", "--indent", "two", file)
+		itesting.AssertEqual(t, exitCheckError, exitCode(err))
+	})
+	t.Run("check in place", func(t *testing.T) {
+		err := execute(t, "format", "--no-input", "--check", "--in-place", file)
+		itesting.AssertEqual(t, exitCheckError, exitCode(err))
+	})
+}
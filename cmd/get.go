@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
+	"github.com/amplia-iiot/yutil/pkg/merge"
+	"github.com/amplia-iiot/yutil/pkg/path"
+	"github.com/spf13/cobra"
+)
+
+type getOptions struct {
+	outputFormat string
+	defaultValue string
+	style        styleOptions
+	strategy     strategyOptions
+	list         fileListOptions
+	files        []string
+}
+
+// Output formats of the get command.
+const (
+	yamlOutput = "yaml"
+	jsonOutput = "json"
+	rawOutput  = "raw"
+)
+
+// Exit status of the get command when the path is not found.
+const exitPathNotFound = 2
+
+var gOptions getOptions
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   "get PATH [FILE...]",
+	Short: "Get the value in a path of yaml files",
+	Long: `Get the value in a path of a yaml file or of the result of merging
+several yaml files (as with the merge command and the merge section of the
+config file, expanding directories and glob patterns). Stdin is read as the
+first file.
+
+For example:
+
+yutil get spec.replicas deployment.yml
+yutil get 'spec.template.spec.containers[0].image' base.yml prod.yml
+yutil get 'metadata.labels."app.kubernetes.io/name"' deployment.yml
+yutil get 'spec.containers[*].name' --output-format raw deployment.yml
+cat config.json | yutil get db --output-format json
+yutil get db.port --default 5432 config.yml
+
+A path is a list of keys separated by dots, with [n] selecting the n-th element
+of a list and * (or [*]) matching any key or element. Dots in keys are escaped
+with a backslash or quoting the key.
+
+The value is written without comments as yaml (formatted with the same options
+as the format command), as json or raw: scalars without quotes and any other
+value as yaml.
+Each value matching a path with wildcards is written as a yaml document, a json
+value or a raw line.
+
+The command exits with status 2 if there is no value in the path, unless a
+--default value is passed, which is written instead (with its type inferred).
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := gOptions.style.load(); err != nil {
+			return err
+		}
+		if err := gOptions.strategy.load(); err != nil {
+			return err
+		}
+		switch gOptions.outputFormat {
+		case yamlOutput, jsonOutput, rawOutput:
+		default:
+			return fmt.Errorf("unknown output format %s, valid formats are [%s %s %s]", gOptions.outputFormat, yamlOutput, jsonOutput, rawOutput)
+		}
+		if len(args) < 1 {
+			return errors.New("requires a path")
+		}
+		if _, err := path.Parse(args[0]); err != nil {
+			return err
+		}
+		files, err := gOptions.list.listFiles(args[1:])
+		if err != nil {
+			return err
+		}
+		gOptions.files = files
+		if len(files) < 1 && !canAccessStdin() {
+			return errors.New("requires a path and at least one file")
+		}
+		for _, file := range files {
+			if !io.Exists(file) {
+				return fmt.Errorf("file %s does not exist", file)
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var docs []*yaml.Node
+		var err error
+		opts := gOptions.strategy.mergeOptions()
+		if canAccessStdin() {
+			docs, err = merge.MergeStdinWithFilesToDocuments(gOptions.files, opts...)
+		} else {
+			docs, err = merge.MergeAllFilesToDocuments(gOptions.files, opts...)
+		}
+		if err != nil {
+			return err
+		}
+		var values []*yaml.Node
+		for _, m := range path.MustParse(args[0]).SelectAll(docs) {
+			values = append(values, m.Value)
+		}
+		if len(values) == 0 {
+			if !cmd.Flags().Changed("default") {
+				fmt.Fprintf(os.Stderr, "path %s not found\n", args[0])
+				os.Exit(exitPathNotFound)
+			}
+			values = append(values, yaml.InferValue(gOptions.defaultValue))
+		}
+		output, err := writeValues(values, gOptions.outputFormat, gOptions.style.formatOptions())
+		if err != nil {
+			return err
+		}
+		return io.WriteToStdout(output)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+
+	getCmd.Flags().StringVar(&gOptions.outputFormat, "output-format", yamlOutput, "format of the value (yaml, json or raw)")
+	getCmd.Flags().StringVar(&gOptions.defaultValue, "default", "", "value written if there is no value in the path, instead of failing with exit status 2")
+	gOptions.style.addFlags(getCmd)
+	gOptions.strategy.addFlags(getCmd)
+	gOptions.list.addFlags(getCmd, "file-", "merge")
+}
+
+// writeValues writes values in an output format, formatted with the format
+// options and without comments.
+func writeValues(values []*yaml.Node, output string, opts []format.Option) (string, error) {
+	opts = append(opts, format.WithAnchors(format.ExpandAnchors))
+	if output == jsonOutput {
+		opts = append(opts, format.WithOutputFormat(format.JSON))
+	}
+	var sb strings.Builder
+	var docs []*yaml.Node
+	flush := func() error {
+		if len(docs) == 0 {
+			return nil
+		}
+		formatted, err := format.FormatDocuments(docs, opts...)
+		docs = nil
+		sb.WriteString(formatted)
+		return err
+	}
+	for _, v := range values {
+		if output == rawOutput && v.Kind == yaml.ScalarNode {
+			if err := flush(); err != nil {
+				return "", err
+			}
+			sb.WriteString(v.Value + "\n")
+			continue
+		}
+		// The comments of the source are not part of the value
+		docs = append(docs, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{yaml.Bare(v)}})
+	}
+	if err := flush(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
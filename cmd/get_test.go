@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/path"
+)
+
+func TestWriteValuesWithoutComments(t *testing.T) {
+	docs, err := yaml.ParseNodes("# Image\nimage:\n  # The tag\n  tag: v1 # current\n  base: &b {name: app} # base\n  alias: *b\n")
+	itesting.AssertEqual(t, nil, err)
+	for _, test := range []struct {
+		path     string
+		output   string
+		expected string
+	}{
+		{path: "image.tag", output: yamlOutput, expected: "v1\n"},
+		{path: "image.tag", output: rawOutput, expected: "v1\n"},
+		{path: "image.alias", output: yamlOutput, expected: "name: app\n"},
+		{path: "image", output: yamlOutput, expected: "alias:\n  name: app\nbase:\n  name: app\ntag: v1\n"},
+	} {
+		t.Run(test.path+" "+test.output, func(t *testing.T) {
+			var values []*yaml.Node
+			for _, m := range path.MustParse(test.path).SelectAll(docs) {
+				values = append(values, m.Value)
+			}
+			output, err := writeValues(values, test.output, nil)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, output)
+		})
+	}
+	// The source is not modified
+	tag := path.MustParse("image.tag").SelectAll(docs)[0].Value
+	itesting.AssertEqual(t, "# current", tag.LineComment)
+}
@@ -25,15 +25,76 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/path"
 
+	"github.com/amplia-iiot/yutil/pkg/format"
 	"github.com/amplia-iiot/yutil/pkg/merge"
+	"github.com/amplia-iiot/yutil/pkg/replace"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 type mergeOptions struct {
-	outputFile string
+	outputFile   string
+	outputFormat string
+	explain      bool
+	strict       bool
+	strictKeys   bool
+	style        styleOptions
+	strategy     strategyOptions
+	list         fileListOptions
+	env          envOptions
+	sets         setOptions
+	files        []string
+}
+
+// strategyOptions are the options that configure how documents and lists are
+// merged, shared by the commands that merge yaml. They are configured in the
+// merge section of the config file.
+type strategyOptions struct {
+	documents  string
+	keys       []string
+	lists      string
+	listKey    string
+	strategies []string
+	policy     merge.ListMerge
+	nullDelete bool
+	protected  []string
+}
+
+// fileListOptions are the options that expand directories and glob patterns
+// into the files to merge.
+type fileListOptions struct {
+	order   string
+	names   []string
+	include []string
+	exclude []string
+}
+
+// envOptions are the options of the overlay of environment variables.
+type envOptions struct {
+	prefix    string
+	separator string
+}
+
+// setOptions are the values set in paths after merging, as PATH=VALUE.
+type setOptions struct {
+	values  []string
+	strings []string
+	jsons   []string
+	files   []string
+	parsed  []assignment
+}
+
+// assignment is a parsed value set in a path.
+type assignment struct {
+	flag  string
+	path  string
+	value string
 }
 
 var mOptions mergeOptions
@@ -53,14 +114,111 @@ yutil merge base.yml changes.yml important.yml
 yutil merge base.yml changes.yml -o merged.yml
 cat base.yml | yutil merge changes.yml > merged.yml
 echo "this is not a yaml" | yutil --no-input merge base.yml changes.yml
+yutil merge --order source base.yml changes.yml
+yutil merge --documents key --document-keys kind,metadata.name base.yml changes.yml
+yutil merge --list-strategy union base.yml changes.yml
+yutil merge --strategy spec.containers=key:name --strategy allow=append base.yml changes.yml
+yutil merge --explain base.yml dev.yml prod.yml
+yutil merge --null-deletes base.yml changes.yml
+yutil merge --strict-keys base.yml overlay.yml
+yutil merge --protected security,image.registry base.yml overlay.yml
+yutil merge conf.d
+yutil merge --output-format json base.json overrides.toml local.yml
+APP__DB__HOST=db.local yutil merge --env-prefix APP base.yml prod.yml
+yutil merge --set image.tag=v2 --set 'spec.containers[0].replicas=3' base.yml prod.yml
+yutil merge --set-string version=1.10 --set-json 'ports=[80, 443]' --set-file ca=ca.pem base.yml
+yutil merge --file-order natural base.yml 'overlays/*.yml'
+yutil merge --file-order explicit --file-names 'base.*,team-*' conf.d
+
+The merged yaml is formatted with the same options as the format command (and
+the format section of the config file). In source order the keys of the first
+file go first and new keys are added after them.
+
+The documents of multi-document files are merged by position (index), by the
+values of some identifying keys (key, with kind and metadata.name by default),
+written one after the other (concat) or merged into a single document (flatten).
+
+Lists are replaced by default. Other list strategies add the items of the list
+after the previous items (append), before them (prepend), only the items that
+are not already there (union) or merge each item with the previous item with
+the same value in a key (key, with name by default). The strategy can be set
+for every list (--list-strategy) and for the lists in specific paths
+(--strategy PATH=STRATEGY[:KEY], and the merge.strategies config).
+
+A null value replaces the previous value unless --null-deletes is passed, then
+it deletes the key (and everything under it) as in a JSON merge patch.
+
+The strict mode (--strict) fails with exit status 1, listing the conflicts with
+their file, line and path, if a value replaces a value of a different kind (a
+mapping replaced by a scalar, for example). With --strict-keys it also fails if
+a key is not in the previous files, to catch typos in overlays.
+
+Protected values cannot be changed by the files merged after the first file
+that has them, the merge fails with exit status 1 naming the file that changes
+one. Values are protected by path (--protected and the merge.protected config,
+with wildcards) or with the !locked tag, which is removed from the merged yaml.
+
+Files may contain merge directives, removed from the merged yaml: !replace (or
+$patch: replace) replaces a value instead of merging it, !delete (or $patch:
+delete) deletes a key or list item and $retainKeys keeps only the listed keys.
+
+The environment variables named with the --env-prefix followed by the
+--env-separator (__ by default) are merged after every file, as the most
+important yaml. APP__DB__HOST=x sets db.host with prefix APP, the keys match
+the merged keys ignoring case and new keys are lower case. The type of the
+value is inferred (APP__DB__PORT=5432 is an int, APP__DEBUG=true a bool) and a
+value in flow style is a list or a mapping (APP__PORTS='[80, 443]'). With the
+overlay a single file can be merged.
+
+The values of --set PATH=VALUE (with the type inferred), --set-string (always a
+string), --set-json (a json value) and --set-file (the content of a file as
+string) are set after merging the files and environment variables, in this
+order, as the last layer. The missing keys of the path are added and an index
+one past the end of a list ([n]) appends the value. Dots and brackets in keys
+are escaped with a backslash (a\.b) or quoting the key. With values set a
+single file can be merged.
+
+The explain mode writes the file and line that set each value as a comment next
+to it. To see every file that set a value use the explain command.
+
+The yaml of markdown files (.md or .markdown) is their front matter. Json and
+toml files are detected by their extension or content (toml does not keep the
+order of the keys). The merged output is yaml unless --output-format is json or
+toml, which lose the comments.
+
+Directories (like conf.d) and glob patterns are expanded into the yaml files
+(*.yml and *.yaml unless --file-include is passed) inside them, walking the
+directories recursively. The files of each directory or pattern are merged in
+lexical order by default (10-a.yml before 9-b.yml), in natural order comparing
+numbers by value (9-b.yml before 10-a.yml) or in explicit order, following the
+list of name patterns in --file-names (the files that match none go last).
 `,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if canAccessStdin() && len(args) < 1 {
+		if err := mOptions.style.load(); err != nil {
+			return err
+		}
+		if err := mOptions.strategy.load(); err != nil {
+			return err
+		}
+		if err := format.FileFormat(mOptions.outputFormat).Validate(); err != nil {
+			return err
+		}
+		if err := mOptions.sets.load(); err != nil {
+			return err
+		}
+		files, err := mOptions.list.listFiles(args)
+		if err != nil {
+			return err
+		}
+		mOptions.files = files
+		if canAccessStdin() && len(files) < 1 {
 			return errors.New("requires at least one file to be merged with stdin")
-		} else if !canAccessStdin() && len(args) < 2 {
+		} else if !canAccessStdin() && len(files) < 1 && mOptions.overlays() {
+			return errors.New("requires at least one file to be merged with the environment variables or values set")
+		} else if !canAccessStdin() && len(files) < 2 && !mOptions.overlays() {
 			return errors.New("requires at least two files to be merged")
 		}
-		for _, file := range args {
+		for _, file := range files {
 			if !io.Exists(file) {
 				return fmt.Errorf("file %s does not exist", file)
 			}
@@ -68,12 +226,29 @@ echo "this is not a yaml" | yutil --no-input merge base.yml changes.yml
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		args = mOptions.files
 		var err error
 		var merged string
+		opts := append(mOptions.strategy.mergeOptions(),
+			merge.WithFormat(append(mOptions.style.formatOptions(), format.WithOutputFormat(format.FileFormat(mOptions.outputFormat)))...),
+			merge.WithExplain(mOptions.explain),
+			merge.WithEnv(mOptions.env.env()),
+			merge.WithStrict(merge.Strict{
+				TypeConflicts: mOptions.strict || mOptions.strictKeys,
+				UnknownKeys:   mOptions.strictKeys,
+			}),
+		)
+		opts = append(opts, mOptions.sets.mergeOptions()...)
 		if canAccessStdin() {
-			merged, err = merge.MergeStdinWithFiles(args)
+			merged, err = merge.MergeStdinWithFiles(args, opts...)
 		} else {
-			merged, err = merge.MergeAllFiles(args)
+			merged, err = merge.MergeAllFiles(args, opts...)
+		}
+		var strictErr *merge.StrictError
+		var protectedErr *merge.ProtectedError
+		if errors.As(err, &strictErr) || errors.As(err, &protectedErr) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
 		if err != nil {
 			panic(err)
@@ -93,7 +268,229 @@ func init() {
 	rootCmd.AddCommand(mergeCmd)
 
 	mergeCmd.Flags().StringVarP(&mOptions.outputFile, "output", "o", "", "write merged yaml to output file instead of stdout")
+	mergeCmd.Flags().StringVar(&mOptions.outputFormat, "output-format", string(format.YAML), "format of the merged output (yaml, json or toml)")
+	mergeCmd.Flags().BoolVar(&mOptions.explain, "explain", false, "write the file and line that set each value as a comment next to it")
+	mergeCmd.Flags().BoolVar(&mOptions.strict, "strict", false, "fail (exit status 1) if a value replaces a value of a different kind (mapping, list or scalar)")
+	mergeCmd.Flags().BoolVar(&mOptions.strictKeys, "strict-keys", false, "fail (exit status 1) as in strict mode, also if a key is not in the previous files")
+	mOptions.style.addFlags(mergeCmd)
+	mOptions.strategy.addFlags(mergeCmd)
+	mOptions.list.addFlags(mergeCmd, "file-", "merge")
+	mOptions.env.addFlags(mergeCmd, "merge")
+	mOptions.sets.addFlags(mergeCmd)
 	onViperInitialize(func() {
 		bindViperC(mergeCmd, "output", "merge.output")
+		bindViperC(mergeCmd, "output-format", "merge.output-format")
+		bindViperC(mergeCmd, "strict", "merge.strict")
+		bindViperC(mergeCmd, "strict-keys", "merge.strict-keys")
+	})
+}
+
+// addFlags adds the strategy flags to a command, binding them to the merge
+// section of the config file.
+func (s *strategyOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.documents, "documents", string(merge.ByIndex), "strategy to merge the documents of multi-document files (index, key, concat or flatten)")
+	cmd.Flags().StringSliceVar(&s.keys, "document-keys", []string{}, "paths of the keys that identify a document with the key strategy (defaults to kind,metadata.name)")
+	cmd.Flags().StringVar(&s.lists, "list-strategy", string(merge.ReplaceList), "strategy to merge lists (replace, append, prepend, union or key)")
+	cmd.Flags().StringVar(&s.listKey, "list-key", merge.DefaultListKey, "path of the key that identifies the items of lists with the key strategy")
+	cmd.Flags().StringArrayVar(&s.strategies, "strategy", []string{}, "strategy to merge the lists in a path as PATH=STRATEGY[:KEY] (takes precedence over the merge.strategies config)")
+	cmd.Flags().BoolVar(&s.nullDelete, "null-deletes", false, "a null value deletes the key from the previous files (JSON merge patch) instead of replacing its value")
+	cmd.Flags().StringSliceVar(&s.protected, "protected", []string{}, "paths whose values cannot be changed by the files after the first file that has them, which makes the merge fail")
+	onViperInitialize(func() {
+		bindViperC(cmd, "protected", "merge.protected")
+		bindViperC(cmd, "null-deletes", "merge.null-deletes")
+		bindViperC(cmd, "documents", "merge.documents")
+		bindViperC(cmd, "document-keys", "merge.document-keys")
+		bindViperC(cmd, "list-strategy", "merge.list-strategy")
+		bindViperC(cmd, "list-key", "merge.list-key")
+	})
+}
+
+// load validates the document strategy and builds and validates the list merge
+// policy from the flags and the strategies of specific paths in the config
+// file.
+func (s *strategyOptions) load() error {
+	if err := merge.DocumentStrategy(s.documents).Validate(); err != nil {
+		return err
+	}
+	s.policy = merge.ListMerge{
+		Strategy: merge.ListStrategy(s.lists),
+		Key:      s.listKey,
+	}
+	for _, str := range s.strategies {
+		p, err := parsePathListMerge(str)
+		if err != nil {
+			return err
+		}
+		s.policy.Paths = append(s.policy.Paths, p)
+	}
+	var config []merge.PathListMerge
+	if err := viper.UnmarshalKey("merge.strategies", &config); err != nil {
+		return fmt.Errorf("invalid merge.strategies config: %w", err)
+	}
+	s.policy.Paths = append(s.policy.Paths, config...)
+	return s.policy.Validate()
+}
+
+// mergeOptions returns the loaded strategy options.
+func (s *strategyOptions) mergeOptions() []merge.Option {
+	return []merge.Option{
+		merge.WithDocumentStrategy(merge.DocumentStrategy(s.documents)),
+		merge.WithDocumentKeys(s.keys...),
+		merge.WithListMerge(s.policy),
+		merge.WithNullDeletes(s.nullDelete),
+		merge.WithProtectedPaths(s.protected...),
+	}
+}
+
+// addFlags adds the flags that expand directories and glob patterns to a
+// command, named with a prefix and bound to a section of the config file.
+func (l *fileListOptions) addFlags(cmd *cobra.Command, prefix string, section string) {
+	cmd.Flags().StringVar(&l.order, prefix+"order", string(merge.LexicalOrder), "order of the files of each directory or glob pattern (lexical, natural or explicit)")
+	cmd.Flags().StringSliceVar(&l.names, prefix+"names", []string{}, "glob patterns of the file names, in order, with the explicit order (the files that match none go last)")
+	cmd.Flags().StringSliceVar(&l.include, prefix+"include", []string{}, "include the files of directories and glob patterns that match the filter/s (defaults to *.yml,*.yaml)")
+	cmd.Flags().StringSliceVar(&l.exclude, prefix+"exclude", []string{}, "exclude the files of directories and glob patterns that match the filter/s (takes precedence over include)")
+	onViperInitialize(func() {
+		for _, flag := range []string{"order", "names", "include", "exclude"} {
+			bindViperC(cmd, prefix+flag, section+"."+prefix+flag)
+		}
 	})
 }
+
+// listFiles returns the files of a list of files, directories and glob
+// patterns.
+func (l *fileListOptions) listFiles(paths []string) ([]string, error) {
+	return merge.ListFiles(paths, merge.FileList{
+		Order:   merge.FileOrder(l.order),
+		Names:   l.names,
+		Include: l.include,
+		Exclude: l.exclude,
+	})
+}
+
+// addFlags adds the flags of the overlay of environment variables to a command,
+// bound to a section of the config file.
+func (e *envOptions) addFlags(cmd *cobra.Command, section string) {
+	cmd.Flags().StringVar(&e.prefix, "env-prefix", "", "merge the environment variables named with the prefix followed by the separator as the most important values (APP__DB__HOST=x sets db.host with prefix APP)")
+	cmd.Flags().StringVar(&e.separator, "env-separator", merge.DefaultEnvSeparator, "separator of the keys in the names of the environment variables of --env-prefix")
+	onViperInitialize(func() {
+		bindViperC(cmd, "env-prefix", section+".env-prefix")
+		bindViperC(cmd, "env-separator", section+".env-separator")
+	})
+}
+
+// env returns the overlay of environment variables.
+func (e *envOptions) env() merge.Env {
+	return merge.Env{Prefix: e.prefix, Separator: e.separator}
+}
+
+// overlays returns whether the environment variables or values set are merged
+// after the files.
+func (o *mergeOptions) overlays() bool {
+	return o.env.prefix != "" || o.sets.set()
+}
+
+// addFlags adds the flags that set values in paths to a command.
+func (s *setOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&s.values, "set", []string{}, "set a value in a path as PATH=VALUE after merging, with its type inferred (a.b[0].c=8080 sets an int)")
+	cmd.Flags().StringArrayVar(&s.strings, "set-string", []string{}, "set a string value in a path as PATH=VALUE after merging")
+	cmd.Flags().StringArrayVar(&s.jsons, "set-json", []string{}, "set a json value in a path as PATH=JSON after merging")
+	cmd.Flags().StringArrayVar(&s.files, "set-file", []string{}, "set the content of a file as string value in a path as PATH=FILE after merging")
+}
+
+// set returns whether any value is set.
+func (s *setOptions) set() bool {
+	return len(s.values)+len(s.strings)+len(s.jsons)+len(s.files) > 0
+}
+
+// load parses the values set, in the order of the flags.
+func (s *setOptions) load() error {
+	s.parsed = nil
+	for _, flag := range []struct {
+		name   string
+		values []string
+	}{{"set", s.values}, {"set-string", s.strings}, {"set-json", s.jsons}, {"set-file", s.files}} {
+		for _, str := range flag.values {
+			p, value, err := parseAssignment(str)
+			if err != nil {
+				return fmt.Errorf("invalid --%s %s: %w", flag.name, str, err)
+			}
+			s.parsed = append(s.parsed, assignment{flag: flag.name, path: p, value: value})
+		}
+	}
+	return nil
+}
+
+// mergeOptions returns the merge options of the values set.
+func (s *setOptions) mergeOptions() []merge.Option {
+	opts := make([]merge.Option, len(s.parsed))
+	for i, a := range s.parsed {
+		switch a.flag {
+		case "set-string":
+			opts[i] = merge.WithSetString(a.path, a.value)
+		case "set-json":
+			opts[i] = merge.WithSetJSON(a.path, a.value)
+		case "set-file":
+			opts[i] = merge.WithSetFile(a.path, a.value)
+		default:
+			opts[i] = merge.WithSet(a.path, a.value)
+		}
+	}
+	return opts
+}
+
+// replaceOptions returns the replace options of the values set.
+func (s *setOptions) replaceOptions() []replace.Option {
+	opts := make([]replace.Option, len(s.parsed))
+	for i, a := range s.parsed {
+		switch a.flag {
+		case "set-string":
+			opts[i] = replace.WithSetString(a.path, a.value)
+		case "set-json":
+			opts[i] = replace.WithSetJSON(a.path, a.value)
+		case "set-file":
+			opts[i] = replace.WithSetFile(a.path, a.value)
+		default:
+			opts[i] = replace.WithSet(a.path, a.value)
+		}
+	}
+	return opts
+}
+
+// parseAssignment parses a value set in a path as PATH=VALUE, the path ends at
+// the first = that is not escaped with a backslash nor inside a quoted key.
+func parseAssignment(s string) (string, string, error) {
+	var quote rune
+	escaped := false
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '=':
+			if p, err := path.Parse(s[:i]); err != nil {
+				return "", "", err
+			} else if p.Wildcards() > 0 {
+				return "", "", errors.New("the path must not contain wildcards")
+			}
+			return s[:i], s[i+1:], nil
+		}
+	}
+	return "", "", errors.New("expected PATH=VALUE")
+}
+
+// parsePathListMerge parses a list strategy of a path as PATH=STRATEGY[:KEY].
+func parsePathListMerge(s string) (merge.PathListMerge, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return merge.PathListMerge{}, fmt.Errorf("invalid strategy %s, expected PATH=STRATEGY[:KEY]", s)
+	}
+	strategy, key, _ := strings.Cut(s[i+1:], ":")
+	return merge.PathListMerge{Path: s[:i], Strategy: merge.ListStrategy(strategy), Key: key}, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/pkg/format"
+	"github.com/amplia-iiot/yutil/pkg/merge"
+	"github.com/spf13/cobra"
+)
+
+type merge3Options struct {
+	outputFile string
+	stdout     bool
+	report     string
+	style      styleOptions
+}
+
+// Conflict reports of the merge3 command.
+const (
+	markersReport = "markers"
+	jsonReport    = "json"
+)
+
+var m3Options merge3Options
+
+// merge3Cmd represents the merge3 command
+var merge3Cmd = &cobra.Command{
+	Use:   "merge3 BASE OURS THEIRS",
+	Short: "Merge the changes of two yaml files to a common base",
+	Long: `Merge the changes made in two yaml files (ours and theirs) to a
+common base file, understanding the yaml structure. The changes of a single
+side are taken, keys changed in different places of the same mapping are
+merged and any other value (lists included) changed differently by both sides
+is a conflict. The documents of multi-document files are merged by position.
+
+The merged yaml overwrites the OURS file (unless --output or --stdout is
+passed) and the command exits with status 1 if there are conflicts. The
+arguments follow the convention of git merge drivers (%O %A %B), register it in
+.gitconfig and .gitattributes to merge yaml files with git:
+
+[merge "yutil"]
+	name = yutil three-way yaml merge
+	driver = yutil merge3 %O %A %B
+
+*.yml merge=yutil
+
+The lines of the conflicts are written between git conflict markers, resolving
+the conflicts with our values and with their values. The json report
+(--report json) also writes to stdout the path and the values of each conflict.
+
+The merged yaml keeps the layout of our file, only the merged values are
+edited, so the merge does not reformat unrelated lines. If our file cannot be
+edited keeping its layout (like values with anchors used by aliases) the merged
+yaml is formatted with the same options as the format command (and the format
+section of the config file), keeping the comments of our file, except that keys
+keep their source order by default.
+
+For example:
+
+yutil merge3 base.yml ours.yml theirs.yml
+yutil merge3 --stdout base.yml ours.yml theirs.yml
+yutil merge3 --report json -o merged.yml base.yml ours.yml theirs.yml
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := m3Options.style.load(); err != nil {
+			return err
+		}
+		if len(args) != 3 {
+			return errors.New("requires the base, our and their files")
+		}
+		switch m3Options.report {
+		case markersReport:
+		case jsonReport:
+			if m3Options.stdout {
+				return errors.New("the json report is written to stdout, use --output to write the merged yaml to a file")
+			}
+		default:
+			return fmt.Errorf("unknown report %s, valid reports are [%s %s]", m3Options.report, markersReport, jsonReport)
+		}
+		for _, file := range args {
+			if !io.Exists(file) {
+				return fmt.Errorf("file %s does not exist", file)
+			}
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := merge.MergeThreeWayFiles(args[0], args[1], args[2], merge.WithFormat(m3Options.style.formatOptions()...))
+		if err != nil {
+			panic(err)
+		}
+		switch {
+		case m3Options.stdout:
+			err = io.WriteToStdout(result.Merged)
+		case len(m3Options.outputFile) > 0:
+			err = io.WriteToFile(m3Options.outputFile, result.Merged)
+		default:
+			err = io.WriteToFile(args[1], result.Merged)
+		}
+		if err != nil {
+			panic(err)
+		}
+		if m3Options.report == jsonReport {
+			if err = writeConflictReport(result.Conflicts); err != nil {
+				panic(err)
+			}
+		}
+		if len(result.Conflicts) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(merge3Cmd)
+
+	merge3Cmd.Flags().StringVarP(&m3Options.outputFile, "output", "o", "", "write merged yaml to output file instead of overwriting the OURS file")
+	merge3Cmd.Flags().BoolVarP(&m3Options.stdout, "stdout", "p", false, "write merged yaml to stdout instead of overwriting the OURS file")
+	merge3Cmd.MarkFlagsMutuallyExclusive("output", "stdout")
+	merge3Cmd.Flags().StringVar(&m3Options.report, "report", markersReport, "how conflicts are reported (markers or json, which also writes a json report to stdout)")
+	m3Options.style.addFlags(merge3Cmd)
+	// Keys keep their source order by default, so the merged yaml only changes
+	// the merged values when it cannot be edited keeping the layout of ours
+	order := merge3Cmd.Flags().Lookup("order")
+	order.DefValue = string(format.Source)
+	if err := order.Value.Set(order.DefValue); err != nil {
+		panic(err)
+	}
+}
+
+// writeConflictReport writes to stdout the json report of the conflicts of a
+// three-way merge.
+func writeConflictReport(conflicts []merge.ThreeWayConflict) error {
+	if conflicts == nil {
+		conflicts = []merge.ThreeWayConflict{}
+	}
+	report, err := json.MarshalIndent(struct {
+		Conflicts []merge.ThreeWayConflict `json:"conflicts"`
+	}{conflicts}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return io.WriteToStdout(string(report) + "\n")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/pkg/patch"
+	"github.com/spf13/cobra"
+)
+
+type patchCmdOptions struct {
+	patchFile string
+	patchType string
+	edit      editOptions
+}
+
+var pOptions patchCmdOptions
+
+// patchCmd represents the patch command
+var patchCmd = &cobra.Command{
+	Use:   "patch [FILE...] --patch PATCH",
+	Short: "Apply a json patch or a merge patch to yaml files",
+	Long: `Apply a patch to a yaml file changing only the text of the patched
+values: comments, key order, quotes, indentation and the rest of the format of
+the file are kept. The patched file (or stdin) is written to stdout, or several
+files are patched in place.
+
+For example:
+
+yutil patch config.yml --patch ops.yml
+yutil patch -i config.yml --patch ops.json
+yutil patch -s .bak dev.yml prod.yml --patch release.yml
+yutil patch config.yml --patch ops.yml --dry-run
+yutil patch config.yml --patch overrides.yml --type merge
+cat config.yml | yutil patch --patch ops.yml
+
+The patch is written in yaml or json and is either a json patch (RFC 6902), a
+list of operations (add, remove, replace, move, copy and test) with paths as
+json pointers:
+
+- op: replace
+  path: /spec/replicas
+  value: 3
+- op: add
+  path: /spec/ports/-
+  value: 8443
+- op: test
+  path: /metadata/name
+  value: web
+
+or a merge patch (RFC 7386), a mapping merged into the file where a null value
+removes its key and any other value that is not a mapping replaces the current
+one. By default (--type auto) a list is a json patch and anything else a merge
+patch.
+
+The operations are applied in order and a failed operation (like a failed test
+or a path not found) leaves every file unmodified. A dry run writes the diff of
+the changes instead of the patched files.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := patch.Type(pOptions.patchType).Validate(); err != nil {
+			return err
+		}
+		if pOptions.patchFile == "" {
+			return errors.New("requires a patch file")
+		}
+		if !io.Exists(pOptions.patchFile) {
+			return fmt.Errorf("patch file %s does not exist", pOptions.patchFile)
+		}
+		pOptions.edit.inPlace = pOptions.edit.inPlace || cmd.Flags().Changed("suffix")
+		return pOptions.edit.validate(args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := io.ReadAsString(pOptions.patchFile)
+		if err != nil {
+			return err
+		}
+		opts := []patch.Option{patch.WithType(patch.Type(pOptions.patchType)), patch.WithDocument(pOptions.edit.document)}
+		return pOptions.edit.run(args, func(content string) (string, error) {
+			return patch.Apply(content, p, opts...)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(patchCmd)
+
+	patchCmd.Flags().StringVarP(&pOptions.patchFile, "patch", "p", "", "yaml or json file with the patch")
+	patchCmd.Flags().StringVarP(&pOptions.patchType, "type", "t", string(patch.AutoPatch), "type of the patch (auto, json or merge)")
+	patchCmd.Flags().StringVarP(&pOptions.edit.suffix, "suffix", "s", "", "patch the files in place making a backup with the given suffix (-i is not necessary if suffix is passed)")
+	patchCmd.Flags().BoolVar(&pOptions.edit.dryRun, "dry-run", false, "write the diff of the changes instead of the patched files")
+	pOptions.edit.addFlags(patchCmd)
+}
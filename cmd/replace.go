@@ -40,6 +40,9 @@ type replaceOptions struct {
 	replacementFiles []string
 	includeEnv       bool
 	extensions       []string
+	list             fileListOptions
+	env              envOptions
+	sets             setOptions
 }
 
 func (o replaceOptions) engine() replace.Engine {
@@ -59,13 +62,28 @@ var replaceCmd = &cobra.Command{
 more replacement files that will be merged. Files should be ordered
 in ascending level of importance in the hierarchy. A yaml
 node in the last file replaces values in any previous file. Stdin is
-treated as first replacement file.
+treated as first replacement file. The front matter of markdown files (.md
+or .markdown) can be used as replacement file.
 
 By default all files in the current directory and subdirectories are passed
 through the template engine. Use include and exclude to filter files.
 If no engine is picked the default golang template with slim-sprig functions
 will be used.
 
+Replacement files may be directories (like conf.d) and glob patterns, which
+are expanded into the yaml files inside them as in the merge command (see
+--replacements-order, --replacements-include...).
+
+The environment variables named with --env-prefix followed by --env-separator
+are merged after the replacement files, as in the merge command, so the
+replacements are the values the application runs with (APP__DB__HOST=x sets
+db.host with prefix APP). The --env flag only adds every variable, as is,
+inside the env node.
+
+The values of --set, --set-string, --set-json and --set-file (PATH=VALUE) are
+set after merging the replacement files and environment variables, as in the
+merge command.
+
 The extension/s is/are used for including those files by default (unless
 include flag is used) and renaming replaced files accordingly.
 
@@ -76,7 +94,10 @@ cat base.yml | yutil replace
 yutil replace -r config.yml -n root_node --jinja2
 yutil replace -r config.yml -e .go -e .gotempl --env
 yutil replace -r config.yml --include 'directory/*.conf'
+yutil replace -r config.yml --env-prefix APP --env-separator _
+yutil replace -r config.yml --set image.tag=v2 --set-file ca=ca.pem
 yutil replace -r config.yml --jinja2 -d directory --exclude '*/secret/*'
+yutil replace -r conf.d -r 'local/*.yml' --replacements-order natural
 echo "this is not a yaml" | yutil --no-input replace -r base.yml -r changes.yml
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -101,22 +122,30 @@ echo "this is not a yaml" | yutil --no-input replace -r base.yml -r changes.yml
 		if !io.Exists(rOptions.directory) {
 			return fmt.Errorf("directory %s does not exist", rOptions.directory)
 		}
-		for _, f := range rOptions.replacementFiles {
+		if err := rOptions.sets.load(); err != nil {
+			return err
+		}
+		replacementFiles, err := rOptions.list.listFiles(rOptions.replacementFiles)
+		if err != nil {
+			return err
+		}
+		for _, f := range replacementFiles {
 			if !io.Exists(f) {
 				return fmt.Errorf("replacement file %s does not exist", f)
 			}
 		}
-		err := replace.Replace(
-			engine,
+		opts := []replace.Option{
 			replace.WithDirectory(rOptions.directory),
-			replace.WithReplacementFiles(rOptions.replacementFiles...),
+			replace.WithReplacementFiles(replacementFiles...),
 			replace.WithRootNode(rOptions.node),
 			replace.WithExtension(extensions...),
 			replace.WithInclude(include...),
 			replace.WithExclude(rOptions.exclude...),
 			replace.WithIncludeEnvironmentInReplacements(rOptions.includeEnv),
+			replace.WithEnvOverlay(rOptions.env.prefix, rOptions.env.separator),
 			replace.WithIncludeStdinInReplacements(canAccessStdin()),
-		)
+		}
+		err = replace.Replace(engine, append(opts, rOptions.sets.replaceOptions()...)...)
 		if err != nil {
 			return err
 		}
@@ -131,12 +160,15 @@ func init() {
 	replaceCmd.Flags().BoolVar(&rOptions.jinja2, "jinja2", false, "use jinja2 template engine, automatically sets include and extension config for .j2 files unless overriden")
 	replaceCmd.MarkFlagsMutuallyExclusive("golang", "jinja2")
 	replaceCmd.Flags().StringVarP(&rOptions.directory, "directory", "d", ".", "pick root directory to search for files to be replaced (defaults to current directory)")
-	replaceCmd.Flags().StringSliceVarP(&rOptions.replacementFiles, "replacements", "r", []string{}, "replacement files (multiple files will be merged)")
+	replaceCmd.Flags().StringSliceVarP(&rOptions.replacementFiles, "replacements", "r", []string{}, "replacement files, directories or glob patterns (multiple files will be merged)")
 	replaceCmd.Flags().StringVarP(&rOptions.node, "node", "n", "", "only include replacements from inside this node")
 	replaceCmd.Flags().BoolVar(&rOptions.includeEnv, "env", false, "include environment variables as input for the template engine (available inside the 'env' node)")
 	replaceCmd.Flags().StringSliceVarP(&rOptions.extensions, "extension", "e", []string{}, "define the extension/s of the files to replace and then remove in the file name when saving (normally you should include the dot), automatically sets default include config unless overriden (*<ext> and *<ext>.*)")
 	replaceCmd.Flags().StringSliceVar(&rOptions.include, "include", []string{}, "include files that match the filter/s")
 	replaceCmd.Flags().StringSliceVar(&rOptions.exclude, "exclude", []string{}, "exclude files that match the filter/s (takes precedence over include)")
+	rOptions.list.addFlags(replaceCmd, "replacements-", "replace")
+	rOptions.env.addFlags(replaceCmd, "replace")
+	rOptions.sets.addFlags(replaceCmd)
 	onViperInitialize(func() {
 		bindViperC(replaceCmd, "golang", "replace.golang")
 		bindViperC(replaceCmd, "jinja2", "replace.jinja2")
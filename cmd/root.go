@@ -24,6 +24,7 @@ SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -32,6 +33,7 @@ import (
 	"github.com/spf13/cobra"
 
 	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -77,7 +79,38 @@ var buildInfo BuildInfo
 // Execute starts the command line tool.
 func Execute(info BuildInfo) {
 	buildInfo = info
-	cobra.CheckErr(rootCmd.Execute())
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitError is an error of a command whose exit status has a meaning, which
+// exits with a status other than 1.
+type exitError struct {
+	error
+	code int
+}
+
+// withExitCode returns an error that exits with a status, nil if there is no
+// error.
+func withExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{error: err, code: code}
+}
+
+// exitCode returns the exit status of the error of a command.
+func exitCode(err error) int {
+	var exit *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return exit.code
+	}
+	return 1
 }
 
 func init() {
@@ -141,8 +174,16 @@ func bindViperC(cmd *cobra.Command, cobraName string, viperName string) {
 	if err != nil {
 		panic(err)
 	}
-	if !cmd.Flag(cobraName).Changed && viper.IsSet(viperName) {
-		err := cmd.Flags().Set(cobraName, fmt.Sprintf("%v", viper.Get(viperName)))
+	flag := cmd.Flag(cobraName)
+	if !flag.Changed && viper.IsSet(viperName) {
+		var err error
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			// Lists are not passed as text, values could contain commas
+			err = slice.Replace(viper.GetStringSlice(viperName))
+			flag.Changed = true
+		} else {
+			err = cmd.Flags().Set(cobraName, fmt.Sprintf("%v", viper.Get(viperName)))
+		}
 		if err != nil {
 			panic(err)
 		}
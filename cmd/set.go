@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	idiff "github.com/amplia-iiot/yutil/internal/diff"
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/markdown"
+	"github.com/amplia-iiot/yutil/pkg/edit"
+	"github.com/amplia-iiot/yutil/pkg/format"
+	"github.com/amplia-iiot/yutil/pkg/path"
+	"github.com/spf13/cobra"
+)
+
+// editOptions are the options shared by the commands that edit yaml files
+// keeping their format.
+type editOptions struct {
+	inPlace  bool
+	suffix   string
+	dryRun   bool
+	document int
+}
+
+type setCmdOptions struct {
+	valueType string
+	edit      editOptions
+}
+
+var sOptions setCmdOptions
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set [FILE...] PATH VALUE",
+	Short: "Set a value in a path of yaml files",
+	Long: `Set a value in a path of a yaml file changing only the text of the
+value: comments, key order, quotes, indentation and the rest of the format of
+the file are kept. The edited file (or stdin) is written to stdout, or several
+files are edited in place.
+
+For example:
+
+yutil set config.yml server.host example.com
+yutil set config.yml server.port 8080 --type int
+yutil set -i config.yml 'spec.containers[0].image' app:1.2
+yutil set -i dev.yml prod.yml metadata.labels.team platform
+yutil set config.yml tls '{enabled: true, port: 443}' --type yaml
+yutil set config.yml 'hosts[2]' backup.example.com
+cat config.yml | yutil set debug true --type bool
+
+A path is a list of keys separated by dots, with [n] selecting the n-th element
+of a list, and must not have wildcards. The missing keys of the path are added
+to their mappings (null values are replaced by new mappings) and an index one
+past the end of a list appends the value.
+
+The value is a string unless another type is passed (--type): an int, a bool or
+any yaml value, written in block style (or in flow style inside a flow
+collection) with the indentation of the file. A scalar replaced by another
+string keeps its quotes.
+
+The edited file is parsed again to check that only the value has changed. If a
+file cannot be edited keeping its format (like a value with an anchor used by
+aliases) no file is modified.
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := edit.ValueType(sOptions.valueType).Validate(); err != nil {
+			return err
+		}
+		if len(args) < 2 {
+			return errors.New("requires a path and a value")
+		}
+		if _, err := path.Parse(args[len(args)-2]); err != nil {
+			return err
+		}
+		return sOptions.edit.validate(args[:len(args)-2])
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expr, value := args[len(args)-2], args[len(args)-1]
+		opts := []edit.Option{edit.WithValueType(edit.ValueType(sOptions.valueType)), edit.WithDocument(sOptions.edit.document)}
+		return sOptions.edit.run(args[:len(args)-2], func(content string) (string, error) {
+			return edit.Set(content, expr, value, opts...)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setCmd)
+
+	setCmd.Flags().StringVarP(&sOptions.valueType, "type", "t", string(edit.String), "type of the value (string, int, bool or yaml)")
+	sOptions.edit.addFlags(setCmd)
+}
+
+// addFlags adds the edit flags to a command.
+func (e *editOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&e.inPlace, "in-place", "i", false, "edit the files in place instead of writing the edited file to stdout")
+	cmd.Flags().IntVarP(&e.document, "document", "d", 0, "document of multi-document files that is edited (0-based)")
+}
+
+// validate checks the files to be edited: several files can only be edited in
+// place (or in a dry run) and stdin only to stdout.
+func (e *editOptions) validate(files []string) error {
+	if e.document < 0 {
+		return fmt.Errorf("invalid document %d", e.document)
+	}
+	switch {
+	case e.dryRun && e.inPlace:
+		return errors.New("dry run not compatible with in place edition")
+	case e.inPlace && canAccessStdin():
+		return errors.New("stdin not compatible with in place edition")
+	case e.inPlace && len(files) == 0:
+		return errors.New("requires at least one file to be edited in place")
+	case !e.inPlace && canAccessStdin() && len(files) != 0:
+		return errors.New("only one yaml can be edited to output, stdin is active")
+	case !e.inPlace && !canAccessStdin() && len(files) == 0:
+		if stdinBlocked() {
+			return errors.New("requires one file to be edited, stdin is blocked")
+		}
+		return errors.New("requires one file to be edited")
+	case !e.inPlace && !e.dryRun && len(files) > 1:
+		return errors.New("only one file can be edited to output, several files can be edited in place")
+	}
+	for _, file := range files {
+		if !io.Exists(file) {
+			return fmt.Errorf("file %s does not exist", file)
+		}
+		if isDir(file) || markdown.IsMarkdown(file) {
+			return fmt.Errorf("%s is not a yaml file", file)
+		}
+	}
+	return nil
+}
+
+// run edits the files (or stdin) writing them in place (with a backup if there
+// is a suffix) or to stdout, or writing the diff of the changes in a dry run.
+// Files are only written if all of them can be edited. A path not found in a
+// file is printed to stderr, exiting with status 2.
+func (e *editOptions) run(files []string, editContent func(content string) (string, error)) error {
+	var contents []string
+	if canAccessStdin() {
+		stdin, err := io.ReadStdin()
+		if err != nil {
+			return err
+		}
+		files, contents = []string{format.StdinName}, []string{stdin}
+	} else {
+		for _, file := range files {
+			content, err := io.ReadAsString(file)
+			if err != nil {
+				return err
+			}
+			if f := format.DetectFormat(file, content); f != format.YAML {
+				return fmt.Errorf("%s is a %s file, only yaml files can be edited", file, f)
+			}
+			contents = append(contents, content)
+		}
+	}
+	edited := make([]string, len(contents))
+	for i, content := range contents {
+		var err error
+		if edited[i], err = editContent(content); errors.Is(err, edit.ErrPathNotFound) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", files[i], err)
+			os.Exit(exitPathNotFound)
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", files[i], err)
+		}
+	}
+	if e.dryRun {
+		var changes string
+		for i, file := range files {
+			changes += idiff.Unified(file, file, contents[i], edited[i])
+		}
+		return io.WriteToStdout(changes)
+	}
+	if !e.inPlace {
+		return io.WriteToStdout(edited[0])
+	}
+	for i, file := range files {
+		if edited[i] == contents[i] {
+			continue
+		}
+		if e.suffix != "" {
+			if err := io.Copy(file, file+e.suffix); err != nil {
+				return err
+			}
+		}
+		if err := io.WriteToFile(file, edited[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package diff writes unified diffs and conflict markers between two texts.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+type kind int
+
+const (
+	equal kind = iota
+	insert
+	delete
+)
+
+type edit struct {
+	kind kind
+	from int // Line index in the old text
+	to   int // Line index in the new text
+}
+
+// Unified returns the unified diff between two texts, empty if they are equal.
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+	a, b := splitLines(old), splitLines(new)
+	edits := compare(a, b)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(edits) {
+		writeHunk(&sb, h, a, b)
+	}
+	return sb.String()
+}
+
+// splitLines splits a text in lines keeping the line breaks, so a missing line
+// break at the end is a difference.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// compare returns the edits that transform a into b using the Myers
+// algorithm.
+func compare(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+	// Backtrack the path from the end
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{equal, x, y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, edit{insert, x, y})
+			} else {
+				x--
+				edits = append(edits, edit{delete, x, y})
+			}
+		}
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunks groups the edits in hunks of changes surrounded by context lines.
+func hunks(edits []edit) [][]edit {
+	var result [][]edit
+	start, end := -1, -1
+	for i, e := range edits {
+		if e.kind == equal {
+			continue
+		}
+		if start >= 0 && i-context > end {
+			result = append(result, edits[start:end])
+			start = -1
+		}
+		if start < 0 {
+			start = i - context
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = i + context + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+	}
+	if start >= 0 {
+		result = append(result, edits[start:end])
+	}
+	return result
+}
+
+func writeHunk(sb *strings.Builder, hunk []edit, a, b []string) {
+	var oldCount, newCount int
+	for _, e := range hunk {
+		if e.kind != insert {
+			oldCount++
+		}
+		if e.kind != delete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", lineRange(hunk[0].from, oldCount), lineRange(hunk[0].to, newCount))
+	for _, e := range hunk {
+		switch e.kind {
+		case equal:
+			writeLine(sb, " ", a[e.from])
+		case delete:
+			writeLine(sb, "-", a[e.from])
+		case insert:
+			writeLine(sb, "+", b[e.to])
+		}
+	}
+}
+
+// lineRange returns the range of a hunk (starting at 1) in the unified diff
+// format.
+func lineRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func writeLine(sb *strings.Builder, prefix, line string) {
+	sb.WriteString(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// Markers returns a text with the lines of two texts that are equal and, where
+// they differ, the lines of both between conflict markers as git writes them:
+//
+//	<<<<<<< oldLabel
+//	old lines
+//	=======
+//	new lines
+//	>>>>>>> newLabel
+func Markers(oldLabel, newLabel, old, new string) string {
+	if old == new {
+		return old
+	}
+	a, b := splitLines(old), splitLines(new)
+	var sb strings.Builder
+	var conflict []edit
+	flush := func() {
+		if len(conflict) == 0 {
+			return
+		}
+		sb.WriteString("<<<<<<< " + oldLabel + "\n")
+		for _, e := range conflict {
+			if e.kind == delete {
+				writeMarked(&sb, a[e.from])
+			}
+		}
+		sb.WriteString("=======\n")
+		for _, e := range conflict {
+			if e.kind == insert {
+				writeMarked(&sb, b[e.to])
+			}
+		}
+		sb.WriteString(">>>>>>> " + newLabel + "\n")
+		conflict = nil
+	}
+	for _, e := range compare(a, b) {
+		if e.kind == equal {
+			flush()
+			sb.WriteString(a[e.from])
+			continue
+		}
+		conflict = append(conflict, e)
+	}
+	flush()
+	return sb.String()
+}
+
+// writeMarked writes a line between conflict markers, adding the line break if
+// it is missing.
+func writeMarked(sb *strings.Builder, line string) {
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteByte('\n')
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package diff
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestUnified(t *testing.T) {
+	for name, i := range map[string]struct {
+		old      string
+		new      string
+		expected string
+	}{
+		"equal": {
+			old:      "a: 1\n",
+			new:      "a: 1\n",
+			expected: "",
+		},
+		"changed line": {
+			old: "a: 1\nb: 2\nc: 3\n",
+			new: "a: 1\nb: two\nc: 3\n",
+			expected: `--- old
++++ new
+@@ -1,3 +1,3 @@
+ a: 1
+-b: 2
++b: two
+ c: 3
+`,
+		},
+		"inserted line": {
+			old: "a: 1\n",
+			new: "a: 1\nb: 2\n",
+			expected: `--- old
++++ new
+@@ -1 +1,2 @@
+ a: 1
++b: 2
+`,
+		},
+		"missing line break": {
+			old: "a: 1",
+			new: "a: 1\n",
+			expected: `--- old
++++ new
+@@ -1 +1 @@
+-a: 1
+\ No newline at end of file
++a: 1
+`,
+		},
+		"separated hunks": {
+			old: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new: "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: `--- old
++++ new
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			itesting.AssertEqual(t, i.expected, Unified("old", "new", i.old, i.new))
+		})
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	for name, i := range map[string]struct {
+		old      string
+		new      string
+		expected string
+	}{
+		"equal": {
+			old:      "a: 1\n",
+			new:      "a: 1\n",
+			expected: "a: 1\n",
+		},
+		"changed line": {
+			old: "a: 1\nb: 2\nc: 3\n",
+			new: "a: 1\nb: two\nc: 3\n",
+			expected: `a: 1
+<<<<<<< ours
+b: 2
+=======
+b: two
+>>>>>>> theirs
+c: 3
+`,
+		},
+		"one side": {
+			old: "a: 1\nb: 2\n",
+			new: "a: 1\n",
+			expected: `a: 1
+<<<<<<< ours
+b: 2
+=======
+>>>>>>> theirs
+`,
+		},
+		"missing line break": {
+			old: "a: 1\nb: 2",
+			new: "a: 1\nb: 3",
+			expected: `a: 1
+<<<<<<< ours
+b: 2
+=======
+b: 3
+>>>>>>> theirs
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			itesting.AssertEqual(t, i.expected, Markers("ours", "theirs", i.old, i.new))
+		})
+	}
+}
@@ -23,16 +23,29 @@ SOFTWARE.
 package io
 
 import (
+	"fmt"
 	"io/fs"
 	"path/filepath"
 
 	"github.com/gobwas/glob"
 )
 
+// ListFiles walks a directory returning the files that match any of the
+// include glob patterns (all if there is none) and none of the exclude glob
+// patterns. Patterns are matched against both the file name and its path.
 var ListFiles = func(dir string, include []string, exclude []string) (files []string, err error) {
-	includeGlobs := toGlobs(include)
-	excludeGlobs := toGlobs(exclude)
+	includeGlobs, err := toGlobs(include)
+	if err != nil {
+		return nil, err
+	}
+	excludeGlobs, err := toGlobs(exclude)
+	if err != nil {
+		return nil, err
+	}
 	err = filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 		if !info.IsDir() && matchFile(path, includeGlobs, excludeGlobs) {
 			files = append(files, path)
 		}
@@ -41,9 +54,13 @@ var ListFiles = func(dir string, include []string, exclude []string) (files []st
 	return
 }
 
-func toGlobs(strings []string) (globs []glob.Glob) {
-	for _, s := range strings {
-		globs = append(globs, glob.MustCompile(s))
+func toGlobs(patterns []string) (globs []glob.Glob, err error) {
+	for _, p := range patterns {
+		g, err := glob.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %s: %w", p, err)
+		}
+		globs = append(globs, g)
 	}
 	return
 }
@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package markdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+)
+
+// Extensions are the file extensions of markdown files.
+var Extensions = []string{".md", ".markdown"}
+
+// IsMarkdown returns whether a file is a markdown file by its extension.
+func IsMarkdown(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Region is a yaml region of a markdown content: the front matter or the
+// content of a fenced code block with yaml info string.
+type Region struct {
+	FrontMatter bool   // Whether the region is the front matter
+	Line        int    // Line where the yaml starts (1-based)
+	Start       int    // Offset where the region starts
+	End         int    // Offset where the region ends
+	Indent      string // Indentation of the fence, removed from each line
+}
+
+// YAML returns the yaml content of the region without the fence indentation.
+func (r Region) YAML(content string) string {
+	lines := strings.SplitAfter(content[r.Start:r.End], "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, r.Indent)
+	}
+	return strings.Join(lines, "")
+}
+
+// Indented returns a yaml content indented with the fence indentation, to
+// replace the region.
+func (r Region) Indented(yaml string) string {
+	if r.Indent == "" {
+		return yaml
+	}
+	lines := strings.SplitAfter(yaml, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = r.Indent + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// Regions returns the yaml regions of a markdown content, in order: the front
+// matter (a block between "---" lines at the start of the content) and the
+// fenced code blocks (``` or ~~~) whose info string is yaml or yml. Code blocks
+// that are not closed are ignored.
+func Regions(content string) []Region {
+	var regions []Region
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	i := 0
+	if len(lines) > 0 && strings.TrimRight(lines[0], " \r\n") == "---" {
+		start := len(lines[0])
+		end := start
+		for j := 1; j < len(lines); j++ {
+			if l := strings.TrimRight(lines[j], " \r\n"); l == "---" || l == "..." {
+				regions = append(regions, Region{FrontMatter: true, Line: 2, Start: start, End: end})
+				i, offset = j+1, end+len(lines[j])
+				break
+			}
+			end += len(lines[j])
+		}
+	}
+	for ; i < len(lines); i++ {
+		offset += len(lines[i])
+		indent, fence, info, ok := openingFence(lines[i])
+		if !ok {
+			continue
+		}
+		start, end := offset, offset
+		closed := false
+		for i++; i < len(lines); i++ {
+			offset += len(lines[i])
+			if closingFence(lines[i], fence) {
+				closed = true
+				break
+			}
+			end = offset
+		}
+		if closed && isYAML(info) {
+			regions = append(regions, Region{Line: strings.Count(content[:start], "\n") + 1, Start: start, End: end, Indent: indent})
+		}
+	}
+	return regions
+}
+
+// FrontMatter returns the front matter of a markdown content, if any.
+func FrontMatter(content string) (string, bool) {
+	if regions := Regions(content); len(regions) > 0 && regions[0].FrontMatter {
+		return regions[0].YAML(content), true
+	}
+	return "", false
+}
+
+// ReadYAML reads the yaml content of a file: the front matter of markdown
+// files or the whole content of any other file.
+var ReadYAML = func(file string) (string, error) {
+	content, err := io.ReadAsString(file)
+	if err != nil || !IsMarkdown(file) {
+		return content, err
+	}
+	yaml, ok := FrontMatter(content)
+	if !ok {
+		return "", fmt.Errorf("%s has no front matter", file)
+	}
+	return yaml, nil
+}
+
+// openingFence returns the indentation, the fence and the info string of a
+// line that opens a fenced code block.
+func openingFence(line string) (indent string, fence string, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	indent = line[:len(line)-len(trimmed)]
+	if len(trimmed) < 3 || trimmed[0] != '`' && trimmed[0] != '~' {
+		return
+	}
+	n := len(trimmed) - len(strings.TrimLeft(trimmed, trimmed[:1]))
+	if n < 3 {
+		return
+	}
+	fence, info = trimmed[:n], strings.TrimSpace(trimmed[n:])
+	if fence[0] == '`' && strings.Contains(info, "`") {
+		return
+	}
+	return indent, fence, info, true
+}
+
+// closingFence returns whether a line closes a fenced code block.
+func closingFence(line string, fence string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// isYAML returns whether an info string marks a yaml code block.
+func isYAML(info string) bool {
+	if fields := strings.Fields(info); len(fields) > 0 {
+		lang := strings.ToLower(strings.Trim(fields[0], "{}."))
+		return lang == "yaml" || lang == "yml"
+	}
+	return false
+}
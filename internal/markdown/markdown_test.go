@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package markdown
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestIsMarkdown(t *testing.T) {
+	for file, expected := range map[string]bool{
+		"README.md":        true,
+		"docs/index.MD":    true,
+		"notes.markdown":   true,
+		"config.yml":       false,
+		"md":               false,
+		"template.md.tmpl": false,
+	} {
+		itesting.AssertEqual(t, expected, IsMarkdown(file))
+	}
+}
+
+func TestRegions(t *testing.T) {
+	content := "---\na: 1\n---\n# Title\n\n```yaml\nb: 2\n```\n\n- item\n\n  ~~~~yml\n  c: 3\n  ~~~~\n\n````md\n```yaml\nd: 4\n```\n````\n\n```json\n{}\n```\n\n```yaml\ne: 5\n"
+	var yamls []string
+	var lines []int
+	for _, r := range Regions(content) {
+		yamls = append(yamls, r.YAML(content))
+		lines = append(lines, r.Line)
+	}
+	itesting.AssertDeepEqual(t, []string{"a: 1\n", "b: 2\n", "c: 3\n"}, yamls)
+	itesting.AssertDeepEqual(t, []int{2, 7, 13}, lines)
+}
+
+func TestFrontMatter(t *testing.T) {
+	for _, i := range []struct {
+		content  string
+		expected string
+		ok       bool
+	}{
+		{"---\na: 1\n---\ntext", "a: 1\n", true},
+		{"---\na: 1\n...\ntext", "a: 1\n", true},
+		{"---\n---\n", "", true},
+		{"text\n---\na: 1\n---\n", "", false},
+		{"---\na: 1\n", "", false},
+		{"```yaml\na: 1\n```\n", "", false},
+	} {
+		fm, ok := FrontMatter(i.content)
+		itesting.AssertEqual(t, i.ok, ok)
+		itesting.AssertEqual(t, i.expected, fm)
+	}
+}
+
+func TestIndented(t *testing.T) {
+	r := Region{Indent: "  "}
+	itesting.AssertEqual(t, "  a:\n\n  - 1\n", r.Indented("a:\n\n- 1\n"))
+	content := "  a: 1\n\n  b: 2\n"
+	r.End = len(content)
+	itesting.AssertEqual(t, "a: 1\n\nb: 2\n", r.YAML(content))
+}
@@ -0,0 +1,274 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package path parses and matches paths to yaml nodes.
+//
+// A path is a list of segments separated by dots, like "spec.containers[0].name".
+// A segment can be:
+//   - a key: a bare word, where dots, brackets and backslashes are escaped with a
+//     backslash ("a\.b"), or a quoted key ("'a.b'" or "\"a.b\"").
+//   - an index between brackets: "[0]".
+//   - a wildcard, matching any key or index: "*" or "[*]".
+//
+// The root path is the empty string (or a single dot).
+package path
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Segment is a step in a path.
+type Segment struct {
+	Key      string // Mapping key (if not an index or wildcard)
+	Index    int    // Sequence index, -1 for keys and wildcards
+	Wildcard bool   // Whether it matches any key or index
+}
+
+// Key returns a key segment.
+func Key(key string) Segment {
+	return Segment{Key: key, Index: -1}
+}
+
+// Index returns an index segment.
+func Index(index int) Segment {
+	return Segment{Index: index}
+}
+
+// Wildcard returns a wildcard segment.
+func Wildcard() Segment {
+	return Segment{Index: -1, Wildcard: true}
+}
+
+// IsIndex returns whether the segment is a sequence index.
+func (s Segment) IsIndex() bool {
+	return !s.Wildcard && s.Index >= 0
+}
+
+// Match returns whether the segment matches a concrete segment. A key that is a
+// number also matches the index with that value.
+func (s Segment) Match(concrete Segment) bool {
+	switch {
+	case s.Wildcard:
+		return true
+	case s.IsIndex() && concrete.IsIndex():
+		return s.Index == concrete.Index
+	case s.IsIndex():
+		return strconv.Itoa(s.Index) == concrete.Key
+	case concrete.IsIndex():
+		return s.Key == strconv.Itoa(concrete.Index)
+	}
+	return s.Key == concrete.Key
+}
+
+func (s Segment) String() string {
+	switch {
+	case s.Wildcard:
+		return "*"
+	case s.IsIndex():
+		return fmt.Sprintf("[%d]", s.Index)
+	case s.Key == "" || strings.ContainsAny(s.Key, ".[]*'\"\\ "):
+		return strconv.Quote(s.Key)
+	}
+	return s.Key
+}
+
+// Path is a list of segments from the root node.
+type Path []Segment
+
+// Child returns a new path with a key appended.
+func (p Path) Child(key string) Path {
+	return p.Append(Key(key))
+}
+
+// Item returns a new path with an index appended.
+func (p Path) Item(index int) Path {
+	return p.Append(Index(index))
+}
+
+// Append returns a new path with the segments appended.
+func (p Path) Append(segments ...Segment) Path {
+	c := make(Path, len(p), len(p)+len(segments))
+	copy(c, p)
+	return append(c, segments...)
+}
+
+// Match returns whether the path (which may contain wildcards) matches a
+// concrete path.
+func (p Path) Match(concrete Path) bool {
+	if len(p) != len(concrete) {
+		return false
+	}
+	for i, s := range p {
+		if !s.Match(concrete[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Wildcards returns the number of wildcard segments.
+func (p Path) Wildcards() int {
+	n := 0
+	for _, s := range p {
+		if s.Wildcard {
+			n++
+		}
+	}
+	return n
+}
+
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, s := range p {
+		if i > 0 && !s.IsIndex() {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
+
+// Parse parses a path expression.
+func Parse(expr string) (Path, error) {
+	p := Path{}
+	rest := strings.TrimPrefix(expr, ".")
+	first := true
+	for rest != "" {
+		if !first {
+			switch rest[0] {
+			case '.':
+				rest = rest[1:]
+			case '[':
+			default:
+				return nil, fmt.Errorf("invalid path %s: expected . or [ before %s", expr, rest)
+			}
+		}
+		first = false
+		var segment Segment
+		var err error
+		segment, rest, err = parseSegment(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", expr, err)
+		}
+		p = append(p, segment)
+	}
+	return p, nil
+}
+
+// MustParse parses a path expression, panicking if it is not valid.
+func MustParse(expr string) Path {
+	p, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// parseSegment parses the first segment of an expression, returning the rest.
+func parseSegment(expr string) (Segment, string, error) {
+	switch expr[0] {
+	case '[':
+		end := strings.IndexByte(expr, ']')
+		if end < 0 {
+			return Segment{}, "", fmt.Errorf("missing ] in %s", expr)
+		}
+		inside := strings.TrimSpace(expr[1:end])
+		if inside == "*" {
+			return Wildcard(), expr[end+1:], nil
+		}
+		if inside != "" && (inside[0] == '"' || inside[0] == '\'') {
+			key, rest, err := parseQuoted(strings.TrimSpace(expr[1:]))
+			if err != nil {
+				return Segment{}, "", err
+			}
+			rest = strings.TrimSpace(rest)
+			if !strings.HasPrefix(rest, "]") {
+				return Segment{}, "", fmt.Errorf("missing ] after %s", strconv.Quote(key))
+			}
+			return Key(key), rest[1:], nil
+		}
+		index, err := strconv.Atoi(inside)
+		if err != nil || index < 0 {
+			return Segment{}, "", fmt.Errorf("invalid index %s", inside)
+		}
+		return Index(index), expr[end+1:], nil
+	case '"', '\'':
+		key, rest, err := parseQuoted(expr)
+		return Key(key), rest, err
+	}
+	var sb strings.Builder
+	i := 0
+loop:
+	for ; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '\\':
+			if i+1 == len(expr) {
+				return Segment{}, "", fmt.Errorf("trailing \\")
+			}
+			i++
+			sb.WriteByte(expr[i])
+		case '.', '[':
+			break loop
+		case ']':
+			return Segment{}, "", fmt.Errorf("unexpected ]")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	key := sb.String()
+	if key == "" {
+		return Segment{}, "", fmt.Errorf("empty key")
+	}
+	if key == "*" && !strings.Contains(expr[:i], "\\") {
+		return Wildcard(), expr[i:], nil
+	}
+	return Key(key), expr[i:], nil
+}
+
+// parseQuoted parses a quoted key, returning the rest of the expression.
+func parseQuoted(expr string) (string, string, error) {
+	quote := expr[0]
+	if quote == '"' {
+		prefix, err := strconv.QuotedPrefix(expr)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid quoted key %s", expr)
+		}
+		key, _ := strconv.Unquote(prefix)
+		return key, expr[len(prefix):], nil
+	}
+	// Single quotes are escaped doubling them, as in yaml
+	var sb strings.Builder
+	for i := 1; i < len(expr); i++ {
+		if expr[i] == '\'' {
+			if i+1 < len(expr) && expr[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), expr[i+1:], nil
+		}
+		sb.WriteByte(expr[i])
+	}
+	return "", "", fmt.Errorf("missing closing quote in %s", expr)
+}
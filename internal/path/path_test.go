@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package path
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestParse(t *testing.T) {
+	for expr, expected := range map[string]Path{
+		"":                       {},
+		".":                      {},
+		"a":                      {Key("a")},
+		".a.b":                   {Key("a"), Key("b")},
+		"a.b[2].c":               {Key("a"), Key("b"), Index(2), Key("c")},
+		"a[0][1]":                {Key("a"), Index(0), Index(1)},
+		"a.*.c":                  {Key("a"), Wildcard(), Key("c")},
+		"a[*]":                   {Key("a"), Wildcard()},
+		`a\.b.c`:                 {Key("a.b"), Key("c")},
+		`a\*`:                    {Key("a*")},
+		`"a.b".c`:                {Key("a.b"), Key("c")},
+		`'it''s'.c`:              {Key("it's"), Key("c")},
+		`a["b.c"]`:               {Key("a"), Key("b.c")},
+		"metadata.labels.app":    {Key("metadata"), Key("labels"), Key("app")},
+		"spec.containers[*].env": {Key("spec"), Key("containers"), Wildcard(), Key("env")},
+	} {
+		p, err := Parse(expr)
+		if err != nil {
+			t.Fatalf("%s: %s", expr, err)
+		}
+		itesting.AssertDeepEqual(t, expected, p)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for expr, expected := range map[string]string{
+		"a..b":    "empty key",
+		"a[":      "missing ]",
+		"a[x]":    "invalid index x",
+		"a[-1]":   "invalid index -1",
+		`a\`:      "trailing",
+		`"a`:      "invalid quoted key",
+		`'a`:      "missing closing quote",
+		"a]":      "unexpected ]",
+		`"a"b`:    "expected . or [",
+		`a['b'x]`: "missing ] after",
+	} {
+		_, err := Parse(expr)
+		itesting.AssertError(t, expected, err)
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, expr := range []string{
+		"a",
+		"a.b[2].c",
+		"a.*.c",
+		`"a.b".c`,
+		`a."".c`,
+	} {
+		itesting.AssertEqual(t, expr, MustParse(expr).String())
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for _, i := range []struct {
+		pattern  string
+		concrete Path
+		expected bool
+	}{
+		{"", Path{}, true},
+		{"a.b", Path{Key("a"), Key("b")}, true},
+		{"a.b", Path{Key("a"), Key("c")}, false},
+		{"a.b", Path{Key("a")}, false},
+		{"a", Path{Key("a"), Key("b")}, false},
+		{"a.*", Path{Key("a"), Key("b")}, true},
+		{"a.*", Path{Key("a"), Index(3)}, true},
+		{"a[*]", Path{Key("a"), Index(3)}, true},
+		{"a[3]", Path{Key("a"), Index(3)}, true},
+		{"a[3]", Path{Key("a"), Index(2)}, false},
+		{"a.3", Path{Key("a"), Index(3)}, true},
+		{"a[3]", Path{Key("a"), Key("3")}, true},
+	} {
+		itesting.AssertEqual(t, i.expected, MustParse(i.pattern).Match(i.concrete))
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 
 	iio "github.com/amplia-iiot/yutil/internal/io"
 )
@@ -77,11 +78,7 @@ func (o *Options) sanitize() {
 func sanitizeReplacements(node *map[string]any) map[string]interface{} {
 	reps := map[string]interface{}{}
 	for name, v := range *node {
-		if n, ok := v.(map[any]any); ok {
-			reps[name] = sanitizeNode(n)
-		} else {
-			reps[name] = v
-		}
+		reps[name] = sanitizeNode(v)
 	}
 	return reps
 }
@@ -89,10 +86,8 @@ func sanitizeReplacements(node *map[string]any) map[string]interface{} {
 func sanitizeNode(node any) any {
 	if n, ok := (node).(map[any]any); ok {
 		m := map[string]interface{}{}
-		for k, v := range n {
-			if name, ok := k.(string); ok {
-				m[name] = sanitizeNode(v)
-			}
+		for k, v := range SanitizeKeys(n) {
+			m[k] = sanitizeNode(v)
 		}
 		return m
 	} else if n, ok := (node).([]any); ok {
@@ -105,6 +100,48 @@ func sanitizeNode(node any) any {
 	return node
 }
 
+// SanitizeKeys returns the entries of a map with keys of any type by the name
+// of their keys. Keys that are not strings are named as they are written in
+// yaml ("1", "true", "null"...). When several keys have the same name the
+// string key is used, then the first name in order of types.
+func SanitizeKeys(node map[any]any) map[string]any {
+	m := map[string]any{}
+	var others []any
+	for k, v := range node {
+		if name, ok := k.(string); ok {
+			m[name] = v
+		} else {
+			others = append(others, k)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		ni, nj := keyName(others[i]), keyName(others[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return fmt.Sprintf("%T", others[i]) < fmt.Sprintf("%T", others[j])
+	})
+	for _, k := range others {
+		if name := keyName(k); !hasKey(m, name) {
+			m[name] = node[k]
+		}
+	}
+	return m
+}
+
+// keyName returns the name of a key that is not a string.
+func keyName(k any) string {
+	if k == nil {
+		return "null"
+	}
+	return fmt.Sprint(k)
+}
+
+func hasKey(m map[string]any, k string) bool {
+	_, ok := m[k]
+	return ok
+}
+
 func (o *Options) engine() (Engine, error) {
 	switch o.Engine {
 	case Golang:
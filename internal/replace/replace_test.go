@@ -0,0 +1,68 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package replace
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestSanitizeKeys(t *testing.T) {
+	for name, i := range map[string]struct {
+		node     map[any]any
+		expected map[string]any
+	}{
+		"strings": {
+			node:     map[any]any{"a": 1, "b": 2},
+			expected: map[string]any{"a": 1, "b": 2},
+		},
+		"other types": {
+			node:     map[any]any{1: "int", true: "bool", nil: "null", 1.5: "float"},
+			expected: map[string]any{"1": "int", "true": "bool", "null": "null", "1.5": "float"},
+		},
+		"strings take precedence": {
+			node:     map[any]any{1: "int", "1": "string"},
+			expected: map[string]any{"1": "string"},
+		},
+		"nested": {
+			node:     map[any]any{"a": map[any]any{80: "http"}},
+			expected: map[string]any{"a": map[any]any{80: "http"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			itesting.AssertDeepEqual(t, i.expected, SanitizeKeys(i.node))
+		})
+	}
+}
+
+func TestSanitizeReplacements(t *testing.T) {
+	reps := map[string]any{
+		"ports": map[any]any{80: "http", "https": 443},
+		"list":  []any{map[any]any{true: "yes"}},
+	}
+	itesting.AssertDeepEqual(t, map[string]any{
+		"ports": map[string]any{"80": "http", "https": 443},
+		"list":  []any{map[string]any{"true": "yes"}},
+	}, sanitizeReplacements(&reps))
+}
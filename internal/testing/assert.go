@@ -36,6 +36,14 @@ func AssertEqual(t *testing.T, expected interface{}, got interface{}) {
 	t.Fatalf("Received %v (type %v), expected %v (type %v)", got, reflect.TypeOf(got), expected, reflect.TypeOf(expected))
 }
 
+// AssertDeepEqual fails if expected and got are not deeply equal.
+func AssertDeepEqual(t *testing.T, expected interface{}, got interface{}) {
+	if reflect.DeepEqual(expected, got) {
+		return
+	}
+	t.Fatalf("Received %#v (type %v), expected %#v (type %v)", got, reflect.TypeOf(got), expected, reflect.TypeOf(expected))
+}
+
 // AssertError fails if an error is expected and does not contain the expected
 // string.
 func AssertError(t *testing.T, expected string, got error) {
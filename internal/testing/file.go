@@ -24,6 +24,8 @@ package testing
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/amplia-iiot/yutil/internal/io"
@@ -40,6 +42,19 @@ func TempFilePath(t *testing.T, pattern string) string {
 	return tmp.Name()
 }
 
+// WriteFile writes a file with a content in a directory, creating the
+// directories of its name, and returns its path. Fails on error.
+func WriteFile(t *testing.T, dir string, name string, content string) string {
+	file := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
 // ReadFile returns the content of a file. Fails on error.
 func ReadFile(t *testing.T, file string) string {
 	content, err := io.ReadAsString(file)
@@ -48,3 +63,12 @@ func ReadFile(t *testing.T, file string) string {
 	}
 	return content
 }
+
+// Replace returns a text with the first occurrence of a substring replaced.
+// Fails if the text does not contain the substring.
+func Replace(t *testing.T, s string, old string, new string) string {
+	if !strings.Contains(s, old) {
+		t.Fatalf("%q not found in %q", old, s)
+	}
+	return strings.Replace(s, old, new, 1)
+}
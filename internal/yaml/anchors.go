@@ -0,0 +1,255 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package yaml
+
+import (
+	"fmt"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// NormalizeAnchors cleans a node tree like Normalize but keeps anchors, aliases
+// and merge keys as written. Anchors defined more than once are renamed so
+// every anchor name is unique in the document.
+var NormalizeAnchors = func(node *Node) error {
+	uniqueAnchors(node)
+	return normalizeScalars(node)
+}
+
+// uniqueAnchors renames the anchors that redefine a previous anchor, the
+// aliases keep pointing to the node they were parsed with.
+func uniqueAnchors(node *Node) {
+	used := map[string]bool{}
+	walk(node, func(n *Node) bool {
+		if n.Anchor != "" {
+			n.Anchor = unusedAnchor(n.Anchor, used)
+		}
+		return true
+	})
+	syncAliases(node)
+}
+
+// OrderAnchors makes sure that every anchor is defined before its aliases in
+// a node tree whose keys have been reordered. When an alias is found before its
+// anchor they swap places: the alias takes the anchored content and the anchor
+// becomes an alias, which represents the same data.
+var OrderAnchors = func(node *Node) {
+	defined := map[*Node]bool{}
+	walk(node, func(n *Node) bool {
+		if n.Kind == AliasNode {
+			if target := resolveAlias(n); target != nil && !defined[target] {
+				swapAlias(n, target)
+				defined[n] = true
+				return true
+			}
+			return false
+		}
+		if n.Anchor != "" {
+			defined[n] = true
+		}
+		return true
+	})
+	syncAliases(node)
+}
+
+// movePropertyComments moves the line comments written after the anchor or tag
+// of a collection, which yaml.v3 attaches to its first entry, to the
+// collection.
+func movePropertyComments(root *Node, lines []string) {
+	walk(root, func(n *Node) bool {
+		if !isBlock(n) || n.Anchor == "" && n.Style&yaml3.TaggedStyle == 0 {
+			return true
+		}
+		first := n.Content[0]
+		if first.LineComment != "" && first.Line-1 < len(lines) && !strings.Contains(lines[first.Line-1], first.LineComment) {
+			n.LineComment = joinComments(n.LineComment, first.LineComment)
+			first.LineComment = ""
+		}
+		return true
+	})
+}
+
+// swapAlias moves the content of an anchored node to an alias pointing to it,
+// converting the anchored node into an alias. Each node keeps its comments and
+// position.
+func swapAlias(alias *Node, target *Node) {
+	a, t := *alias, *target
+	*alias = t
+	alias.HeadComment, alias.LineComment, alias.FootComment = a.HeadComment, a.LineComment, a.FootComment
+	alias.Line, alias.Column = a.Line, a.Column
+	*target = Node{
+		Kind:        AliasNode,
+		Value:       t.Anchor,
+		Alias:       alias,
+		HeadComment: t.HeadComment,
+		LineComment: t.LineComment,
+		FootComment: t.FootComment,
+		Line:        t.Line,
+		Column:      t.Column,
+	}
+}
+
+// Dedupe replaces the repeated non empty mappings and sequences of a node tree
+// with aliases of their first occurrence, which is anchored. Anchors are named
+// after the key of the first occurrence. Comments inside the repeated nodes
+// must also be the same, the comments of the node itself are kept in the
+// alias.
+var Dedupe = func(node *Node) {
+	used := map[string]bool{}
+	walk(node, func(n *Node) bool {
+		if n.Anchor != "" {
+			used[n.Anchor] = true
+		}
+		return true
+	})
+	type occurrence struct {
+		node *Node
+		name string
+	}
+	seen := map[string]occurrence{}
+	var visit func(n *Node, name string) *Node
+	children := func(n *Node, name string) {
+		switch n.Kind {
+		case MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				n.Content[i+1] = visit(n.Content[i+1], n.Content[i].Value)
+			}
+		case SequenceNode:
+			for i, item := range n.Content {
+				n.Content[i] = visit(item, name)
+			}
+		}
+	}
+	visit = func(n *Node, name string) *Node {
+		if !isBlock(n) {
+			return n
+		}
+		id := fingerprint(n)
+		if first, ok := seen[id]; ok && n.Anchor == "" {
+			if first.node.Anchor == "" {
+				first.node.Anchor = unusedAnchor(first.name, used)
+			}
+			return &Node{
+				Kind:        AliasNode,
+				Value:       first.node.Anchor,
+				Alias:       first.node,
+				HeadComment: n.HeadComment,
+				LineComment: n.LineComment,
+				FootComment: n.FootComment,
+			}
+		} else if !ok {
+			seen[id] = occurrence{n, name}
+		}
+		children(n, name)
+		return n
+	}
+	if root := Root(node); root != nil {
+		children(root, "")
+	}
+}
+
+// fingerprint returns a string that identifies the content of a node,
+// including the comments and anchors inside it but not its own ones.
+func fingerprint(n *Node) string {
+	var sb strings.Builder
+	var write func(n *Node, own bool)
+	write = func(n *Node, own bool) {
+		fmt.Fprintf(&sb, "%d|%s|%q", n.Kind, n.ShortTag(), n.Value)
+		if !own {
+			fmt.Fprintf(&sb, "|%q|%q|%q|%q", n.Anchor, n.HeadComment, n.LineComment, n.FootComment)
+		}
+		sb.WriteString("(")
+		for _, child := range n.Content {
+			write(child, false)
+		}
+		sb.WriteString(")")
+	}
+	write(n, true)
+	return sb.String()
+}
+
+// unusedAnchor returns a valid anchor name based on a name that has not been
+// used, marking it as used.
+func unusedAnchor(name string, used map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+	base = strings.Trim(base, "-")
+	if base == "" {
+		base = "anchor"
+	}
+	anchor := base
+	for i := 2; used[anchor]; i++ {
+		anchor = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[anchor] = true
+	return anchor
+}
+
+// syncAliases writes in every alias the name of the anchor it points to.
+func syncAliases(node *Node) {
+	walk(node, func(n *Node) bool {
+		if n.Kind == AliasNode {
+			if target := resolveAlias(n); target != nil {
+				n.Value = target.Anchor
+			}
+		}
+		return true
+	})
+}
+
+// resolveAlias returns the anchored node an alias points to, nil if unknown.
+func resolveAlias(alias *Node) *Node {
+	target := alias
+	for target != nil && target.Kind == AliasNode {
+		target = target.Alias
+	}
+	return target
+}
+
+// clearAnchors removes the anchors of a node and its children.
+func clearAnchors(node *Node) {
+	walk(node, func(n *Node) bool {
+		n.Anchor = ""
+		return true
+	})
+}
+
+// isMergeKey returns whether a key is a merge key (<<).
+func isMergeKey(k *Node) bool {
+	return k.Kind == ScalarNode && k.ShortTag() == mergeTag
+}
+
+// walk visits a node tree in document order, the children of a node are only
+// visited if visit returns true. Aliases are not followed.
+func walk(node *Node, visit func(n *Node) bool) {
+	if !visit(node) {
+		return
+	}
+	for _, child := range node.Content {
+		walk(child, visit)
+	}
+}
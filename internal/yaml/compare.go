@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"bytes"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+)
+
+// DifferenceKind is how a value differs between two nodes.
+type DifferenceKind int
+
+const (
+	Added   DifferenceKind = iota // The value is only in the second node
+	Removed                       // The value is only in the first node
+	Changed                       // The value is different in both nodes
+)
+
+// Difference is a value that differs between two nodes.
+type Difference struct {
+	Kind DifferenceKind
+	Path path.Path
+	From *Node // Value in the first node, nil if added
+	To   *Node // Value in the second node, nil if removed
+}
+
+// Compare returns the differences between the data of two nodes (or
+// documents), regardless of their format: key order, styles, comments and
+// anchors are ignored, aliases and merge keys are expanded and scalars are
+// compared in their canonical form (0x10 is 16). Mappings are compared by key
+// and sequences by index. The differences are in document order, except the
+// items removed from the end of a sequence, which go from the last one so the
+// differences can be applied one after the other. An empty document is
+// different from any value.
+var Compare = func(a *Node, b *Node) ([]Difference, error) {
+	from, to := normalizedRoot(a), normalizedRoot(b)
+	for _, n := range []*Node{from, to} {
+		if n == nil {
+			continue
+		}
+		if err := normalizeScalars(n); err != nil {
+			return nil, err
+		}
+	}
+	var diffs []Difference
+	switch {
+	case from == nil && to == nil:
+	case from == nil:
+		diffs = append(diffs, Difference{Kind: Added, Path: path.Path{}, To: to})
+	case to == nil:
+		diffs = append(diffs, Difference{Kind: Removed, Path: path.Path{}, From: from})
+	default:
+		compareNodes(from, to, path.Path{}, &diffs)
+	}
+	return diffs, nil
+}
+
+// normalizedRoot returns a copy of the root of a node with its aliases and
+// merge keys expanded, nil if it is an empty document.
+func normalizedRoot(n *Node) *Node {
+	root := Root(n)
+	if root == nil {
+		return nil
+	}
+	doc := &Node{Kind: DocumentNode, Content: []*Node{deepCopy(root)}}
+	expandAliases(doc)
+	return doc.Content[0]
+}
+
+// compareNodes appends the differences between two nodes in a path.
+func compareNodes(a *Node, b *Node, p path.Path, diffs *[]Difference) {
+	switch {
+	case a.Kind == MappingNode && b.Kind == MappingNode:
+		for i := 0; i+1 < len(a.Content); i += 2 {
+			k := a.Content[i]
+			if j := findKey(b.Content, k); j >= 0 {
+				compareNodes(a.Content[i+1], b.Content[j+1], p.Child(k.Value), diffs)
+			} else {
+				*diffs = append(*diffs, Difference{Kind: Removed, Path: p.Child(k.Value), From: a.Content[i+1]})
+			}
+		}
+		for j := 0; j+1 < len(b.Content); j += 2 {
+			if k := b.Content[j]; findKey(a.Content, k) < 0 {
+				*diffs = append(*diffs, Difference{Kind: Added, Path: p.Child(k.Value), To: b.Content[j+1]})
+			}
+		}
+	case a.Kind == SequenceNode && b.Kind == SequenceNode:
+		for i := 0; i < len(a.Content) && i < len(b.Content); i++ {
+			compareNodes(a.Content[i], b.Content[i], p.Item(i), diffs)
+		}
+		for i := len(a.Content) - 1; i >= len(b.Content); i-- {
+			*diffs = append(*diffs, Difference{Kind: Removed, Path: p.Item(i), From: a.Content[i]})
+		}
+		for i := len(a.Content); i < len(b.Content); i++ {
+			*diffs = append(*diffs, Difference{Kind: Added, Path: p.Item(i), To: b.Content[i]})
+		}
+	case a.Kind != b.Kind || a.Kind == ScalarNode && (a.ShortTag() != b.ShortTag() || a.Value != b.Value):
+		*diffs = append(*diffs, Difference{Kind: Changed, Path: p, From: a, To: b})
+	}
+}
+
+// CompactJSON returns a node (or the root of a document) as compact json.
+func CompactJSON(n *Node) (string, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, Root(n)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
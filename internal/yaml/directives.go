@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import yaml3 "gopkg.in/yaml.v3"
+
+// Merge directives, interpreted in the changes when merging nodes (see
+// MergeNodes) and removed from the result.
+const (
+	// ReplaceTag replaces the base value instead of merging it.
+	ReplaceTag = "!replace"
+	// DeleteTag deletes the key (or the list item) from the base.
+	DeleteTag = "!delete"
+	// PatchKey is a mapping key with a directive as value: "replace" replaces
+	// the base mapping and "delete" deletes it.
+	PatchKey = "$patch"
+	// RetainKeysKey is a mapping key with the list of the only keys that the
+	// merged mapping keeps.
+	RetainKeysKey = "$retainKeys"
+)
+
+// directive is a merge directive of a node.
+type directive int
+
+const (
+	noDirective directive = iota
+	replaceDirective
+	deleteDirective
+)
+
+// directiveOf returns the merge directive of a node: its tag or, for mappings,
+// the value of its $patch key.
+func directiveOf(n *Node) directive {
+	switch n.Tag {
+	case ReplaceTag:
+		return replaceDirective
+	case DeleteTag:
+		return deleteDirective
+	}
+	if n.Kind == MappingNode {
+		if j := findKey(n.Content, directiveKey(PatchKey)); j >= 0 {
+			switch n.Content[j+1].Value {
+			case "replace":
+				return replaceDirective
+			case "delete":
+				return deleteDirective
+			}
+		}
+	}
+	return noDirective
+}
+
+// isDirectiveKey returns whether a mapping key is a directive.
+func isDirectiveKey(k *Node) bool {
+	return sameKey(k, directiveKey(PatchKey)) || sameKey(k, directiveKey(RetainKeysKey))
+}
+
+// directiveKey returns the key node of a directive.
+func directiveKey(key string) *Node {
+	return &Node{Kind: ScalarNode, Tag: strTag, Value: key}
+}
+
+// retainKeys returns the keys listed in the $retainKeys directive of a mapping
+// and whether it has the directive.
+func retainKeys(mapping *Node) ([]*Node, bool) {
+	if mapping.Kind != MappingNode {
+		return nil, false
+	}
+	j := findKey(mapping.Content, directiveKey(RetainKeysKey))
+	if j < 0 {
+		return nil, false
+	}
+	var keys []*Node
+	if list := mapping.Content[j+1]; list.Kind == SequenceNode {
+		keys = list.Content
+	}
+	return keys, true
+}
+
+// StripDirectives removes the merge directives of a node tree: the mapping
+// entries and sequence items to delete, the directive keys and the directive
+// tags.
+var StripDirectives = func(node *Node) {
+	stripDirectives(node)
+}
+
+func stripDirectives(node *Node) {
+	if node.Tag == ReplaceTag || node.Tag == DeleteTag {
+		node.Tag = ""
+		node.Style &^= yaml3.TaggedStyle
+	}
+	var content []*Node
+	switch node.Kind {
+	case MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k, v := node.Content[i], node.Content[i+1]
+			if !isDirectiveKey(k) && directiveOf(v) != deleteDirective {
+				content = append(content, k, v)
+			}
+		}
+	case SequenceNode:
+		for _, item := range node.Content {
+			if directiveOf(item) != deleteDirective {
+				content = append(content, item)
+			}
+		}
+	default:
+		content = node.Content
+	}
+	node.Content = content
+	for _, child := range node.Content {
+		stripDirectives(child)
+	}
+}
+
+// stripped returns a copy of a node tree without merge directives.
+func stripped(node *Node) *Node {
+	c := deepCopy(node)
+	stripDirectives(c)
+	return c
+}
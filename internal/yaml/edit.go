@@ -0,0 +1,1101 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// ErrPathNotFound is the error of deleting a path that does not exist.
+var ErrPathNotFound = errors.New("path not found")
+
+// EditValue sets a value in a concrete path (without wildcards) of a document
+// of a yaml content, changing only the text of the value: comments, key order
+// and the format of the rest of the content are kept. The missing keys of the
+// path are added to their mappings, null values are replaced by new mappings
+// (or sequences for an index 0) and an index one past the end of a sequence
+// appends the value. The edited content is parsed again to check that only the
+// value has changed.
+var EditValue = func(content string, document int, p path.Path, value *Node) (string, error) {
+	if p.Wildcards() > 0 {
+		return "", fmt.Errorf("path %s must not contain wildcards", p)
+	}
+	e, err := newEditor(content, document, p)
+	if err != nil {
+		return "", err
+	}
+	expected := deepCopy(e.doc)
+	root, err := assign(Root(expected), p, 0, value)
+	if err != nil {
+		return "", err
+	}
+	expected.Content = []*Node{root}
+	if err := e.set(expected); err != nil {
+		return "", err
+	}
+	return e.result(expected)
+}
+
+// DeleteValue deletes the value in a concrete path of a document of a yaml
+// content (an entry of a mapping or an element of a sequence) with the
+// comments right above it, changing only its text like EditValue. An
+// ErrPathNotFound is returned if the path does not exist.
+var DeleteValue = func(content string, document int, p path.Path) (string, error) {
+	if p.Wildcards() > 0 {
+		return "", fmt.Errorf("path %s must not contain wildcards", p)
+	}
+	if len(p) == 0 {
+		return "", errors.New("the root of a document cannot be deleted")
+	}
+	e, err := newEditor(content, document, p)
+	if err != nil {
+		return "", err
+	}
+	expected := deepCopy(e.doc)
+	if err := remove(Root(expected), p); err != nil {
+		return "", err
+	}
+	if err := e.delete(expected); err != nil {
+		return "", err
+	}
+	return e.result(expected)
+}
+
+// InsertValue inserts a value in a sequence of a document of a yaml content
+// before the element in the index of a concrete path (or at the end if the
+// index is one past the end), changing only the text of the value like
+// EditValue.
+var InsertValue = func(content string, document int, p path.Path, value *Node) (string, error) {
+	if p.Wildcards() > 0 {
+		return "", fmt.Errorf("path %s must not contain wildcards", p)
+	}
+	if len(p) == 0 || !p[len(p)-1].IsIndex() {
+		return "", fmt.Errorf("path %s is not an element of a list", p)
+	}
+	e, err := newEditor(content, document, p)
+	if err != nil {
+		return "", err
+	}
+	parent, i := p[:len(p)-1], p[len(p)-1].Index
+	chain := e.locate(parent)
+	if len(chain) != len(p) || chain[len(chain)-1].node.Kind != SequenceNode {
+		return "", fmt.Errorf("path %s is not a list", parent)
+	}
+	seq := chain[len(chain)-1].node
+	if i >= len(seq.Content) {
+		return EditValue(content, document, p, value)
+	}
+	expected := deepCopy(e.doc)
+	list := Lookup(expected, parent)
+	list.Content = append(list.Content[:i], append([]*Node{value}, list.Content[i:]...)...)
+	if err := e.insertItem(chain, parent, i, expected); err != nil {
+		return "", err
+	}
+	return e.result(expected)
+}
+
+// ContentRoot returns the root of a document of a yaml content as edited by
+// EditValue, with its aliases and merge keys, nil if the content is empty.
+var ContentRoot = func(content string, document int) (*Node, error) {
+	e, err := newEditor(content, document, path.Path{})
+	if err != nil {
+		return nil, err
+	}
+	return Root(e.doc), nil
+}
+
+// Detach returns a copy of a node to be set in another document: aliases and
+// merge keys are expanded and comments and positions are removed.
+var Detach = func(n *Node) *Node {
+	doc := &Node{Kind: DocumentNode, Content: []*Node{deepCopy(n)}}
+	expandAliases(doc)
+	walk(doc.Content[0], func(c *Node) bool {
+		c.HeadComment, c.LineComment, c.FootComment = "", "", ""
+		c.Line, c.Column = 0, 0
+		return true
+	})
+	return doc.Content[0]
+}
+
+// assign returns a node with a value set in a path, starting at a segment.
+func assign(n *Node, p path.Path, i int, value *Node) (*Node, error) {
+	if i == len(p) {
+		return value, nil
+	}
+	if n != nil && n.Kind == AliasNode {
+		return nil, fmt.Errorf("path %s is an alias", p[:i])
+	}
+	if n != nil && isNull(n) {
+		n = nil
+	}
+	s := p[i]
+	if s.IsIndex() {
+		if n == nil && s.Index == 0 {
+			n = &Node{Kind: SequenceNode, Tag: seqTag}
+		} else if n == nil || n.Kind != SequenceNode {
+			return nil, fmt.Errorf("path %s is not a list", p[:i])
+		}
+		switch {
+		case s.Index < len(n.Content):
+			item, err := assign(n.Content[s.Index], p, i+1, value)
+			if err != nil {
+				return nil, err
+			}
+			n.Content[s.Index] = item
+		case s.Index == len(n.Content):
+			item, err := assign(nil, p, i+1, value)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, item)
+		default:
+			return nil, fmt.Errorf("index %d out of range in path %s with %d items", s.Index, p[:i], len(n.Content))
+		}
+		return n, nil
+	}
+	if n == nil {
+		n = emptyMapping()
+	} else if n.Kind != MappingNode {
+		return nil, fmt.Errorf("path %s is not a mapping", p[:i])
+	}
+	if j := keyIndex(n, s.Key); j >= 0 {
+		v, err := assign(n.Content[j+1], p, i+1, value)
+		if err != nil {
+			return nil, err
+		}
+		n.Content[j+1] = v
+		return n, nil
+	}
+	v, err := assign(nil, p, i+1, value)
+	if err != nil {
+		return nil, err
+	}
+	n.Content = append(n.Content, &Node{Kind: ScalarNode, Tag: strTag, Value: s.Key}, v)
+	return n, nil
+}
+
+// remove deletes the value in a path of a node.
+func remove(n *Node, p path.Path) error {
+	for i, s := range p {
+		last := i == len(p)-1
+		if s.IsIndex() && n.Kind == SequenceNode && s.Index < len(n.Content) {
+			if last {
+				n.Content = append(n.Content[:s.Index], n.Content[s.Index+1:]...)
+				return nil
+			}
+			n = n.Content[s.Index]
+			continue
+		}
+		j := -1
+		if !s.IsIndex() && n.Kind == MappingNode {
+			j = keyIndex(n, s.Key)
+		}
+		if j < 0 {
+			break
+		}
+		if last {
+			n.Content = append(n.Content[:j], n.Content[j+2:]...)
+			return nil
+		}
+		n = n.Content[j+1]
+	}
+	return fmt.Errorf("%w: %s", ErrPathNotFound, p)
+}
+
+// keyIndex returns the index of a scalar key in the content of a mapping (not
+// inherited with merge keys), -1 if it is not found.
+func keyIndex(mapping *Node, key string) int {
+	for j := 0; j+1 < len(mapping.Content); j += 2 {
+		if k := mapping.Content[j]; k.Kind == ScalarNode && k.Value == key {
+			return j
+		}
+	}
+	return -1
+}
+
+// editor edits the lines of a yaml content using the positions of the nodes of
+// a document as parsed by yaml.v3.
+type editor struct {
+	lines  []string  // Lines of the content, without line breaks
+	breaks []string  // Line break after each line ("\n" or "\r\n")
+	eol    string    // Line break of the added lines, the first one of the content
+	docs   []*Node   // Documents of the content
+	index  int       // Index of the edited document
+	doc    *Node     // Edited document
+	path   path.Path // Edited path
+	start  int       // First line of the root of the edited document
+	end    int       // Line after the edited document
+	style  Style     // Style of the added yaml, detected from the document
+}
+
+func newEditor(content string, index int, p path.Path) (*editor, error) {
+	docs, err := decodeDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(docs) && (index > 0 || len(docs) > 0) {
+		return nil, fmt.Errorf("document %d not found, the content has %d documents", index, len(docs))
+	}
+	e := &editor{docs: docs, index: index, path: p}
+	e.lines, e.breaks, e.eol = splitLines(content)
+	e.end = len(e.lines)
+	if index == len(docs) {
+		// An empty content
+		e.doc = &Node{Kind: DocumentNode}
+		e.start = e.end
+		return e, nil
+	}
+	e.doc = docs[index]
+	e.start = e.doc.Line - 1
+	if root := Root(e.doc); root != nil {
+		e.start = root.Line - 1
+	}
+	for l := e.start + 1; l < len(e.lines); l++ {
+		if isDocumentMarker(e.lines[l]) {
+			e.end = l
+			break
+		}
+	}
+	e.style = detectStyle(e.doc)
+	return e, nil
+}
+
+// splitLines returns the lines of a content without their line breaks, the line
+// break after each line (none after the last one) and the first line break of
+// the content ("\n" if there are no lines breaks).
+func splitLines(content string) ([]string, []string, string) {
+	lines := strings.Split(content, "\n")
+	breaks := make([]string, len(lines))
+	for i := 0; i+1 < len(lines); i++ {
+		breaks[i] = "\n"
+		if strings.HasSuffix(lines[i], "\r") {
+			lines[i], breaks[i] = strings.TrimSuffix(lines[i], "\r"), "\r\n"
+		}
+	}
+	eol := "\n"
+	if len(breaks) > 1 {
+		eol = breaks[0]
+	}
+	return lines, breaks, eol
+}
+
+// decodeDocuments parses all the documents of a content, keeping the nodes as
+// parsed by yaml.v3.
+func decodeDocuments(content string) ([]*Node, error) {
+	var docs []*Node
+	decoder := yaml3.NewDecoder(strings.NewReader(content))
+	for {
+		doc := &Node{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// isDocumentMarker returns whether a line starts or ends a document.
+func isDocumentMarker(line string) bool {
+	for _, marker := range []string{"---", "..."} {
+		if line == marker || strings.HasPrefix(line, marker+" ") || strings.HasPrefix(line, marker+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// detectStyle returns the indentation used in a document: the indentation of
+// the first nested mapping and whether the first sequence in a mapping is
+// indented.
+func detectStyle(doc *Node) Style {
+	var style Style
+	mappings, sequences := false, false
+	walk(doc, func(n *Node) bool {
+		if n.Kind != MappingNode || n.Style&yaml3.FlowStyle != 0 {
+			return true
+		}
+		for j := 0; j+1 < len(n.Content); j += 2 {
+			k, v := n.Content[j], n.Content[j+1]
+			if v.Style&yaml3.FlowStyle != 0 || v.Line == k.Line {
+				continue
+			}
+			switch {
+			case v.Kind == MappingNode && !mappings && v.Column > k.Column:
+				style.Indent, mappings = v.Column-k.Column, true
+			case v.Kind == SequenceNode && !sequences:
+				style.IndentSequences, sequences = v.Column > k.Column, true
+			}
+		}
+		return true
+	})
+	return style
+}
+
+// link is an existing node of the edited document in a path.
+type link struct {
+	node *Node
+	key  *Node // Key of the node in its mapping, nil in a sequence
+}
+
+// locate returns the existing nodes in a path of the edited document, from
+// the root to the deepest one.
+func (e *editor) locate(p path.Path) []link {
+	n := Root(e.doc)
+	if n == nil {
+		return nil
+	}
+	chain := []link{{node: n}}
+	for _, s := range p {
+		switch {
+		case s.IsIndex() && n.Kind == SequenceNode && s.Index < len(n.Content):
+			n = n.Content[s.Index]
+			chain = append(chain, link{node: n})
+		case !s.IsIndex() && n.Kind == MappingNode && keyIndex(n, s.Key) >= 0:
+			j := keyIndex(n, s.Key)
+			chain = append(chain, link{node: n.Content[j+1], key: n.Content[j]})
+			n = n.Content[j+1]
+		default:
+			return chain
+		}
+	}
+	return chain
+}
+
+// set edits the lines to set the value of the edited path, expected is the
+// edited document.
+func (e *editor) set(expected *Node) error {
+	chain := e.locate(e.path)
+	if len(chain) == 0 {
+		return e.replaceRoot(expected)
+	}
+	k := len(chain) - 1
+	if k < len(e.path) && e.insert(chain[k].node, e.path[:k+1], expected) {
+		return nil
+	}
+	return e.replace(chain, e.path[:k], expected)
+}
+
+// delete edits the lines to delete the edited path, expected is the edited
+// document.
+func (e *editor) delete(expected *Node) error {
+	chain := e.locate(e.path)
+	j := len(chain) - 1
+	parent, old := chain[j-1].node, chain[j].node
+	if parent.Style&yaml3.FlowStyle != 0 && e.try(expected, func() bool { return e.flowDelete(parent, old, chain[j].key) }) {
+		return nil
+	}
+	for i := 0; i < j; i++ {
+		if chain[i].node.Style&yaml3.FlowStyle != 0 {
+			return e.flow(chain[i].node, Lookup(expected, e.path[:i]))
+		}
+	}
+	var l, start, column, end int
+	if key := chain[j].key; key != nil {
+		l = key.Line - 1
+		start, column = e.offset(l, key.Column), key.Column-1
+		end = e.entryEnd(key, old)
+	} else {
+		var ok bool
+		if start, ok = e.dash(old); !ok {
+			return e.unsupported()
+		}
+		l, column = old.Line-1, start
+		end = e.blockEnd(l, column, false)
+	}
+	if len(parent.Content) <= 2 && (parent.Kind == MappingNode || len(parent.Content) == 1) ||
+		strings.TrimSpace(e.lines[l][:start]) != "" {
+		// The parent is left empty or starts in the same line
+		return e.replace(chain[:j], e.path[:j-1], expected)
+	}
+	l = e.attached(l, column, parent)
+	e.splice(l, end+1, nil)
+	if l > e.start && strings.TrimSpace(e.lines[l-1]) == "" && (l >= e.end || strings.TrimSpace(e.lines[l]) == "") {
+		// The blank line that separated the deleted lines
+		e.splice(l-1, l, nil)
+	}
+	return nil
+}
+
+// insertItem edits the lines to insert the element of the expected document
+// in an index of a sequence, the last node of a chain in a path.
+func (e *editor) insertItem(chain []link, p path.Path, i int, expected *Node) error {
+	seq := chain[len(chain)-1].node
+	if seq.Style&yaml3.FlowStyle != 0 {
+		entry := flowEntry(seq, bare(Lookup(expected, p.Item(i))))
+		if e.try(expected, func() bool { return e.flowInsert(seq, seq.Content[i], entry) }) {
+			return nil
+		}
+	}
+	for f := range chain {
+		if chain[f].node.Style&yaml3.FlowStyle != 0 {
+			return e.flow(chain[f].node, Lookup(expected, p[:f]))
+		}
+	}
+	dash, ok := e.dash(seq.Content[i])
+	l := seq.Content[i].Line - 1
+	if !ok || strings.TrimSpace(e.lines[l][:dash]) != "" {
+		// The element starts in the line of its parent
+		return e.replace(chain, p, expected)
+	}
+	lines := e.render(&Node{Kind: SequenceNode, Tag: seqTag, Content: []*Node{bare(Lookup(expected, p.Item(i)))}})
+	if lines == nil {
+		return e.unsupported()
+	}
+	l = e.attached(l, dash, seq)
+	e.splice(l, l, indentLines(lines, dash))
+	return nil
+}
+
+// attached returns the first of the comment lines right above a line of an
+// entry in a column of a parent node, or the line if there are none.
+func (e *editor) attached(l int, column int, parent *Node) int {
+	for l > parent.Line && isComment(e.lines[l-1]) && indentation(e.lines[l-1]) == column {
+		l--
+	}
+	return l
+}
+
+// insert edits the lines to add a new entry or element to the end of a flow
+// collection or a non empty block mapping or sequence, returning whether it
+// could be added.
+func (e *editor) insert(parent *Node, p path.Path, expected *Node) bool {
+	s := p[len(p)-1]
+	value := bare(Lookup(expected, p))
+	if parent.Style&yaml3.FlowStyle != 0 {
+		var entry string
+		switch {
+		case parent.Kind == MappingNode && !s.IsIndex():
+			entry = flowEntry(parent, &Node{Kind: ScalarNode, Tag: strTag, Value: s.Key}, value)
+		case parent.Kind == SequenceNode && s.IsIndex():
+			entry = flowEntry(parent, value)
+		default:
+			return false
+		}
+		return e.try(expected, func() bool { return e.flowInsert(parent, nil, entry) })
+	}
+	if len(parent.Content) == 0 {
+		return false
+	}
+	var lines []string
+	var end, column int
+	switch {
+	case parent.Kind == MappingNode && !s.IsIndex():
+		k, v := parent.Content[len(parent.Content)-2], parent.Content[len(parent.Content)-1]
+		end, column = e.entryEnd(k, v), parent.Content[0].Column-1
+		lines = e.render(&Node{Kind: MappingNode, Tag: mapTag, Content: []*Node{{Kind: ScalarNode, Tag: strTag, Value: s.Key}, value}})
+	case parent.Kind == SequenceNode && s.IsIndex():
+		last := parent.Content[len(parent.Content)-1]
+		dash, ok := e.dash(last)
+		if !ok {
+			return false
+		}
+		end, column = e.blockEnd(last.Line-1, dash, false), dash
+		lines = e.render(&Node{Kind: SequenceNode, Tag: seqTag, Content: []*Node{value}})
+	default:
+		return false
+	}
+	if lines == nil {
+		return false
+	}
+	e.splice(end+1, end+1, indentLines(lines, column))
+	return true
+}
+
+// replace edits the lines to replace the last node of a chain, in a path, with
+// its value in the expected document. Scalars are replaced in their line,
+// other values are written again with their entry and a node inside a flow
+// collection rewrites the outermost flow collection.
+func (e *editor) replace(chain []link, p path.Path, expected *Node) error {
+	j := len(chain) - 1
+	old, value := chain[j].node, Lookup(expected, p)
+	flow := -1
+	for i := 0; i < j && flow < 0; i++ {
+		if chain[i].node.Style&yaml3.FlowStyle != 0 {
+			flow = i
+		}
+	}
+	column := -1
+	if key := chain[j].key; key != nil {
+		column = key.Column - 1
+	} else if j > 0 {
+		dash, ok := e.dash(old)
+		if !ok && flow < 0 {
+			return e.unsupported()
+		}
+		column = dash
+	}
+	if (value.Kind == ScalarNode || value.Kind != AliasNode && len(value.Content) == 0) && e.inline(old, value, flow >= 0, column) {
+		return nil
+	}
+	if flow < 0 && old.Style&yaml3.FlowStyle != 0 && (value.Kind == MappingNode || value.Kind == SequenceNode) {
+		flow = j
+	}
+	switch {
+	case flow >= 0:
+		return e.flow(chain[flow].node, Lookup(expected, p[:flow]))
+	case j == 0:
+		return e.replaceRoot(expected)
+	case chain[j].key != nil:
+		return e.replaceEntry(chain[j].key, old, value)
+	}
+	return e.replaceItem(old, value, column)
+}
+
+// inline replaces a single line scalar (or alias) with a scalar or an empty
+// collection in its line, returning whether it could be replaced. The quotes of
+// a string are kept.
+func (e *editor) inline(old *Node, value *Node, flow bool, column int) bool {
+	if old.Kind != ScalarNode && old.Kind != AliasNode || old.Anchor != "" || old.Style&yaml3.TaggedStyle != 0 ||
+		old.Kind == ScalarNode && old.Style == 0 && old.Value == "" {
+		return false
+	}
+	l := old.Line - 1
+	if !flow && e.blockEnd(l, column, false) != l {
+		return false
+	}
+	text := e.lines[l]
+	start := e.offset(l, old.Column)
+	end := scalarEnd(text, start, old, flow)
+	if end < 0 {
+		return false
+	}
+	v := bare(value)
+	if v.Kind == ScalarNode && v.Style == 0 && v.ShortTag() == strTag && old.Kind == ScalarNode && old.ShortTag() == strTag {
+		v.Style = old.Style & (yaml3.DoubleQuotedStyle | yaml3.SingleQuotedStyle)
+	}
+	s := flowString(v)
+	if flow && v.Kind == ScalarNode && !strings.HasPrefix(s, `"`) && !strings.HasPrefix(s, "'") && strings.ContainsAny(s, ",[]{}") {
+		v.Style = yaml3.DoubleQuotedStyle
+		s = flowString(v)
+	}
+	e.lines[l] = text[:start] + s + text[end:]
+	return true
+}
+
+// replaceEntry writes again the entry of a key of a block mapping with a new
+// value, keeping the text before the colon and the comment of its line.
+func (e *editor) replaceEntry(key *Node, old *Node, value *Node) error {
+	l := key.Line - 1
+	text := e.lines[l]
+	start := e.offset(l, key.Column)
+	colon := keyEnd(text, start, key)
+	if colon < 0 {
+		return e.unsupported()
+	}
+	k := bare(key)
+	lines := e.render(&Node{Kind: MappingNode, Tag: mapTag, Content: []*Node{k, bare(value)}})
+	if lines == nil {
+		return e.unsupported()
+	}
+	if rendered := flowString(k) + ":"; strings.HasPrefix(lines[0], rendered) {
+		lines[0] = text[:colon+1] + lines[0][len(rendered):]
+	} else {
+		lines[0] = text[:start] + lines[0]
+	}
+	if old.Kind == ScalarNode || old.Kind == AliasNode || old.Line != key.Line {
+		lines[0] += lineComment(text, colon+1)
+	}
+	lines = append(lines[:1], indentLines(lines[1:], key.Column-1)...)
+	e.splice(l, e.entryEnd(key, old)+1, lines)
+	return nil
+}
+
+// replaceItem writes again an element of a block sequence, whose dash is in a
+// column, keeping the text before the dash and the comment of a scalar.
+func (e *editor) replaceItem(old *Node, value *Node, dash int) error {
+	l := old.Line - 1
+	text := e.lines[l]
+	lines := e.render(&Node{Kind: SequenceNode, Tag: seqTag, Content: []*Node{bare(value)}})
+	if lines == nil {
+		return e.unsupported()
+	}
+	lines[0] = text[:dash] + lines[0]
+	if old.Kind == ScalarNode || old.Kind == AliasNode {
+		lines[0] += lineComment(text, dash+1)
+	}
+	lines = append(lines[:1], indentLines(lines[1:], dash)...)
+	e.splice(l, e.blockEnd(l, dash, false)+1, lines)
+	return nil
+}
+
+// replaceRoot writes again the root of the edited document, or adds it to an
+// empty document.
+func (e *editor) replaceRoot(expected *Node) error {
+	lines := e.render(bare(Root(expected)))
+	if lines == nil {
+		return e.unsupported()
+	}
+	start, end := e.start, e.start
+	if root := Root(e.doc); root == nil || isNull(root) && root.Value == "" {
+		// Added at the end of the document, before the final new line
+		start, end = e.end, e.end
+		if e.end == len(e.lines) && e.lines[e.end-1] == "" {
+			start, end = e.end-1, e.end-1
+		}
+	} else {
+		end = e.blockEnd(e.start, -1, false) + 1
+	}
+	e.splice(start, end, lines)
+	return nil
+}
+
+// flow writes again a flow collection in a single line with a new value,
+// keeping its properties.
+func (e *editor) flow(collection *Node, value *Node) error {
+	l, start, endLine, end := e.flowBounds(collection)
+	if l < 0 {
+		return e.unsupported()
+	}
+	v := bare(value)
+	v.Anchor, v.Style = "", v.Style&^yaml3.TaggedStyle
+	line := e.lines[l][:start] + flowString(v) + e.lines[endLine][end+1:]
+	e.splice(l, endLine+1, []string{line})
+	return nil
+}
+
+// flowInsert adds the text of an entry to a flow collection before one of its
+// nodes, or after its last entry if it is nil, keeping the text of the rest of
+// entries. It returns whether the entry could be added.
+func (e *editor) flowInsert(collection *Node, before *Node, entry string) bool {
+	if before != nil {
+		l := before.Line - 1
+		i := e.offset(l, before.Column)
+		e.lines[l] = e.lines[l][:i] + entry + ", " + e.lines[l][i:]
+		return true
+	}
+	l, start, endLine, end := e.flowBounds(collection)
+	if l < 0 {
+		return false
+	}
+	// The entry goes after the last text before the closing bracket
+	for pl, pi := endLine, end; ; pl, pi = pl-1, len(e.lines[pl-1]) {
+		from, text := 0, e.lines[pl][:pi]
+		if pl == l {
+			from = start
+		}
+		if c := commentStart(text, from); c >= 0 && pl != endLine {
+			text = text[:c]
+		}
+		text = strings.TrimRight(text, " \t")
+		if len(text) > from {
+			separator := ", "
+			switch {
+			case pl == l && len(text) == start+1:
+				// An empty collection
+				separator = ""
+			case strings.HasSuffix(text, ","):
+				separator = " "
+			}
+			e.lines[pl] = text + separator + entry + e.lines[pl][len(text):]
+			return true
+		}
+		if pl == l {
+			return false
+		}
+	}
+}
+
+// flowDelete removes the text of an entry of a flow collection in a single line
+// (an element or a key and its value) with its separator, keeping the text of
+// the rest of entries. It returns whether the entry could be removed.
+func (e *editor) flowDelete(collection *Node, value *Node, key *Node) bool {
+	l, start, endLine, end := e.flowBounds(collection)
+	if l < 0 || endLine != l {
+		return false
+	}
+	first, step := value, 1
+	if key != nil {
+		first, step = key, 2
+	}
+	i := 0
+	for i < len(collection.Content) && collection.Content[i] != first {
+		i++
+	}
+	if first.Line-1 != l || i == len(collection.Content) {
+		return false
+	}
+	text := e.lines[l]
+	from := e.offset(l, first.Column)
+	switch {
+	case i+step < len(collection.Content):
+		// Up to the next entry
+		next := collection.Content[i+step]
+		if next.Line-1 != l {
+			return false
+		}
+		e.lines[l] = text[:from] + text[e.offset(l, next.Column):]
+	case i > 0:
+		// From the comma after the previous entry
+		before := strings.TrimRight(text[:from], " \t")
+		if !strings.HasSuffix(before, ",") {
+			return false
+		}
+		e.lines[l] = before[:len(before)-1] + text[end:]
+	default:
+		e.lines[l] = text[:start+1] + text[end:]
+	}
+	return true
+}
+
+// flowBounds returns the line and offset of the opening bracket of a flow
+// collection and the line and offset of its closing bracket, -1 if they are
+// not found.
+func (e *editor) flowBounds(collection *Node) (int, int, int, int) {
+	l := collection.Line - 1
+	offset := e.offset(l, collection.Column)
+	start := strings.IndexAny(e.lines[l][offset:], "[{")
+	if start < 0 {
+		return -1, -1, -1, -1
+	}
+	start += offset
+	endLine, end := e.flowEnd(l, start)
+	if endLine < 0 {
+		return -1, -1, -1, -1
+	}
+	return l, start, endLine, end
+}
+
+// flowEntry returns the text of an entry of a flow collection: an element of a
+// sequence or a key and its value in a mapping.
+func flowEntry(collection *Node, nodes ...*Node) string {
+	c := &Node{Kind: collection.Kind, Tag: seqTag, Style: yaml3.FlowStyle, Content: nodes}
+	if c.Kind == MappingNode {
+		c.Tag = mapTag
+	}
+	s := flowString(c)
+	return s[1 : len(s)-1]
+}
+
+// flowEnd returns the line and offset of the bracket closing a flow collection
+// that starts in a line and offset, -1 if it is not found.
+func (e *editor) flowEnd(l int, start int) (int, int) {
+	depth := 0
+	var quote byte
+	for ; l < e.end; l, start = l+1, 0 {
+		text := e.lines[l]
+		for i := start; i < len(text); i++ {
+			c := text[i]
+			switch {
+			case quote == '"' && c == '\\':
+				i++
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,:", text[i-1]) >= 0):
+				quote = c
+			case c == '#' && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t'):
+				i = len(text)
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				if depth--; depth == 0 {
+					return l, i
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+// entryEnd returns the last line of the entry of a key of a block mapping.
+func (e *editor) entryEnd(key *Node, value *Node) int {
+	dashes := value.Kind == SequenceNode && value.Style&yaml3.FlowStyle == 0 && value.Line > key.Line && value.Column == key.Column
+	return e.blockEnd(key.Line-1, key.Column-1, dashes)
+}
+
+// blockEnd returns the last line of a block that starts in a line, including
+// the following lines indented more than a column (or starting with a dash in
+// the column) and excluding the trailing blank and comment lines.
+func (e *editor) blockEnd(l int, column int, dashes bool) int {
+	end := l
+	for l++; l < e.end; l++ {
+		text := e.lines[l]
+		if strings.TrimSpace(text) == "" || isComment(text) {
+			continue
+		}
+		indent := indentation(text)
+		if indent > column || dashes && indent == column && strings.HasPrefix(text[indent:], "-") {
+			end = l
+			continue
+		}
+		break
+	}
+	return end
+}
+
+// dash returns the offset of the dash of an element of a block sequence in
+// its line.
+func (e *editor) dash(item *Node) (int, bool) {
+	l := item.Line - 1
+	text := e.lines[l]
+	i := e.offset(l, item.Column) - 1
+	for i >= 0 && (text[i] == ' ' || text[i] == '\t') {
+		i--
+	}
+	if i < 0 || text[i] != '-' {
+		return 0, false
+	}
+	return i, true
+}
+
+// offset returns the offset in a line of a column (in characters, from 1).
+func (e *editor) offset(l int, column int) int {
+	text := e.lines[l]
+	i := 0
+	for c := 1; c < column && i < len(text); c++ {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return i
+}
+
+// render returns the lines of a node written in block style, nil if it cannot
+// be written.
+func (e *editor) render(n *Node) []string {
+	composed, err := ComposeNode(&Node{Kind: DocumentNode, Content: []*Node{n}}, e.style)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(composed, "\n"), "\n")
+}
+
+// splice replaces the lines from one line to (not including) another. The
+// added lines end with the line break of the content, the last one with the
+// line break of the last replaced line.
+func (e *editor) splice(from int, to int, lines []string) {
+	breaks := make([]string, len(lines))
+	for i := range breaks {
+		breaks[i] = e.eol
+	}
+	if to > from && len(lines) > 0 {
+		breaks[len(breaks)-1] = e.breaks[to-1]
+	}
+	spliced := append(append(append([]string{}, e.lines[:from]...), lines...), e.lines[to:]...)
+	e.breaks = append(append(append([]string{}, e.breaks[:from]...), breaks...), e.breaks[to:]...)
+	e.end += len(spliced) - len(e.lines)
+	e.lines = spliced
+}
+
+// content returns the edited lines joined with their line breaks.
+func (e *editor) content() string {
+	var sb strings.Builder
+	for i, line := range e.lines {
+		sb.WriteString(line)
+		if i+1 < len(e.lines) {
+			// The last line of the content may not be the last one now
+			lineBreak := e.breaks[i]
+			if lineBreak == "" {
+				lineBreak = e.eol
+			}
+			sb.WriteString(lineBreak)
+		}
+	}
+	return sb.String()
+}
+
+// result returns the edited content, checking that it is the expected
+// document and the rest of documents have not changed.
+func (e *editor) result(expected *Node) (string, error) {
+	content, ok := e.matches(expected)
+	if !ok {
+		return "", e.unsupported()
+	}
+	return content, nil
+}
+
+// matches returns the edited content and whether it has the expected document
+// and the rest of documents unchanged.
+func (e *editor) matches(expected *Node) (string, bool) {
+	content := e.content()
+	docs, err := decodeDocuments(content)
+	count := len(e.docs)
+	if e.index == count {
+		count++
+	}
+	if err != nil || len(docs) != count {
+		return "", false
+	}
+	m := &merger{}
+	for i, doc := range docs {
+		want := expected
+		if i != e.index {
+			want = e.docs[i]
+		}
+		a, b := Root(want), Root(doc)
+		if (a == nil) != (b == nil) || a != nil && !m.equal(a, b) {
+			return "", false
+		}
+	}
+	return content, true
+}
+
+// try makes an edit of the lines, returning whether it could be made, which is
+// undone unless the content has the expected document.
+func (e *editor) try(expected *Node, edit func() bool) bool {
+	lines, breaks, end := append([]string{}, e.lines...), append([]string{}, e.breaks...), e.end
+	if edit() {
+		if _, ok := e.matches(expected); ok {
+			return true
+		}
+	}
+	e.lines, e.breaks, e.end = lines, breaks, end
+	return false
+}
+
+func (e *editor) unsupported() error {
+	return fmt.Errorf("path %s cannot be edited keeping the format of the content", e.path)
+}
+
+// bare returns a shallow copy of a node without its own comments, which are
+// kept in the edited lines.
+func bare(n *Node) *Node {
+	c := *n
+	c.HeadComment, c.LineComment, c.FootComment = "", "", ""
+	return &c
+}
+
+// scalarEnd returns the offset after a scalar (or alias) that starts at an
+// offset of a line, -1 if it does not end in the line.
+func scalarEnd(text string, start int, n *Node, flow bool) int {
+	switch {
+	case n.Kind == AliasNode:
+		i := start + 1
+		for i < len(text) && text[i] != ' ' && text[i] != '\t' && !(flow && strings.IndexByte(",]}", text[i]) >= 0) {
+			i++
+		}
+		return i
+	case n.Style&yaml3.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return -1
+	case n.Style&yaml3.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(text); i++ {
+			if text[i] == '\'' {
+				if i+1 < len(text) && text[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return -1
+	case n.Style&(yaml3.LiteralStyle|yaml3.FoldedStyle) != 0:
+		return -1
+	}
+	end := len(text)
+	if i := commentStart(text, start); i >= 0 {
+		end = i
+	}
+	if i := strings.IndexAny(text[start:end], ",]}"); flow && i >= 0 {
+		end = start + i
+	}
+	return start + len(strings.TrimRight(text[start:end], " \t"))
+}
+
+// keyEnd returns the offset of the colon after a key that starts at an offset
+// of a line, -1 if it is not found.
+func keyEnd(text string, start int, key *Node) int {
+	i := start
+	if key.Style&(yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle) != 0 && key.Style&yaml3.TaggedStyle == 0 && key.Anchor == "" {
+		if i = scalarEnd(text, start, key, false); i < 0 {
+			return -1
+		}
+	}
+	for ; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// commentStart returns the offset of the comment of a line after an offset,
+// -1 if there is none.
+func commentStart(text string, from int) int {
+	var quote byte
+	for i := from; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == from || strings.IndexByte(" \t[{,", text[i-1]) >= 0):
+			quote = c
+		case c == '#' && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t'):
+			return i
+		}
+	}
+	return -1
+}
+
+// lineComment returns the comment of a line after an offset, with the spaces
+// before it.
+func lineComment(text string, from int) string {
+	i := commentStart(text, from)
+	if i < 0 {
+		return ""
+	}
+	return text[len(strings.TrimRight(text[:i], " \t")):]
+}
+
+// isComment returns whether a line only has a comment.
+func isComment(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "#")
+}
+
+// indentation returns the number of spaces at the start of a line.
+func indentation(text string) int {
+	return len(text) - len(strings.TrimLeft(text, " "))
+}
+
+// indentLines indents the non empty lines with spaces up to a column.
+func indentLines(lines []string, column int) []string {
+	indented := make([]string, len(lines))
+	for i, line := range lines {
+		if line != "" && column > 0 {
+			line = strings.Repeat(" ", column) + line
+		}
+		indented[i] = line
+	}
+	return indented
+}
@@ -0,0 +1,580 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	yaml2 "gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Quote is the preferred quote style for strings that need quotes.
+type Quote int
+
+const (
+	AutoQuote   Quote = iota // Single quotes, double quotes for values that look like other types
+	SingleQuote              // Single quotes, unless the string needs escaping
+	DoubleQuote              // Always double quotes
+)
+
+// Style configures how the emitter writes yaml.
+type Style struct {
+	Indent          int   // Spaces of each indentation level (2 if 0)
+	IndentSequences bool  // Whether sequences are indented inside mappings
+	Width           int   // Maximum line width to fold long strings, 0 never folds
+	Quote           Quote // Preferred quote style
+	EscapeNonASCII  bool  // Whether non ASCII characters are escaped in strings
+}
+
+// emitter writes a node tree as block yaml keeping its comments. Scalars are
+// written by yaml.v3, the emitter takes care of the structure: sequences are
+// not indented inside mappings (unless configured), collections are always
+// written in block style (unless empty) and comments are written where they
+// were found.
+//
+// A head comment starting with an empty line marks a key that was separated
+// from the previous entry by a blank line.
+type emitter struct {
+	buf     strings.Builder
+	style   Style
+	indent  int
+	footEnd int // Position after the last foot comment
+	err     error
+}
+
+func newEmitter(style Style) *emitter {
+	indent := style.Indent
+	if indent == 0 {
+		indent = 2
+	}
+	return &emitter{style: style, indent: indent}
+}
+
+func (e *emitter) String() string {
+	return e.buf.String()
+}
+
+func (e *emitter) document(doc *Node) {
+	root := Root(doc)
+	if root == nil {
+		// A document with only comments
+		e.comment(joinComments(doc.HeadComment, doc.FootComment), 0)
+		return
+	}
+	if doc.Kind == DocumentNode && doc.HeadComment != "" {
+		e.comment(doc.HeadComment, 0)
+		e.newline()
+	}
+	switch {
+	case isBlock(root):
+		e.comment(joinComments(root.HeadComment, root.LineComment), 0)
+		if props := e.properties(root); props != "" {
+			e.write(props)
+			e.newline()
+		}
+		e.block(root, 0, false)
+		e.foot(root.FootComment, 0)
+	default:
+		e.comment(root.HeadComment, 0)
+		e.inline(root, 0)
+		e.foot(root.FootComment, 0)
+	}
+	if doc.Kind == DocumentNode && doc.FootComment != "" {
+		e.newline()
+		e.comment(doc.FootComment, 0)
+	}
+}
+
+// block writes the entries of a non empty mapping or sequence starting at a
+// column. When inline the first entry continues the current line (after a
+// "- ") and its leading comments must have already been written.
+func (e *emitter) block(n *Node, column int, inline bool) {
+	if n.Kind == MappingNode {
+		e.mapping(n, column, inline)
+	} else {
+		e.sequence(n, column, inline)
+	}
+}
+
+func (e *emitter) mapping(n *Node, column int, inline bool) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if i > 0 && (hasBlankLine(k) || e.afterFoot()) {
+			e.newline()
+		}
+		if i > 0 || !inline {
+			e.comment(leadingComment(k, v), column)
+			e.pad(column)
+		}
+		e.key(k, column)
+		e.value(v, column, k.LineComment)
+		if isBlock(v) {
+			e.foot(k.FootComment, column)
+		} else {
+			e.foot(joinComments(v.FootComment, k.FootComment), column)
+		}
+	}
+}
+
+func (e *emitter) sequence(n *Node, column int, inline bool) {
+	for i, item := range n.Content {
+		if i > 0 && e.afterFoot() {
+			e.newline()
+		}
+		if i > 0 || !inline {
+			e.comment(e.itemComment(item), column)
+			e.pad(column)
+		}
+		e.write("-")
+		switch {
+		case e.inlineable(item):
+			e.write(" ")
+			e.block(item, column+2, true)
+		case isBlock(item):
+			if props := e.properties(item); props != "" {
+				e.write(" " + props)
+			}
+			e.lineComment(item.LineComment)
+			e.newline()
+			e.block(item, column+2, false)
+		default:
+			e.write(" ")
+			e.inline(item, column)
+		}
+		e.foot(item.FootComment, column)
+	}
+}
+
+// value writes the value of a mapping entry after its key.
+func (e *emitter) value(v *Node, column int, keyComment string) {
+	if !isBlock(v) {
+		e.write(" ")
+		e.inline(v, column, keyComment)
+		return
+	}
+	if props := e.properties(v); props != "" {
+		e.write(" " + props)
+	}
+	e.lineComment(keyComment, v.LineComment)
+	e.newline()
+	child := column + e.indent
+	if v.Kind == SequenceNode && !e.style.IndentSequences {
+		child = column
+	}
+	e.comment(v.HeadComment, child)
+	e.block(v, child, false)
+	e.foot(v.FootComment, child)
+}
+
+// inline writes a scalar, an alias or an empty collection, followed by its
+// comments.
+func (e *emitter) inline(n *Node, column int, comments ...string) {
+	lines := e.scalar(n, column)
+	if len(lines) == 1 {
+		lines = e.fold(lines[0], column)
+	}
+	e.write(lines[0])
+	e.lineComment(append(comments, n.LineComment)...)
+	e.newline()
+	for _, line := range lines[1:] {
+		e.write(line)
+		e.newline()
+	}
+}
+
+func (e *emitter) key(k *Node, column int) {
+	switch {
+	case k.Kind == AliasNode:
+		e.write("*" + k.Value + " :")
+		return
+	case isMergeKey(k):
+		e.write("<<:")
+		return
+	}
+	if k.Kind == ScalarNode {
+		if lines := e.scalar(k, column); len(lines) == 1 {
+			e.write(lines[0] + ":")
+			return
+		}
+	}
+	// Complex keys are written in flow style
+	e.write("? " + e.flow(k))
+	e.newline()
+	e.pad(column)
+	e.write(":")
+}
+
+// scalar returns the lines of a scalar, alias or empty collection. Lines after
+// the first one are already indented.
+func (e *emitter) scalar(n *Node, column int) []string {
+	switch n.Kind {
+	case AliasNode:
+		return []string{"*" + n.Value}
+	case MappingNode:
+		return []string{prefix(e.properties(n), "{}")}
+	case SequenceNode:
+		return []string{prefix(e.properties(n), "[]")}
+	}
+	c := &Node{Kind: ScalarNode, Tag: n.Tag, Value: n.Value, Style: n.Style, Anchor: n.Anchor}
+	quoteAmbiguous(c)
+	escape := e.style.EscapeNonASCII && !isASCII(c.Value)
+	if escape {
+		c.Style = c.Style&yaml3.TaggedStyle | yaml3.DoubleQuotedStyle
+	}
+	lines := e.encode(c)
+	if len(lines) > 1 && strings.ContainsAny(lines[0][len(scalarProperties(lines[0])):], "123456789") {
+		// Block scalars with an indentation indicator depend on the
+		// indentation of their parent, use double quotes instead
+		c.Style = c.Style&yaml3.TaggedStyle | yaml3.DoubleQuotedStyle
+		lines = e.encode(c)
+	}
+	if len(lines) == 1 {
+		lines = e.requote(c, lines, escape)
+	}
+	if escape {
+		for i, line := range lines {
+			lines[i] = escapeNonASCII(line)
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", column+e.indent) + strings.TrimPrefix(lines[i], strings.Repeat(" ", e.indent))
+		}
+	}
+	return lines
+}
+
+// requote changes the quotes of a single line scalar to the preferred quote
+// style.
+func (e *emitter) requote(c *Node, lines []string, escape bool) []string {
+	var quote byte
+	if value := lines[0][len(scalarProperties(lines[0])):]; value != "" {
+		quote = value[0]
+	}
+	switch {
+	case e.style.Quote == DoubleQuote && quote == '\'':
+		c.Style = c.Style&yaml3.TaggedStyle | yaml3.DoubleQuotedStyle
+	case e.style.Quote == SingleQuote && quote == '"' && !escape && isPrintable(c.Value):
+		c.Style = c.Style&yaml3.TaggedStyle | yaml3.SingleQuotedStyle
+	default:
+		return lines
+	}
+	return e.encode(c)
+}
+
+// fold splits a long single line scalar in several lines as yaml.v2 does, so
+// the files formatted by previous versions keep their format: quoted and plain
+// strings break at the first single space after the maximum width (the first
+// line continues the current line).
+func (e *emitter) fold(line string, column int) []string {
+	start := e.lineLen()
+	if e.style.Width <= 0 || start+utf8.RuneCountInString(line) <= e.style.Width {
+		return []string{line}
+	}
+	properties := scalarProperties(line)
+	value := line[len(properties):]
+	if value == "" || strings.ContainsRune("|>*{[", rune(value[0])) {
+		return []string{line}
+	}
+	// Breaks are only allowed in the value, and not at the edges of a quoted
+	// value (a double quoted value escapes a space starting a line)
+	first, last := len(properties), len(line)
+	var quote byte
+	if value[0] == '\'' || value[0] == '"' {
+		quote, first, last = value[0], first+2, last-2
+	}
+	padding := strings.Repeat(" ", column+e.indent)
+	var lines []string
+	var current strings.Builder
+	col, spaces := start, false
+	for i, r := range line {
+		next := i+1 < len(line) && line[i+1] == ' '
+		if r == ' ' && !spaces && col > e.style.Width && i >= first && i < last && (quote == '"' || !next) {
+			lines = append(lines, current.String())
+			current.Reset()
+			current.WriteString(padding)
+			col = len(padding)
+			if next {
+				current.WriteByte('\\')
+				col++
+			}
+			spaces = true
+			continue
+		}
+		current.WriteRune(r)
+		col++
+		spaces = r == ' '
+	}
+	return append(lines, current.String())
+}
+
+// lineLen returns the length in characters of the current line.
+func (e *emitter) lineLen() int {
+	written := e.buf.String()
+	return utf8.RuneCountInString(written[strings.LastIndexByte(written, '\n')+1:])
+}
+
+// flow returns a node written in flow style in a single line.
+func (e *emitter) flow(n *Node) string {
+	c := deepCopy(n)
+	toFlow(c)
+	return strings.Join(e.encode(c), " ")
+}
+
+func toFlow(n *Node) {
+	switch n.Kind {
+	case MappingNode, SequenceNode:
+		n.Style |= yaml3.FlowStyle
+	case ScalarNode:
+		if strings.Contains(n.Value, "\n") {
+			n.Style = n.Style&yaml3.TaggedStyle | yaml3.DoubleQuotedStyle
+		}
+		quoteAmbiguous(n)
+	}
+	clearComments(n)
+	for _, child := range n.Content {
+		toFlow(child)
+	}
+}
+
+// base60 matches the sexagesimal numbers of yaml 1.1, like 1:20.
+var base60 = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+(?:\.[0-9_]*)?$`)
+
+// quoteAmbiguous writes with double quotes a plain string that would not be read
+// back as a string: << (read as a merge key by yaml.v3, which writes it plain)
+// and the strings that yaml 1.1 parsers (like yaml.v2) would read as a bool or
+// a number, like on, yes, n or 1:20. The strings read as null in yaml 1.1 are
+// already quoted by yaml.v3.
+func quoteAmbiguous(n *Node) {
+	if n.Style&^yaml3.TaggedStyle != 0 || n.ShortTag() != strTag || strings.Contains(n.Value, "\n") {
+		return
+	}
+	if n.Value == "<<" || base60.MatchString(n.Value) {
+		n.Style = n.Style&yaml3.TaggedStyle | yaml3.DoubleQuotedStyle
+		return
+	}
+	var value interface{}
+	if yaml2.Unmarshal([]byte(n.Value), &value) != nil {
+		return
+	}
+	switch value.(type) {
+	case bool, int, int64, uint64, float64:
+		n.Style = n.Style&yaml3.TaggedStyle | yaml3.DoubleQuotedStyle
+	}
+}
+
+// encode returns the lines of a node encoded with yaml.v3.
+func (e *emitter) encode(n *Node) []string {
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(e.indent)
+	if err := encoder.Encode(n); err != nil {
+		e.fail(err)
+	}
+	if err := encoder.Close(); err != nil {
+		e.fail(err)
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// properties returns the anchor and the explicit tag of a collection, if
+// needed.
+func (e *emitter) properties(n *Node) string {
+	var props []string
+	if n.Anchor != "" {
+		props = append(props, "&"+n.Anchor)
+	}
+	if tag := n.ShortTag(); n.Style&yaml3.TaggedStyle != 0 &&
+		!(n.Kind == MappingNode && tag == mapTag || n.Kind == SequenceNode && tag == seqTag) {
+		props = append(props, tag)
+	}
+	return strings.Join(props, " ")
+}
+
+// inlineable returns whether a sequence item can start in the same line as
+// its "- ".
+func (e *emitter) inlineable(n *Node) bool {
+	return isBlock(n) && n.LineComment == "" && e.properties(n) == ""
+}
+
+// itemComment returns the comments to write before a sequence item, including
+// the ones of its first entry when it starts in the same line.
+func (e *emitter) itemComment(item *Node) string {
+	comment := item.HeadComment
+	if e.inlineable(item) {
+		var first string
+		if item.Kind == MappingNode {
+			first = leadingComment(item.Content[0], item.Content[1])
+		} else {
+			first = e.itemComment(item.Content[0])
+		}
+		comment = joinComments(comment, first)
+	}
+	return comment
+}
+
+// leadingComment returns the comments to write before a mapping entry.
+func leadingComment(k, v *Node) string {
+	comment := strings.TrimLeft(k.HeadComment, "\n")
+	if !isBlock(v) {
+		comment = joinComments(comment, v.HeadComment)
+	}
+	return comment
+}
+
+// hasBlankLine returns whether a key was preceded by a blank line.
+func hasBlankLine(k *Node) bool {
+	return strings.HasPrefix(k.HeadComment, "\n")
+}
+
+// isBlock returns whether a node is a non empty collection.
+func isBlock(n *Node) bool {
+	return (n.Kind == MappingNode || n.Kind == SequenceNode) && len(n.Content) > 0
+}
+
+// scalarProperties returns the anchor and tag (followed by a space) at the
+// start of an encoded scalar, if any.
+func scalarProperties(encoded string) string {
+	end := 0
+	for end < len(encoded) && (encoded[end] == '!' || encoded[end] == '&') {
+		i := strings.IndexByte(encoded[end:], ' ')
+		if i < 0 {
+			return encoded
+		}
+		end += i + 1
+	}
+	return encoded[:end]
+}
+
+// isASCII returns whether a string only contains ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintable returns whether a string can be written without escapes.
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeNonASCII escapes the non ASCII characters of a double quoted scalar.
+func escapeNonASCII(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			sb.WriteRune(r)
+		case r <= 0xFFFF:
+			sb.WriteString(fmt.Sprintf("\\u%04X", r))
+		default:
+			sb.WriteString(fmt.Sprintf("\\U%08X", r))
+		}
+	}
+	return sb.String()
+}
+
+func joinComments(comments ...string) string {
+	var nonEmpty []string
+	for _, c := range comments {
+		if c != "" {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
+
+func prefix(properties, value string) string {
+	if properties == "" {
+		return value
+	}
+	return properties + " " + value
+}
+
+func (e *emitter) comment(comment string, column int) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimLeft(comment, "\n"), "\n") {
+		if line != "" {
+			e.pad(column)
+			e.write(line)
+		}
+		e.newline()
+	}
+}
+
+// foot writes a foot comment. The next entry must be separated by a blank
+// line, otherwise the comment would be read as its head comment.
+func (e *emitter) foot(comment string, column int) {
+	if comment == "" {
+		return
+	}
+	e.comment(comment, column)
+	e.footEnd = e.buf.Len()
+}
+
+// afterFoot returns whether the last thing written was a foot comment.
+func (e *emitter) afterFoot() bool {
+	return e.footEnd > 0 && e.footEnd == e.buf.Len()
+}
+
+func (e *emitter) lineComment(comments ...string) {
+	for _, c := range comments {
+		if c != "" {
+			e.write(" " + c)
+		}
+	}
+}
+
+func (e *emitter) pad(column int) {
+	e.write(strings.Repeat(" ", column))
+}
+
+func (e *emitter) write(s string) {
+	e.buf.WriteString(s)
+}
+
+func (e *emitter) newline() {
+	e.buf.WriteByte('\n')
+}
+
+func (e *emitter) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
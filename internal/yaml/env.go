@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultEnvSeparator is the default separator of the keys in the names of
+// environment variables.
+const DefaultEnvSeparator = "__"
+
+// EnvVariable is an environment variable that sets the value of a path.
+type EnvVariable struct {
+	Name  string   // Name of the variable
+	Keys  []string // Keys of the path, in lower case
+	Value *Node    // Value of the variable, with its inferred type
+}
+
+// ParseEnv returns the environment variables ("NAME=VALUE") whose name starts
+// with a prefix followed by a separator (DefaultEnvSeparator if empty), sorted
+// by name. The rest of the name,
+// split by the separator, are the keys of the path set by the variable. The
+// type of the value is inferred (see InferValue).
+var ParseEnv = func(environ []string, prefix string, separator string) []EnvVariable {
+	if separator == "" {
+		separator = DefaultEnvSeparator
+	}
+	var vars []EnvVariable
+	for _, env := range environ {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(name, prefix+separator) {
+			continue
+		}
+		keys := strings.Split(name[len(prefix)+len(separator):], separator)
+		valid := true
+		for i, key := range keys {
+			keys[i] = strings.ToLower(key)
+			valid = valid && key != ""
+		}
+		if valid {
+			vars = append(vars, EnvVariable{Name: name, Keys: keys, Value: InferValue(value)})
+		}
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// Document returns a document that sets the value of the variable in its path.
+// The keys of the path take the case of the keys of a base document that are
+// equal ignoring case.
+func (v EnvVariable) Document(base *Node) *Node {
+	current := Root(base)
+	root := &Node{Kind: MappingNode}
+	mapping := root
+	for i, key := range v.Keys {
+		if current != nil && current.Kind == MappingNode {
+			var next *Node
+			key, next = envKey(current, key)
+			current = next
+		} else {
+			current = nil
+		}
+		value := v.Value
+		if i < len(v.Keys)-1 {
+			value = &Node{Kind: MappingNode}
+		}
+		mapping.Content = append(mapping.Content, &Node{Kind: ScalarNode, Tag: "!!str", Value: key}, value)
+		mapping = value
+	}
+	return &Node{Kind: DocumentNode, Content: []*Node{root}}
+}
+
+// envKey returns the key of a mapping that is equal to a key of an environment
+// variable (exactly or ignoring case) with its value, or the key of the
+// variable if the mapping has no such key.
+func envKey(mapping *Node, key string) (string, *Node) {
+	found := -1
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if k := mapping.Content[i]; k.Kind == ScalarNode && strings.EqualFold(k.Value, key) {
+			if k.Value == key {
+				return key, mapping.Content[i+1]
+			}
+			if found < 0 {
+				found = i
+			}
+		}
+	}
+	if found < 0 {
+		return key, nil
+	}
+	return mapping.Content[found].Value, mapping.Content[found+1]
+}
@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseJSON parses a json content, which may be a stream of json values, as a
+// stream of document nodes keeping the order of the keys and the lines of the
+// values. An empty content is parsed as an empty mapping and so is a null
+// value.
+var ParseJSON = func(content string) ([]*Node, error) {
+	p := &jsonParser{content: content, decoder: json.NewDecoder(strings.NewReader(content))}
+	p.decoder.UseNumber()
+	for i, c := range content {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+	var docs []*Node
+	for {
+		root, err := p.value()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		if isNull(root) {
+			root = emptyMapping()
+		}
+		docs = append(docs, &Node{Kind: DocumentNode, Content: []*Node{root}, Line: root.Line, Column: root.Column})
+	}
+	if len(docs) == 0 {
+		docs = append(docs, &Node{Kind: DocumentNode, Content: []*Node{emptyMapping()}})
+	}
+	return docs, nil
+}
+
+// jsonParser reads the nodes of a json content.
+type jsonParser struct {
+	content string
+	decoder *json.Decoder
+	lines   []int // Offsets where the lines after the first start
+}
+
+// value returns the node of the next json value.
+func (p *jsonParser) value() (*Node, error) {
+	line, column := p.position()
+	token, err := p.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{Line: line, Column: column}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			n.Kind, n.Tag = MappingNode, mapTag
+			err = p.object(n)
+		} else {
+			n.Kind, n.Tag = SequenceNode, seqTag
+			err = p.array(n)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case string:
+		n.Kind, n.Tag, n.Value = ScalarNode, strTag, t
+	case json.Number:
+		n.Kind, n.Tag, n.Value = ScalarNode, intTag, t.String()
+		if strings.ContainsAny(n.Value, ".eE") {
+			n.Tag = floatTag
+		}
+	case bool:
+		n.Kind, n.Tag, n.Value = ScalarNode, boolTag, fmt.Sprint(t)
+	case nil:
+		n.Kind, n.Tag, n.Value = ScalarNode, nullTag, "null"
+	}
+	return n, nil
+}
+
+// object reads the entries of a json object until its end.
+func (p *jsonParser) object(n *Node) error {
+	for p.decoder.More() {
+		k, err := p.value()
+		if err != nil {
+			return err
+		}
+		v, err := p.value()
+		if err != nil {
+			return err
+		}
+		n.Content = append(n.Content, k, v)
+	}
+	_, err := p.decoder.Token()
+	return err
+}
+
+// array reads the items of a json array until its end.
+func (p *jsonParser) array(n *Node) error {
+	for p.decoder.More() {
+		item, err := p.value()
+		if err != nil {
+			return err
+		}
+		n.Content = append(n.Content, item)
+	}
+	_, err := p.decoder.Token()
+	return err
+}
+
+// position returns the line and column (1-based) where the next token starts.
+func (p *jsonParser) position() (int, int) {
+	offset := int(p.decoder.InputOffset())
+	for offset < len(p.content) && strings.ContainsRune(" \t\r\n,:", rune(p.content[offset])) {
+		offset++
+	}
+	line, start := 1, 0
+	for _, l := range p.lines {
+		if l > offset {
+			break
+		}
+		line, start = line+1, l
+	}
+	return line, offset - start + 1
+}
+
+// ComposeJSON writes a stream of document nodes as json values with a number
+// of spaces of indentation, one after the other. The keys keep their order,
+// aliases are resolved and comments are lost.
+var ComposeJSON = func(docs []*Node, indent int) (string, error) {
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		root := Root(doc)
+		if root == nil {
+			continue
+		}
+		var compact bytes.Buffer
+		if err := writeJSON(&compact, root); err != nil {
+			return "", err
+		}
+		if err := json.Indent(&buf, compact.Bytes(), "", strings.Repeat(" ", indent)); err != nil {
+			return "", err
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String(), nil
+}
+
+// writeJSON writes a node as compact json.
+func writeJSON(buf *bytes.Buffer, n *Node) error {
+	if target := resolveAlias(n); target != nil {
+		n = target
+	}
+	switch n.Kind {
+	case MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := marshalJSON(n.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err = writeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return err
+		}
+		scalar, err := marshalJSON(value)
+		if err != nil {
+			return fmt.Errorf("value %s cannot be written as json: %w", n.Value, err)
+		}
+		buf.Write(scalar)
+	}
+	return nil
+}
+
+// marshalJSON returns the json of a value without escaping html characters.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
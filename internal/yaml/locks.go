@@ -0,0 +1,206 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// LockedTag locks a value: the yaml merged after the document that tags it
+// cannot change it.
+const LockedTag = "!locked"
+
+// Locks are the protected values of the merged documents, which the changes
+// cannot modify (see MergeOptions). A value is protected if its path matches
+// any of the protected paths or if a document merged before tagged it with
+// LockedTag.
+type Locks struct {
+	paths  []path.Path           // Protected paths (may contain wildcards)
+	locked map[*Node][]path.Path // Paths locked with the tag in each document
+}
+
+// NewLocks returns the locks of some protected paths.
+func NewLocks(paths ...path.Path) *Locks {
+	return &Locks{paths: paths, locked: map[*Node][]path.Path{}}
+}
+
+// LockError is the error of a merge whose changes modify a protected value.
+type LockError struct {
+	Path     path.Path // Path of the protected value
+	Document *Node     // Document of the changes
+	Changes  *Node     // Node of the changes that modifies the value (or its closest ancestor)
+}
+
+func (e *LockError) Error() string {
+	p := e.Path.String()
+	if p == "" {
+		p = "."
+	}
+	return fmt.Sprintf("protected path %s cannot be changed", p)
+}
+
+// Read removes the locked tags of a document, locking the paths of the tagged
+// values for the documents it is merged with.
+func (l *Locks) Read(doc *Node) {
+	if root := Root(doc); root != nil {
+		l.unlock(doc, root, path.Path{})
+	}
+}
+
+func (l *Locks) unlock(doc *Node, n *Node, p path.Path) {
+	if n.Tag == LockedTag {
+		n.Tag = ""
+		n.Style &^= yaml3.TaggedStyle
+		l.locked[doc] = append(l.locked[doc], p)
+	}
+	switch n.Kind {
+	case MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if k := n.Content[i]; k.Kind == ScalarNode && !isMergeKey(k) {
+				l.unlock(doc, n.Content[i+1], p.Child(k.Value))
+			}
+		}
+	case SequenceNode:
+		for i, item := range n.Content {
+			l.unlock(doc, item, p.Item(i))
+		}
+	}
+}
+
+// protects returns whether a path of a document is protected.
+func (l *Locks) protects(doc *Node, p path.Path) bool {
+	for _, protected := range append(l.paths, l.locked[doc]...) {
+		if protected.Match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockedValue is a copy of a protected value before merging.
+type lockedValue struct {
+	path  path.Path
+	value *Node
+}
+
+// values returns copies of the protected values of a document.
+func (l *Locks) values(doc *Node) []lockedValue {
+	root := Root(doc)
+	if l == nil || root == nil {
+		return nil
+	}
+	m := &merger{}
+	var values []lockedValue
+	var visit func(n *Node, p path.Path)
+	visit = func(n *Node, p path.Path) {
+		n = m.resolve(n)
+		if l.protects(doc, p) {
+			c := &Node{Kind: DocumentNode, Content: []*Node{deepCopy(n)}}
+			expandAliases(c)
+			values = append(values, lockedValue{path: p, value: c.Content[0]})
+			return
+		}
+		switch n.Kind {
+		case MappingNode:
+			entries := m.entries(n)
+			for i := 0; i+1 < len(entries); i += 2 {
+				if k := entries[i]; k.Kind == ScalarNode {
+					visit(entries[i+1], p.Child(k.Value))
+				}
+			}
+		case SequenceNode:
+			for i, item := range n.Content {
+				visit(item, p.Item(i))
+			}
+		}
+	}
+	visit(root, path.Path{})
+	return values
+}
+
+// check returns a LockError if a protected value of the base has changed in
+// the merged document and, otherwise, locks in the merged document the paths
+// locked in both documents.
+func (l *Locks) check(values []lockedValue, base *Node, changes *Node, merged *Node) error {
+	if l == nil {
+		return nil
+	}
+	m := &merger{}
+	for _, v := range values {
+		if n := m.find(Root(merged), v.path); n == nil || !m.equal(n, v.value) {
+			return &LockError{Path: v.path, Document: changes, Changes: m.closest(Root(changes), v.path)}
+		}
+	}
+	locked := append([]path.Path{}, l.locked[base]...)
+	l.locked[merged] = append(locked, l.locked[changes]...)
+	return nil
+}
+
+// find returns the node in a path, following aliases and merge keys, nil if
+// it is not found.
+func (m *merger) find(n *Node, p path.Path) *Node {
+	for _, s := range p {
+		if n = m.child(n, s); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// closest returns the node in a path or, if it is not found, its closest
+// ancestor.
+func (m *merger) closest(n *Node, p path.Path) *Node {
+	for _, s := range p {
+		c := m.child(n, s)
+		if c == nil {
+			break
+		}
+		n = c
+	}
+	return n
+}
+
+// child returns the child of a node in a concrete path segment, nil if it is
+// not found.
+func (m *merger) child(n *Node, s path.Segment) *Node {
+	if n == nil {
+		return nil
+	}
+	switch n = m.resolve(n); n.Kind {
+	case MappingNode:
+		entries := m.entries(n)
+		for i := 0; i+1 < len(entries); i += 2 {
+			if k := entries[i]; k.Kind == ScalarNode && k.Value == s.Key {
+				return entries[i+1]
+			}
+		}
+	case SequenceNode:
+		if s.IsIndex() && s.Index < len(n.Content) {
+			return n.Content[s.Index]
+		}
+	}
+	return nil
+}
@@ -22,7 +22,12 @@ SOFTWARE.
 
 package yaml
 
-import "github.com/imdario/mergo"
+import (
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/imdario/mergo"
+)
 
 var Merge = func(base map[string]interface{}, changes map[string]interface{}) (map[string]interface{}, error) {
 	if err := mergo.Merge(&base, changes, mergo.WithOverwriteWithEmptyValue); err != nil {
@@ -30,3 +35,659 @@ var Merge = func(base map[string]interface{}, changes map[string]interface{}) (m
 	}
 	return base, nil
 }
+
+// ListStrategy is how a sequence is merged with the sequence in the same path
+// of the base.
+type ListStrategy int
+
+const (
+	// ReplaceList replaces the base sequence.
+	ReplaceList ListStrategy = iota
+	// AppendList adds the items after the base items.
+	AppendList
+	// PrependList adds the items before the base items.
+	PrependList
+	// UnionList adds the items that are not in the base (nor repeated) after
+	// the base items.
+	UnionList
+	// MergeListByKey merges each mapping item with the base item with the same
+	// value in the key path, the rest of the items are added after the base
+	// items.
+	MergeListByKey
+)
+
+// ListMerge returns how to merge the sequences in a path, and the path (from
+// each item) of the key identifying the items with MergeListByKey.
+type ListMerge func(p path.Path) (strategy ListStrategy, key path.Path)
+
+// MergeOptions configures how nodes are merged.
+type MergeOptions struct {
+	Lists   ListMerge // How sequences are merged (replaced if nil)
+	Tracker *Tracker  // Tracker of the nodes that set each value (not tracked if nil)
+	// NullDeletes makes a null value delete its key from the base mapping, as
+	// in a JSON merge patch (RFC 7386), instead of replacing the value
+	NullDeletes bool
+	// Check receives the conflicts found while merging (not checked if nil)
+	Check func(c Conflict)
+	// Locks are the protected values, the merge fails with a LockError if the
+	// changes modify any of them (nothing is protected if nil)
+	Locks *Locks
+}
+
+// Conflict is a value of the changes that may be a mistake.
+type Conflict struct {
+	Path    path.Path // Path of the value
+	Base    *Node     // Base value (aliases resolved), nil if the key is not in the base
+	Changes *Node     // Value of the changes (the key if it is not in the base)
+}
+
+// TypeConflict returns whether the conflict is a value of a different kind
+// (mapping, sequence or scalar) than the base value. Otherwise it is a key that
+// is not in the base.
+func (c Conflict) TypeConflict() bool {
+	return c.Base != nil
+}
+
+// MergeNodes merges the changes document into the base document, which is
+// modified and returned. Mappings are merged recursively, keeping the order of
+// the base keys and appending new keys at the end. Sequences are merged with
+// the list strategy of their path (see MergeOptions), replaced by default. Any
+// other value in the changes, null included, replaces the base value. Comments
+// in the changes replace the comments of the base node they are attached to.
+//
+// The changes may contain merge directives, which are removed from the result:
+//   - A !replace tag (or "$patch: replace" in a mapping) replaces the base
+//     value instead of merging it.
+//   - A !delete tag (or "$patch: delete" in a mapping) deletes the key from the
+//     base mapping. In a sequence it deletes the base items with the same key
+//     (MergeListByKey) or the equal items (other list strategies).
+//   - A $retainKeys list in a mapping deletes the keys of the base mapping
+//     that are not listed, after merging.
+//
+// The anchors, aliases and merge keys of the base are kept while they still
+// represent the merged data, which is the same as merging with every alias
+// expanded:
+//   - A change under an alias (or a key inherited with a merge key) only
+//     affects that copy, the alias is expanded (or the key is added to the
+//     mapping) before applying it.
+//   - A change under an anchored node only affects that node, its aliases keep
+//     the original data: the first alias takes the anchor with the original
+//     content and the rest of aliases point to it. The same happens when an
+//     anchored node is replaced.
+//   - Changes that leave the data as it was do not expand anything.
+//
+// The anchors and aliases of the changes are expanded.
+var MergeNodes = func(base *Node, changes *Node, opts MergeOptions) (*Node, error) {
+	locked := opts.Locks.values(base)
+	merged := mergeNodes(base, changes, opts)
+	if err := opts.Locks.check(locked, base, changes, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func mergeNodes(base *Node, changes *Node, opts MergeOptions) *Node {
+	baseRoot, changesRoot := Root(base), Root(changes)
+	switch {
+	case changesRoot == nil:
+		return base
+	case baseRoot == nil:
+		clean(changes, opts)
+		return changes
+	case directiveOf(changesRoot) == deleteDirective:
+		changes.Content[0] = emptyMapping()
+		return changes
+	}
+	expandAliases(changes)
+	if opts.Tracker != nil {
+		opts.Tracker.adopt(changes, changes)
+	}
+	m := &merger{snapshots: map[*Node]*Node{}, opts: opts}
+	base.Content[0] = m.merge(baseRoot, changesRoot, path.Path{})
+	m.restoreAliases(base)
+	base.HeadComment = firstComment(changes.HeadComment, base.HeadComment)
+	base.FootComment = firstComment(changes.FootComment, base.FootComment)
+	return base
+}
+
+// merger merges node trees with anchors.
+type merger struct {
+	snapshots map[*Node]*Node // Original content of the modified anchored nodes
+	opts      MergeOptions
+	changes   *Node // Document of the changes set in a path (see SetNode)
+}
+
+// merge returns the result of merging two nodes in a path.
+func (m *merger) merge(base *Node, changes *Node, p path.Path) *Node {
+	if base.Kind == AliasNode || base.Anchor != "" {
+		switch {
+		case m.covers(base, changes, p):
+			if base.Kind != MappingNode || directiveOf(changes) == replaceDirective {
+				m.opts.Tracker.override(base, changes, base)
+				overrideComments(base, changes)
+				return base
+			}
+		case base.Kind == AliasNode:
+			base = m.expand(base)
+		default:
+			m.snapshot(base)
+		}
+	}
+	replace := directiveOf(changes) == replaceDirective
+	if !replace && base.Kind == SequenceNode && changes.Kind == SequenceNode {
+		if strategy, key := m.list(p); strategy != ReplaceList {
+			m.mergeList(base, changes, p, strategy, key)
+			overrideComments(base, changes)
+			return base
+		}
+	}
+	if replace || base.Kind != MappingNode || changes.Kind != MappingNode {
+		if resolved := m.resolve(base); !replace && m.opts.Check != nil && conflicting(resolved, changes) {
+			m.opts.Check(Conflict{Path: p, Base: resolved, Changes: changes})
+		}
+		clean(changes, m.opts)
+		m.opts.Tracker.override(base, changes, changes)
+		overrideComments(base, changes)
+		changes.HeadComment = base.HeadComment
+		changes.LineComment = base.LineComment
+		changes.FootComment = base.FootComment
+		return changes
+	}
+	for i := 0; i+1 < len(changes.Content); i += 2 {
+		k, v := changes.Content[i], changes.Content[i+1]
+		if isDirectiveKey(k) {
+			continue
+		}
+		if m.deletes(v) {
+			m.deleteKey(base, k)
+			continue
+		}
+		if j := findKey(base.Content, k); j >= 0 {
+			overrideComments(base.Content[j], k)
+			base.Content[j+1] = m.merge(base.Content[j+1], v, p.Child(k.Value))
+		} else if inherited := m.lookup(base, k); inherited != nil {
+			if !m.covers(inherited, v, p.Child(k.Value)) {
+				base.Content = append(base.Content, k, m.merge(m.expand(inherited), v, p.Child(k.Value)))
+			}
+		} else {
+			if m.opts.Check != nil {
+				m.opts.Check(Conflict{Path: p.Child(k.Value), Changes: k})
+			}
+			clean(v, m.opts)
+			base.Content = append(base.Content, k, v)
+		}
+	}
+	if keys, ok := retainKeys(changes); ok {
+		m.retainKeys(base, keys)
+	}
+	overrideComments(base, changes)
+	return base
+}
+
+// conflicting returns whether a value replaces a value of a different kind.
+// Null values never conflict.
+func conflicting(base *Node, changes *Node) bool {
+	return base.Kind != changes.Kind && !isNull(base) && !isNull(changes)
+}
+
+// deletes returns whether a value deletes its key from the base mapping.
+func (m *merger) deletes(v *Node) bool {
+	return directiveOf(v) == deleteDirective || m.opts.NullDeletes && isNull(v)
+}
+
+// clean removes the merge directives of a node added to the base and, if null
+// deletes, the mapping entries with null values.
+func clean(n *Node, opts MergeOptions) {
+	stripDirectives(n)
+	if opts.NullDeletes {
+		removeNulls(n)
+	}
+}
+
+// cleaned returns a cleaned copy of a node (see clean).
+func (m *merger) cleaned(n *Node) *Node {
+	c := deepCopy(n)
+	clean(c, m.opts)
+	return c
+}
+
+// removeNulls removes the entries with null values of a mapping and of the
+// mappings inside it. Sequences are kept as they are.
+func removeNulls(n *Node) {
+	if n.Kind == DocumentNode {
+		for _, child := range n.Content {
+			removeNulls(child)
+		}
+		return
+	}
+	if n.Kind != MappingNode {
+		return
+	}
+	var content []*Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if v := n.Content[i+1]; !isNull(v) {
+			removeNulls(v)
+			content = append(content, n.Content[i], v)
+		}
+	}
+	n.Content = content
+}
+
+// deleteKey deletes a key from a mapping. If the key is inherited with a merge
+// key, the merge keys are replaced with the keys they inherit.
+func (m *merger) deleteKey(mapping *Node, key *Node) {
+	if findKey(mapping.Content, key) < 0 && m.lookup(mapping, key) != nil {
+		m.inline(mapping)
+	}
+	if j := findKey(mapping.Content, key); j >= 0 {
+		mapping.Content = append(mapping.Content[:j], mapping.Content[j+2:]...)
+	}
+}
+
+// retainKeys deletes the keys of a mapping that are not in a list, replacing
+// its merge keys with the keys they inherit first.
+func (m *merger) retainKeys(mapping *Node, keys []*Node) {
+	entries := m.entries(mapping)
+	retained := true
+	for i := 0; i+1 < len(entries); i += 2 {
+		retained = retained && containsKey(keys, entries[i])
+	}
+	if retained {
+		return
+	}
+	m.inline(mapping)
+	var content []*Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if containsKey(keys, mapping.Content[i]) {
+			content = append(content, mapping.Content[i], mapping.Content[i+1])
+		}
+	}
+	mapping.Content = content
+}
+
+// inline replaces the merge keys of a mapping with copies of the entries they
+// inherit that are not already defined.
+func (m *merger) inline(mapping *Node) {
+	var content []*Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if !isMergeKey(mapping.Content[i]) {
+			content = append(content, mapping.Content[i], mapping.Content[i+1])
+		}
+	}
+	if len(content) == len(mapping.Content) {
+		return
+	}
+	entries := m.entries(mapping)
+	for i := 0; i+1 < len(entries); i += 2 {
+		if findKey(content, entries[i]) < 0 {
+			content = append(content, m.expand(entries[i]), m.expand(entries[i+1]))
+		}
+	}
+	mapping.Content = content
+}
+
+// containsKey returns whether a key is in a list of keys.
+func containsKey(keys []*Node, key *Node) bool {
+	for _, k := range keys {
+		if sameKey(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeList merges the items of a sequence into the base sequence with a list
+// strategy other than ReplaceList.
+func (m *merger) mergeList(base *Node, changes *Node, p path.Path, strategy ListStrategy, key path.Path) {
+	var added []*Node
+	deleted := map[*Node]bool{}
+	for _, item := range changes.Content {
+		if directiveOf(item) == deleteDirective {
+			for _, j := range m.matches(base.Content, item, strategy, key) {
+				deleted[base.Content[j]] = true
+			}
+			continue
+		}
+		switch strategy {
+		case UnionList:
+			if m.contains(base.Content, item) || m.contains(added, item) {
+				continue
+			}
+		case MergeListByKey:
+			if j := m.findItem(base.Content, item, key); j >= 0 {
+				base.Content[j] = m.merge(base.Content[j], item, p.Item(j))
+				continue
+			}
+		}
+		stripDirectives(item)
+		added = append(added, item)
+	}
+	if len(deleted) > 0 {
+		var content []*Node
+		for _, item := range base.Content {
+			if !deleted[item] {
+				content = append(content, item)
+			}
+		}
+		base.Content = content
+	}
+	if strategy == PrependList {
+		base.Content = append(added, base.Content...)
+	} else {
+		base.Content = append(base.Content, added...)
+	}
+}
+
+// matches returns the positions of the items matching a node to delete: the
+// item with the same key with MergeListByKey, the equal items otherwise.
+func (m *merger) matches(items []*Node, n *Node, strategy ListStrategy, key path.Path) []int {
+	if strategy == MergeListByKey {
+		if j := m.findItem(items, n, key); j >= 0 {
+			return []int{j}
+		}
+		return nil
+	}
+	n = stripped(n)
+	var matches []int
+	for j, item := range items {
+		if m.equal(item, n) {
+			matches = append(matches, j)
+		}
+	}
+	return matches
+}
+
+// list returns the list strategy of a path.
+func (m *merger) list(p path.Path) (ListStrategy, path.Path) {
+	if m.opts.Lists == nil {
+		return ReplaceList, nil
+	}
+	return m.opts.Lists(p)
+}
+
+// contains returns whether a node is equal to any of the items.
+func (m *merger) contains(items []*Node, n *Node) bool {
+	for _, item := range items {
+		if m.equal(item, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// findItem returns the position of the first item with the same scalar value in
+// the key path as a node, -1 if there is none or the node does not have it.
+func (m *merger) findItem(items []*Node, n *Node, key path.Path) int {
+	id := m.itemKey(n, key)
+	if id == nil {
+		return -1
+	}
+	for j, item := range items {
+		if other := m.itemKey(item, key); other != nil && sameKey(id, other) {
+			return j
+		}
+	}
+	return -1
+}
+
+// itemKey returns the scalar in the key path of a mapping item, nil if it is
+// not a mapping or it does not have a scalar in the path.
+func (m *merger) itemKey(item *Node, key path.Path) *Node {
+	item = m.resolve(item)
+	if item.Kind != MappingNode || len(key) == 0 {
+		return nil
+	}
+	if n := Lookup(item, key); n != nil {
+		if n = m.resolve(n); n.Kind == ScalarNode {
+			return n
+		}
+	}
+	return nil
+}
+
+// covers returns whether merging the changes into a node in a path leaves its
+// data as it was.
+func (m *merger) covers(base *Node, changes *Node, p path.Path) bool {
+	base = m.resolve(base)
+	if directiveOf(changes) == replaceDirective {
+		return m.equal(base, m.cleaned(changes))
+	}
+	if base.Kind == SequenceNode && changes.Kind == SequenceNode {
+		if strategy, key := m.list(p); strategy != ReplaceList {
+			for _, item := range changes.Content {
+				if directiveOf(item) == deleteDirective {
+					if len(m.matches(base.Content, item, strategy, key)) > 0 {
+						return false
+					}
+					continue
+				}
+				switch strategy {
+				case UnionList:
+					if !m.contains(base.Content, item) {
+						return false
+					}
+				case MergeListByKey:
+					j := m.findItem(base.Content, item, key)
+					if j < 0 || !m.covers(base.Content[j], item, p.Item(j)) {
+						return false
+					}
+				default:
+					return false
+				}
+			}
+			return true
+		}
+	}
+	if base.Kind != MappingNode || changes.Kind != MappingNode {
+		return m.equal(base, m.cleaned(changes))
+	}
+	for i := 0; i+1 < len(changes.Content); i += 2 {
+		k, v := changes.Content[i], changes.Content[i+1]
+		switch inherited := m.lookup(base, k); {
+		case isDirectiveKey(k):
+		case m.deletes(v):
+			if inherited != nil {
+				return false
+			}
+		case inherited == nil || !m.covers(inherited, v, p.Child(k.Value)):
+			return false
+		}
+	}
+	if keys, ok := retainKeys(changes); ok {
+		entries := m.entries(base)
+		for i := 0; i+1 < len(entries); i += 2 {
+			if !containsKey(keys, entries[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// equal returns whether two nodes represent the same data.
+func (m *merger) equal(a *Node, b *Node) bool {
+	a, b = m.resolve(a), m.resolve(b)
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case ScalarNode:
+		return a.ShortTag() == b.ShortTag() && a.Value == b.Value
+	case SequenceNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := range a.Content {
+			if !m.equal(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	case MappingNode:
+		ea, eb := m.entries(a), m.entries(b)
+		if len(ea) != len(eb) {
+			return false
+		}
+		for i := 0; i+1 < len(eb); i += 2 {
+			j := findKey(ea, eb[i])
+			if j < 0 || !m.equal(ea[j+1], eb[i+1]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// lookup returns the value of a key in a mapping, including the keys inherited
+// with merge keys, nil if it is not found.
+func (m *merger) lookup(mapping *Node, key *Node) *Node {
+	entries := m.entries(mapping)
+	if j := findKey(entries, key); j >= 0 {
+		return entries[j+1]
+	}
+	return nil
+}
+
+// entries returns the content of a mapping with its merge keys expanded (see
+// expandMergeKeys), without modifying it.
+func (m *merger) entries(mapping *Node) []*Node {
+	var content []*Node
+	var merged []*Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		k, v := mapping.Content[i], mapping.Content[i+1]
+		if !isMergeKey(k) {
+			content = append(content, k, v)
+			continue
+		}
+		switch v = m.resolve(v); v.Kind {
+		case MappingNode:
+			merged = append(merged, m.entries(v)...)
+		case SequenceNode:
+			for _, item := range v.Content {
+				if item = m.resolve(item); item.Kind == MappingNode {
+					merged = append(merged, m.entries(item)...)
+				}
+			}
+		}
+	}
+	for i := 0; i+1 < len(merged); i += 2 {
+		if findKey(content, merged[i]) < 0 {
+			content = append(content, merged[i], merged[i+1])
+		}
+	}
+	return content
+}
+
+// resolve returns the original node an alias points to, or the original
+// content of a node if it has been modified.
+func (m *merger) resolve(n *Node) *Node {
+	if target := resolveAlias(n); target != nil {
+		n = target
+	}
+	if original, ok := m.snapshots[n]; ok {
+		return original
+	}
+	return n
+}
+
+// expand returns a copy without anchors nor comments of the original content of
+// a node, keeping the comments of the node itself.
+func (m *merger) expand(n *Node) *Node {
+	original := m.resolve(n)
+	c := deepCopy(original)
+	m.opts.Tracker.copied(original, c)
+	clearAnchors(c)
+	clearComments(c)
+	c.HeadComment = n.HeadComment
+	c.LineComment = n.LineComment
+	c.FootComment = n.FootComment
+	return c
+}
+
+// snapshot saves the original content of an anchored node before modifying it.
+func (m *merger) snapshot(n *Node) {
+	if _, ok := m.snapshots[n]; !ok {
+		m.snapshots[n] = deepCopy(n)
+	}
+}
+
+// restoreAliases fixes the aliases of a merged document pointing to anchored
+// nodes that have been modified or are no longer in the document: the first
+// one takes the anchor with the original content and the rest point to it.
+func (m *merger) restoreAliases(doc *Node) {
+	present := map[*Node]bool{}
+	walk(doc, func(n *Node) bool {
+		present[n] = true
+		return true
+	})
+	restored := map[*Node]*Node{}
+	var restore func(n *Node)
+	restore = func(n *Node) {
+		for i, child := range n.Content {
+			if child.Kind == AliasNode {
+				target := resolveAlias(child)
+				if target == nil {
+					continue
+				}
+				if r, ok := restored[target]; ok {
+					child.Alias = r
+					continue
+				}
+				if _, modified := m.snapshots[target]; !modified && present[target] {
+					continue
+				}
+				r := m.expand(child)
+				r.Anchor = target.Anchor
+				target.Anchor = ""
+				restored[target] = r
+				n.Content[i] = r
+			}
+			restore(n.Content[i])
+		}
+	}
+	restore(doc)
+	syncAliases(doc)
+}
+
+// overrideComments replaces the comments of a node with the comments of another
+// node, if it has them. A blank line mark (see ParseNodes) is kept and it is not
+// considered a comment.
+func overrideComments(node *Node, from *Node) {
+	if strings.TrimSpace(from.HeadComment) != "" {
+		mark := ""
+		if hasBlankLine(node) {
+			mark = "\n"
+		}
+		node.HeadComment = mark + strings.TrimPrefix(from.HeadComment, "\n")
+	}
+	node.LineComment = firstComment(from.LineComment, node.LineComment)
+	node.FootComment = firstComment(from.FootComment, node.FootComment)
+}
+
+// firstComment returns the first comment that is not empty.
+func firstComment(comments ...string) string {
+	for _, c := range comments {
+		if c != "" {
+			return c
+		}
+	}
+	return ""
+}
+
+// Flatten merges a list of documents, in ascending level of importance, into
+// the first one.
+func Flatten(docs []*Node, opts MergeOptions) (*Node, error) {
+	if len(docs) == 0 {
+		return &Node{Kind: DocumentNode, Content: []*Node{emptyMapping()}}, nil
+	}
+	merged := docs[0]
+	stripDirectives(merged)
+	for _, doc := range docs[1:] {
+		var err error
+		if merged, err = MergeNodes(merged, doc, opts); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
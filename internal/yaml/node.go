@@ -0,0 +1,477 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"reflect"
+	"sort"
+	"unicode"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Node is a yaml node as parsed by yaml.v3. Unlike the map based functions it
+// keeps comments, key order, styles and source positions.
+type Node = yaml3.Node
+
+const (
+	DocumentNode = yaml3.DocumentNode
+	SequenceNode = yaml3.SequenceNode
+	MappingNode  = yaml3.MappingNode
+	ScalarNode   = yaml3.ScalarNode
+	AliasNode    = yaml3.AliasNode
+)
+
+const (
+	nullTag      = "!!null"
+	boolTag      = "!!bool"
+	strTag       = "!!str"
+	intTag       = "!!int"
+	floatTag     = "!!float"
+	timestampTag = "!!timestamp"
+	seqTag       = "!!seq"
+	mapTag       = "!!map"
+	mergeTag     = "!!merge"
+)
+
+// coreTags are the tags that can be resolved from a plain value, they never
+// need to be written.
+var coreTags = map[string]bool{
+	nullTag:      true,
+	boolTag:      true,
+	strTag:       true,
+	intTag:       true,
+	floatTag:     true,
+	timestampTag: true,
+	seqTag:       true,
+	mapTag:       true,
+	mergeTag:     true,
+}
+
+// Root returns the root node of a document, nil if the document is empty.
+func Root(doc *Node) *Node {
+	if doc == nil {
+		return nil
+	}
+	if doc.Kind != DocumentNode {
+		return doc
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// Normalize cleans a node tree: aliases and merge keys are expanded and the
+// styles and values of scalars are reset to their canonical form. Comments are
+// kept.
+var Normalize = func(node *Node) error {
+	expandAliases(node)
+	return normalizeScalars(node)
+}
+
+// expandAliases replaces aliases with a copy of the anchored node and merges
+// the content of merge keys (<<) into their mapping. Anchors are removed.
+func expandAliases(node *Node) {
+	for i, child := range node.Content {
+		if child.Kind == AliasNode {
+			node.Content[i] = aliasCopy(child)
+		}
+		expandAliases(node.Content[i])
+	}
+	node.Anchor = ""
+	if node.Kind == MappingNode {
+		expandMergeKeys(node)
+	}
+}
+
+// aliasCopy returns a deep copy without comments of the node an alias points
+// to, keeping the comments of the alias itself.
+func aliasCopy(alias *Node) *Node {
+	target := alias
+	for target.Kind == AliasNode && target.Alias != nil {
+		target = target.Alias
+	}
+	c := deepCopy(target)
+	clearComments(c)
+	c.HeadComment = alias.HeadComment
+	c.LineComment = alias.LineComment
+	c.FootComment = alias.FootComment
+	c.Line = alias.Line
+	c.Column = alias.Column
+	return c
+}
+
+// expandMergeKeys replaces merge keys in a mapping with the entries of the
+// merged mappings that are not already defined. Explicit keys take precedence
+// over merged ones and, in a sequence of mappings, earlier mappings take
+// precedence over later ones.
+func expandMergeKeys(mapping *Node) {
+	var content []*Node
+	var merged []*Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		k, v := mapping.Content[i], mapping.Content[i+1]
+		if k.Kind != ScalarNode || k.ShortTag() != mergeTag {
+			content = append(content, k, v)
+			continue
+		}
+		switch v.Kind {
+		case MappingNode:
+			merged = append(merged, v.Content...)
+		case SequenceNode:
+			for _, m := range v.Content {
+				if m.Kind == MappingNode {
+					merged = append(merged, m.Content...)
+				}
+			}
+		}
+	}
+	if merged == nil {
+		return
+	}
+	for i := 0; i+1 < len(merged); i += 2 {
+		if findKey(content, merged[i]) < 0 {
+			content = append(content, merged[i], merged[i+1])
+		}
+	}
+	mapping.Content = content
+}
+
+// findKey returns the position of a key with the same value in the content of
+// a mapping, -1 if it is not found.
+func findKey(content []*Node, key *Node) int {
+	for i := 0; i+1 < len(content); i += 2 {
+		if sameKey(content[i], key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameKey returns whether two key nodes represent the same key. Mappings and
+// sequences used as keys are compared by content.
+func sameKey(a, b *Node) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case ScalarNode:
+		return a.ShortTag() == b.ShortTag() && a.Value == b.Value
+	case SequenceNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := range a.Content {
+			if !sameKey(a.Content[i], b.Content[i]) {
+				return false
+			}
+		}
+		return true
+	case MappingNode:
+		if len(a.Content) != len(b.Content) {
+			return false
+		}
+		for i := 0; i+1 < len(a.Content); i += 2 {
+			j := findKey(b.Content, a.Content[i])
+			if j < 0 || !sameKey(a.Content[i+1], b.Content[j+1]) {
+				return false
+			}
+		}
+		return true
+	case AliasNode:
+		return a.Alias == b.Alias
+	}
+	return false
+}
+
+// deepCopy returns a copy of a node and all its children.
+func deepCopy(node *Node) *Node {
+	c := *node
+	c.Content = nil
+	for _, child := range node.Content {
+		c.Content = append(c.Content, deepCopy(child))
+	}
+	return &c
+}
+
+// clearComments removes all comments of a node and its children.
+func clearComments(node *Node) {
+	node.HeadComment = ""
+	node.LineComment = ""
+	node.FootComment = ""
+	for _, child := range node.Content {
+		clearComments(child)
+	}
+}
+
+// normalizeScalars resets styles and writes the values of scalars of core
+// types in their canonical form.
+func normalizeScalars(node *Node) error {
+	switch node.Kind {
+	case ScalarNode:
+		return normalizeScalar(node)
+	case MappingNode, SequenceNode:
+		node.Style &= yaml3.TaggedStyle
+	}
+	for _, child := range node.Content {
+		if err := normalizeScalars(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeScalar(node *Node) error {
+	tag := node.ShortTag()
+	node.Style &= yaml3.TaggedStyle
+	if !coreTags[tag] {
+		return nil
+	}
+	// Core tags can be resolved from the canonical value
+	node.Tag = tag
+	node.Style = 0
+	switch tag {
+	case nullTag:
+		node.Value = "null"
+	case boolTag, intTag, floatTag:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		var canonical Node
+		if err := canonical.Encode(value); err != nil {
+			return err
+		}
+		// The canonical value may resolve to another type (1.0 is written 1)
+		node.Value = canonical.Value
+		node.Tag = plainTag(node.Value)
+	}
+	return nil
+}
+
+// plainTag returns the tag a value resolves to when written without quotes.
+func plainTag(value string) string {
+	return (&Node{Kind: ScalarNode, Value: value}).ShortTag()
+}
+
+// SortKeys orders alphabetically the keys of every mapping in a node tree.
+// Numbers are ordered by value and numeric sequences inside strings are
+// compared as numbers ("a2" goes before "a10").
+var SortKeys = func(node *Node) {
+	OrderKeys(node, func(path.Path) ([]string, bool) { return nil, false })
+}
+
+// KeyOrder returns how to order the keys of the mapping in a path: the priority
+// keys go first in the given order and the rest are sorted alphabetically or,
+// if keepSource, are left in their current order.
+type KeyOrder func(p path.Path) (priority []string, keepSource bool)
+
+// OrderKeys orders the keys of every mapping in a node tree with a key order.
+// Merge keys (<<) always go first.
+var OrderKeys = func(node *Node, order KeyOrder) {
+	orderKeys(node, path.Path{}, order)
+}
+
+func orderKeys(node *Node, p path.Path, order KeyOrder) {
+	switch node.Kind {
+	case DocumentNode:
+		for _, child := range node.Content {
+			orderKeys(child, p, order)
+		}
+	case SequenceNode:
+		for i, child := range node.Content {
+			orderKeys(child, p.Item(i), order)
+		}
+	case MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			orderKeys(node.Content[i+1], p.Child(node.Content[i].Value), order)
+		}
+		priority, keepSource := order(p)
+		rank := func(k *Node) int {
+			if isMergeKey(k) {
+				// Merge keys always go first
+				return -1
+			}
+			for i, key := range priority {
+				if k.Kind == ScalarNode && k.Value == key {
+					return i
+				}
+			}
+			return len(priority)
+		}
+		sortMapping(node, func(a, b *Node) bool {
+			ra, rb := rank(a), rank(b)
+			if ra != rb || ra < len(priority) || keepSource {
+				return ra < rb
+			}
+			return keyLess(a, b)
+		})
+	}
+}
+
+// sortMapping reorders the entries of a mapping with a stable sort.
+func sortMapping(mapping *Node, less func(a, b *Node) bool) {
+	entries := make([][2]*Node, len(mapping.Content)/2)
+	for i := range entries {
+		entries[i] = [2]*Node{mapping.Content[2*i], mapping.Content[2*i+1]}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i][0], entries[j][0])
+	})
+	for i, e := range entries {
+		mapping.Content[2*i] = e[0]
+		mapping.Content[2*i+1] = e[1]
+	}
+}
+
+// keyLess compares two keys with the same criteria yaml.v2 uses when
+// marshalling maps. Mappings and sequences used as keys, which yaml.v2 cannot
+// compare, are ordered by their flow representation.
+func keyLess(a, b *Node) bool {
+	av, bv := keyValue(a), keyValue(b)
+	if valueLess(av, bv) {
+		return true
+	}
+	if valueLess(bv, av) {
+		return false
+	}
+	if a.Kind != ScalarNode && b.Kind != ScalarNode {
+		return flowString(a) < flowString(b)
+	}
+	return false
+}
+
+// flowString returns a node written in flow style in a single line.
+func flowString(n *Node) string {
+	return newEmitter(Style{}).flow(n)
+}
+
+// FlowString returns a node written in flow style in a single line, with its
+// aliases expanded and without comments.
+func FlowString(n *Node) string {
+	return flowString(Bare(n))
+}
+
+// Bare returns a copy of a node with its aliases expanded and without comments.
+func Bare(n *Node) *Node {
+	c := &Node{Kind: DocumentNode, Content: []*Node{deepCopy(n)}}
+	expandAliases(c)
+	clearComments(c.Content[0])
+	return c.Content[0]
+}
+
+// keyValue returns the go value of a key node.
+func keyValue(key *Node) reflect.Value {
+	var value interface{}
+	if key.Kind != ScalarNode || key.Decode(&value) != nil {
+		switch key.Kind {
+		case MappingNode:
+			value = map[string]interface{}{}
+		case SequenceNode:
+			value = []interface{}{}
+		default:
+			value = key.Value
+		}
+	}
+	return reflect.ValueOf(&value).Elem()
+}
+
+func valueLess(a, b reflect.Value) bool {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	ak, bk := a.Kind(), b.Kind()
+	af, aok := keyFloat(a)
+	bf, bok := keyFloat(b)
+	if aok && bok {
+		if af != bf {
+			return af < bf
+		}
+		return ak < bk
+	}
+	if ak != reflect.String || bk != reflect.String {
+		return ak < bk
+	}
+	ar, br := []rune(a.String()), []rune(b.String())
+	for i := 0; i < len(ar) && i < len(br); i++ {
+		if ar[i] == br[i] {
+			continue
+		}
+		al := unicode.IsLetter(ar[i])
+		bl := unicode.IsLetter(br[i])
+		if al && bl {
+			return ar[i] < br[i]
+		}
+		if al || bl {
+			return bl
+		}
+		var ai, bi int
+		var an, bn int64
+		if ar[i] == '0' || br[i] == '0' {
+			for j := i - 1; j >= 0 && unicode.IsDigit(ar[j]); j-- {
+				if ar[j] != '0' {
+					an = 1
+					bn = 1
+					break
+				}
+			}
+		}
+		for ai = i; ai < len(ar) && unicode.IsDigit(ar[ai]); ai++ {
+			an = an*10 + int64(ar[ai]-'0')
+		}
+		for bi = i; bi < len(br) && unicode.IsDigit(br[bi]); bi++ {
+			bn = bn*10 + int64(br[bi]-'0')
+		}
+		if an != bn {
+			return an < bn
+		}
+		if ai != bi {
+			return ai < bi
+		}
+		return ar[i] < br[i]
+	}
+	return len(ar) < len(br)
+}
+
+// keyFloat returns the float value of a number or boolean and whether it is a
+// number or boolean.
+func keyFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
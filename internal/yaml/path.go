@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import "github.com/amplia-iiot/yutil/internal/path"
+
+// Lookup returns the node in a path from a node (or document), nil if the path
+// does not exist. A wildcard segment selects the first key or element.
+func Lookup(node *Node, p path.Path) *Node {
+	node = Root(node)
+	for _, s := range p {
+		if node == nil {
+			return nil
+		}
+		node = child(node, s)
+	}
+	return node
+}
+
+// child returns the first child of a mapping or sequence (or the node an alias
+// points to) matching a segment, nil if there is none.
+func child(node *Node, s path.Segment) *Node {
+	if target := resolveAlias(node); target != nil {
+		node = target
+	}
+	switch node.Kind {
+	case MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if k := node.Content[i]; k.Kind == ScalarNode && s.Match(path.Key(k.Value)) {
+				return node.Content[i+1]
+			}
+		}
+	case SequenceNode:
+		for i, item := range node.Content {
+			if s.Match(path.Index(i)) {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// Selected is a node selected by a path.
+type Selected struct {
+	Path path.Path // Concrete path of the node
+	Node *Node     // Node (the node an alias points to)
+}
+
+// Select returns the nodes in a path from a node (or document), in document
+// order. A wildcard segment selects every key or element, aliases and merge
+// keys are followed.
+func Select(node *Node, p path.Path) []Selected {
+	var selected []Selected
+	if root := Root(node); root != nil {
+		m := &merger{}
+		m.selectPath(root, p, path.Path{}, &selected)
+	}
+	return selected
+}
+
+// selectPath appends the nodes in the rest of a path from a node in a path.
+func (m *merger) selectPath(n *Node, rest path.Path, p path.Path, selected *[]Selected) {
+	n = m.resolve(n)
+	if len(rest) == 0 {
+		*selected = append(*selected, Selected{Path: p, Node: n})
+		return
+	}
+	s := rest[0]
+	switch n.Kind {
+	case MappingNode:
+		entries := m.entries(n)
+		for i := 0; i+1 < len(entries); i += 2 {
+			if k := entries[i]; k.Kind == ScalarNode && s.Match(path.Key(k.Value)) {
+				m.selectPath(entries[i+1], rest[1:], p.Child(k.Value), selected)
+			}
+		}
+	case SequenceNode:
+		for i, item := range n.Content {
+			if s.Match(path.Index(i)) {
+				m.selectPath(item, rest[1:], p.Item(i), selected)
+			}
+		}
+	}
+}
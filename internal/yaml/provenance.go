@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+// Source is where a node was read.
+type Source struct {
+	Name   string // Name of the yaml (usually its file)
+	Line   int    // Line of the node (1-based)
+	Column int    // Column of the node (1-based)
+	Value  string // Value of the node when it was read, in flow style
+}
+
+// Tracker tracks the nodes that set each value while merging (see
+// MergeOptions).
+type Tracker struct {
+	sources map[*Node]Source // Where each node was read
+	names   map[*Node]string // Names of the documents
+	offsets map[*Node]int    // Line offsets of the documents
+	chains  map[*Node][]*Node
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		sources: map[*Node]Source{},
+		names:   map[*Node]string{},
+		offsets: map[*Node]int{},
+		chains:  map[*Node][]*Node{},
+	}
+}
+
+// Read records the source of every node of a document read from a named yaml,
+// whose lines start after a number of lines of its file.
+func (t *Tracker) Read(doc *Node, name string, lineOffset int) {
+	t.names[doc] = name
+	t.offsets[doc] = lineOffset
+	t.adopt(doc, doc)
+}
+
+// adopt records the source of the nodes of a document that are not tracked.
+func (t *Tracker) adopt(doc *Node, node *Node) {
+	walk(node, func(n *Node) bool {
+		if _, ok := t.sources[n]; !ok {
+			s := Source{Name: t.names[doc], Line: n.Line + t.offsets[doc], Column: n.Column}
+			// Merging modifies the nodes of the base, so the value is kept as read
+			if n.Kind != DocumentNode {
+				s.Value = FlowString(n)
+			}
+			t.sources[n] = s
+		}
+		return true
+	})
+}
+
+// Source returns where a node was read and whether it is tracked.
+func (t *Tracker) Source(n *Node) (Source, bool) {
+	s, ok := t.sources[n]
+	return s, ok
+}
+
+// Chain returns the nodes that have set the value of a node, from the first to
+// the one that prevails (the node itself unless it has been overridden with the
+// same value).
+func (t *Tracker) Chain(n *Node) []*Node {
+	if chain, ok := t.chains[n]; ok {
+		return chain
+	}
+	return []*Node{n}
+}
+
+// override records that a value has been overridden by another, the result is
+// the node that remains (one of them).
+func (t *Tracker) override(previous *Node, value *Node, result *Node) {
+	if t == nil {
+		return
+	}
+	chain := append([]*Node{}, t.Chain(previous)...)
+	t.chains[result] = append(chain, t.Chain(value)...)
+}
+
+// copied records that a node tree is a copy of another.
+func (t *Tracker) copied(original *Node, c *Node) {
+	if t == nil {
+		return
+	}
+	if s, ok := t.sources[original]; ok {
+		t.sources[c] = s
+	}
+	if chain, ok := t.chains[original]; ok {
+		t.chains[c] = chain
+	}
+	for i := range c.Content {
+		if i < len(original.Content) {
+			t.copied(original.Content[i], c.Content[i])
+		}
+	}
+}
@@ -23,8 +23,13 @@ SOFTWARE.
 package yaml
 
 import (
+	"errors"
+	"io"
+	"strings"
+
 	"gopkg.in/yaml.v2"
 	yaml2 "gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
 )
 
 var Parse = func(content string) (map[string]interface{}, error) {
@@ -42,3 +47,86 @@ var Unmarshal = func(content []byte) (map[string]interface{}, error) {
 	}
 	return m, nil
 }
+
+// ParseNodes parses the yaml content as a stream of document nodes. The root of
+// a document can be any node (mapping, sequence or scalar) and keys can be of
+// any type. Keys at root level that were separated by a blank line from the
+// previous entry are marked (see emitter). Empty documents are skipped (an
+// empty content is parsed as an empty mapping) unless they have comments, then
+// they have no root node. Null documents are parsed as empty mappings.
+var ParseNodes = func(content string) ([]*Node, error) {
+	var docs []*Node
+	lines := strings.Split(content, "\n")
+	decoder := yaml3.NewDecoder(strings.NewReader(content))
+	for {
+		doc := &Node{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch root := Root(doc); {
+		case root == nil || isNull(root) && root.Value == "":
+			// Empty document, only its comments are kept
+			comments := joinComments(doc.HeadComment, documentRootComments(root), doc.FootComment)
+			if comments == "" {
+				continue
+			}
+			doc.HeadComment, doc.FootComment, doc.Content = "", comments, nil
+		case isNull(root):
+			doc.Content = []*Node{emptyMapping()}
+		default:
+			markBlankLines(root, lines)
+			movePropertyComments(root, lines)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, &Node{Kind: DocumentNode, Content: []*Node{emptyMapping()}})
+	}
+	return docs, nil
+}
+
+// isNull returns whether a node is a null scalar.
+func isNull(n *Node) bool {
+	return n.Kind == ScalarNode && n.ShortTag() == nullTag
+}
+
+// documentRootComments returns the comments of a root node.
+func documentRootComments(root *Node) string {
+	if root == nil {
+		return ""
+	}
+	return joinComments(root.HeadComment, root.LineComment, root.FootComment)
+}
+
+func emptyMapping() *Node {
+	return &Node{Kind: MappingNode, Tag: mapTag}
+}
+
+// markBlankLines marks the root keys preceded by a blank line adding an empty
+// line at the start of their head comment. When the root keys are separated
+// by blank lines the first key is also marked, so it remains separated from
+// the rest if it is moved.
+func markBlankLines(root *Node, lines []string) {
+	if root.Kind != MappingNode {
+		return
+	}
+	separated := false
+	for i := 0; i < len(root.Content); i += 2 {
+		k := root.Content[i]
+		first := k.Line
+		if k.HeadComment != "" {
+			first -= strings.Count(k.HeadComment, "\n") + 1
+		}
+		if first >= 2 && first-2 < len(lines) && strings.TrimSpace(lines[first-2]) == "" {
+			k.HeadComment = "\n" + k.HeadComment
+			separated = separated || i > 0
+		}
+	}
+	if separated && !hasBlankLine(root.Content[0]) {
+		root.Content[0].HeadComment = "\n" + root.Content[0].HeadComment
+	}
+}
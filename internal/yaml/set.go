@@ -0,0 +1,282 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/path"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// ValueType is how a value set in a path is read (see ParseValue).
+type ValueType int
+
+const (
+	// InferredValue infers the type of the value as in a yaml scalar, a value
+	// in yaml flow style is a list or a mapping.
+	InferredValue ValueType = iota
+	// StringValue is always a string.
+	StringValue
+	// JSONValue is a json value.
+	JSONValue
+	// FileValue is the name of a file whose content is the string value.
+	FileValue
+	// IntValue is an integer, in any notation of a yaml int.
+	IntValue
+	// BoolValue is a boolean, in any notation of a yaml bool.
+	BoolValue
+	// YAMLValue is a yaml document, in block or flow style.
+	YAMLValue
+)
+
+// ParseValue returns the node of a value of a type.
+var ParseValue = func(value string, t ValueType) (*Node, error) {
+	switch t {
+	case StringValue:
+		return &Node{Kind: ScalarNode, Tag: "!!str", Value: value}, nil
+	case JSONValue:
+		if strings.TrimSpace(value) == "null" {
+			return &Node{Kind: ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		docs, err := ParseJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) != 1 || strings.TrimSpace(value) == "" {
+			return nil, errors.New("invalid json: a single json value is required")
+		}
+		root := Root(docs[0])
+		walk(root, func(n *Node) bool {
+			n.Line, n.Column = 0, 0
+			return true
+		})
+		return root, nil
+	case FileValue:
+		content, err := io.ReadAsString(value)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: ScalarNode, Tag: "!!str", Value: content}, nil
+	case IntValue, BoolValue:
+		tag, name := intTag, "int"
+		if t == BoolValue {
+			tag, name = boolTag, "bool"
+		}
+		n := InferValue(strings.TrimSpace(value))
+		if n.Kind != ScalarNode || n.ShortTag() != tag || n.Style != 0 {
+			return nil, fmt.Errorf("invalid %s value %q", name, value)
+		}
+		return n, nil
+	case YAMLValue:
+		var doc Node
+		if err := yaml3.Unmarshal([]byte(value), &doc); err != nil {
+			return nil, err
+		}
+		root := Root(&doc)
+		if root == nil {
+			return &Node{Kind: ScalarNode, Tag: nullTag, Value: "null"}, nil
+		}
+		walk(root, func(n *Node) bool {
+			n.Line, n.Column = 0, 0
+			return true
+		})
+		return root, nil
+	}
+	return InferValue(value), nil
+}
+
+// InferValue returns the node of a value with the type inferred as in a yaml
+// scalar (8080 is an int, true a bool...). A value written in yaml flow style
+// is a list or a mapping and any other value is a string.
+func InferValue(value string) *Node {
+	str := &Node{Kind: ScalarNode, Tag: "!!str", Value: value}
+	var doc Node
+	if yaml3.Unmarshal([]byte(value), &doc) != nil {
+		return str
+	}
+	root := Root(&doc)
+	if root == nil {
+		return str
+	}
+	// Values set in a path have no lines
+	walk(root, func(n *Node) bool {
+		n.Line, n.Column = 0, 0
+		return true
+	})
+	switch {
+	case root.Anchor != "" || root.HeadComment != "" || root.LineComment != "" || root.FootComment != "":
+		return str
+	case root.Kind == ScalarNode && root.Style == 0 && root.Value == value:
+		return root
+	case root.Kind == ScalarNode && root.Style&(yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle) != 0:
+		return root
+	case (root.Kind == MappingNode || root.Kind == SequenceNode) && root.Style&yaml3.FlowStyle != 0:
+		return root
+	}
+	return str
+}
+
+// SetNode sets the root of a changes document in a concrete path (without
+// wildcards) of a base document, which is modified and returned. The missing
+// keys of the path are added to their mappings, replacing the values that are
+// not mappings, and an index one past the end of a sequence (or 0 for a new
+// sequence) appends the value. The value is merged into the value in the path
+// as in MergeNodes, with the same options.
+var SetNode = func(base *Node, p path.Path, changes *Node, opts MergeOptions) (*Node, error) {
+	if p.Wildcards() > 0 {
+		return nil, fmt.Errorf("path %s must not contain wildcards", p)
+	}
+	value := Root(changes)
+	if value == nil {
+		return base, nil
+	}
+	if Root(base) == nil {
+		base = &Node{Kind: DocumentNode, Content: []*Node{emptyMapping()}}
+	}
+	locked := opts.Locks.values(base)
+	m := &merger{snapshots: map[*Node]*Node{}, opts: opts, changes: changes}
+	root, err := m.set(Root(base), p, value, path.Path{})
+	if err != nil {
+		return nil, err
+	}
+	base.Content[0] = root
+	m.restoreAliases(base)
+	if err := opts.Locks.check(locked, base, changes, base); err != nil {
+		var lockErr *LockError
+		if errors.As(err, &lockErr) {
+			lockErr.Changes = value
+		}
+		return nil, err
+	}
+	return base, nil
+}
+
+// set returns the result of setting a value in the rest of a path of a node
+// in a path.
+func (m *merger) set(n *Node, rest path.Path, value *Node, p path.Path) (*Node, error) {
+	if len(rest) == 0 {
+		return m.merge(n, value, p), nil
+	}
+	if n.Kind == AliasNode {
+		n = m.expand(n)
+	} else if n.Anchor != "" {
+		m.snapshot(n)
+	}
+	s := rest[0]
+	if s.IsIndex() {
+		if n.Kind != SequenceNode {
+			return nil, fmt.Errorf("path %s is not a list", p)
+		}
+		switch {
+		case s.Index < len(n.Content):
+			item, err := m.set(n.Content[s.Index], rest[1:], value, p.Item(s.Index))
+			if err != nil {
+				return nil, err
+			}
+			n.Content[s.Index] = item
+		case s.Index == len(n.Content):
+			item, err := m.create(rest[1:], value, p.Item(s.Index))
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, item)
+		default:
+			return nil, fmt.Errorf("index %d out of range in path %s with %d items", s.Index, p, len(n.Content))
+		}
+		return n, nil
+	}
+	if n.Kind != MappingNode {
+		created, err := m.create(rest, value, p)
+		if err != nil {
+			return nil, err
+		}
+		return m.merge(n, created, p), nil
+	}
+	k := &Node{Kind: ScalarNode, Tag: "!!str", Value: s.Key}
+	if j := findKey(n.Content, k); j >= 0 {
+		v, err := m.set(n.Content[j+1], rest[1:], value, p.Child(s.Key))
+		if err != nil {
+			return nil, err
+		}
+		n.Content[j+1] = v
+		return n, nil
+	}
+	var v *Node
+	var err error
+	if inherited := m.lookup(n, k); inherited != nil {
+		v, err = m.set(m.expand(inherited), rest[1:], value, p.Child(s.Key))
+	} else {
+		m.adopt(k)
+		if m.opts.Check != nil {
+			m.opts.Check(Conflict{Path: p.Child(s.Key), Changes: k})
+		}
+		v, err = m.create(rest[1:], value, p.Child(s.Key))
+	}
+	if err != nil {
+		return nil, err
+	}
+	n.Content = append(n.Content, k, v)
+	return n, nil
+}
+
+// create returns a new node with a value in the rest of a path of a node in a
+// path.
+func (m *merger) create(rest path.Path, value *Node, p path.Path) (*Node, error) {
+	if len(rest) == 0 {
+		clean(value, m.opts)
+		return value, nil
+	}
+	s := rest[0]
+	var n *Node
+	var err error
+	if s.IsIndex() {
+		if s.Index != 0 {
+			return nil, fmt.Errorf("index %d out of range in path %s with 0 items", s.Index, p)
+		}
+		n = &Node{Kind: SequenceNode, Tag: "!!seq"}
+		var item *Node
+		if item, err = m.create(rest[1:], value, p.Item(0)); err == nil {
+			n.Content = []*Node{item}
+		}
+	} else {
+		k := &Node{Kind: ScalarNode, Tag: "!!str", Value: s.Key}
+		n = emptyMapping()
+		var v *Node
+		if v, err = m.create(rest[1:], value, p.Child(s.Key)); err == nil {
+			n.Content = []*Node{k, v}
+		}
+	}
+	m.adopt(n)
+	return n, err
+}
+
+// adopt records the nodes created to set a value as read from the changes.
+func (m *merger) adopt(n *Node) {
+	if m.opts.Tracker != nil {
+		m.opts.Tracker.adopt(m.changes, n)
+	}
+}
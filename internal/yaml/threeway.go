@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import "github.com/amplia-iiot/yutil/internal/path"
+
+// ThreeWayConflict is a value changed differently by both sides of a three-way
+// merge.
+type ThreeWayConflict struct {
+	Path   path.Path // Path of the value
+	Base   *Node     // Value in the common base, nil if it is not there
+	Ours   *Node     // Value in our side, nil if it is not there
+	Theirs *Node     // Value in their side, nil if it is not there
+}
+
+// MergeThreeWay merges the changes made by two sides (ours and theirs) to a
+// common base, any of which may be nil if the document does not exist. The
+// changes of a single side are taken, both sides merge recursively the keys of
+// mappings and any other value (sequences included) changed differently by
+// both sides is a conflict. It returns the merged root resolving the conflicts
+// with our values, the merged root resolving them with their values (the same
+// node if there are no conflicts, an independent copy otherwise) and the
+// conflicts. A nil root means that the document has been deleted.
+//
+// The nodes should not have aliases nor merge keys (see Normalize). The
+// comments and styles of our side are kept.
+var MergeThreeWay = func(base *Node, ours *Node, theirs *Node) (*Node, *Node, []ThreeWayConflict) {
+	w := &threeWay{m: &merger{}}
+	o, t := w.merge(Root(base), Root(ours), Root(theirs), path.Path{})
+	if t != nil && len(w.conflicts) > 0 {
+		t = deepCopy(t)
+	}
+	return o, t, w.conflicts
+}
+
+// threeWay merges nodes from a common base collecting the conflicts.
+type threeWay struct {
+	m         *merger
+	conflicts []ThreeWayConflict
+}
+
+// merge returns the values of a path resolving the conflicts with our and
+// their values, nil if the path is deleted.
+func (w *threeWay) merge(base *Node, ours *Node, theirs *Node, p path.Path) (*Node, *Node) {
+	switch {
+	case w.equal(ours, theirs), w.equal(base, theirs):
+		return ours, ours
+	case w.equal(base, ours):
+		return theirs, theirs
+	case isMapping(ours) && isMapping(theirs) && (base == nil || isMapping(base)):
+		return w.mergeMappings(base, ours, theirs, p)
+	}
+	w.conflicts = append(w.conflicts, ThreeWayConflict{Path: p, Base: base, Ours: ours, Theirs: theirs})
+	return ours, theirs
+}
+
+// mergeMappings merges the keys of mappings, keeping the order of our keys and
+// adding their new keys after them.
+func (w *threeWay) mergeMappings(base *Node, ours *Node, theirs *Node, p path.Path) (*Node, *Node) {
+	conflicts := len(w.conflicts)
+	o, t := *ours, *ours
+	o.Content, t.Content = nil, nil
+	keys := append([]*Node{}, ours.Content...)
+	for i := 0; i+1 < len(theirs.Content); i += 2 {
+		if findKey(ours.Content, theirs.Content[i]) < 0 {
+			keys = append(keys, theirs.Content[i], theirs.Content[i+1])
+		}
+	}
+	for i := 0; i+1 < len(keys); i += 2 {
+		k := keys[i]
+		ko, kt := w.merge(value(base, k), value(ours, k), value(theirs, k), p.Child(k.Value))
+		if ko != nil {
+			o.Content = append(o.Content, k, ko)
+		}
+		if kt != nil {
+			t.Content = append(t.Content, k, kt)
+		}
+	}
+	if len(w.conflicts) == conflicts {
+		return &o, &o
+	}
+	return &o, &t
+}
+
+// equal returns whether two values, which may be nil, are equal.
+func (w *threeWay) equal(a *Node, b *Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return w.m.equal(a, b)
+}
+
+// isMapping returns whether a value is a mapping.
+func isMapping(n *Node) bool {
+	return n != nil && n.Kind == MappingNode
+}
+
+// value returns the value of a key in a mapping, nil if the mapping is nil or
+// does not have the key.
+func value(mapping *Node, key *Node) *Node {
+	if mapping == nil {
+		return nil
+	}
+	if j := findKey(mapping.Content, key); j >= 0 {
+		return mapping.Content[j+1]
+	}
+	return nil
+}
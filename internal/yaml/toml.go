@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package yaml
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ParseTOML parses a toml content as a single document node. The keys are
+// sorted, toml does not keep their order, and the local dates and times are
+// parsed as strings.
+var ParseTOML = func(content string) ([]*Node, error) {
+	var data map[string]interface{}
+	if err := toml.Unmarshal([]byte(content), &data); err != nil {
+		return nil, fmt.Errorf("invalid toml: %w", err)
+	}
+	root := &Node{}
+	if err := root.Encode(localTimes(data)); err != nil {
+		return nil, err
+	}
+	if isNull(root) {
+		root = emptyMapping()
+	}
+	return []*Node{{Kind: DocumentNode, Content: []*Node{root}}}, nil
+}
+
+// localTimes replaces the local dates and times of toml values with their
+// string representation.
+func localTimes(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, value := range t {
+			t[k] = localTimes(value)
+		}
+	case []interface{}:
+		for i, value := range t {
+			t[i] = localTimes(value)
+		}
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprint(t)
+	}
+	return v
+}
+
+// ComposeTOML writes a stream of document nodes as toml, which must be a single
+// document with a mapping root without null values, toml has no null. The keys
+// are sorted and comments are lost.
+var ComposeTOML = func(docs []*Node) (string, error) {
+	var roots []*Node
+	for _, doc := range docs {
+		if root := Root(doc); root != nil {
+			roots = append(roots, root)
+		}
+	}
+	if len(roots) != 1 || roots[0].Kind != MappingNode {
+		return "", errors.New("toml requires a single document with a mapping")
+	}
+	if p, ok := firstNull(roots[0], path.Path{}); ok {
+		return "", fmt.Errorf("toml cannot write null values, %s is null", p)
+	}
+	var data map[string]interface{}
+	if err := roots[0].Decode(&data); err != nil {
+		return "", err
+	}
+	composed, err := toml.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("the yaml cannot be written as toml: %w", err)
+	}
+	return string(composed), nil
+}
+
+// firstNull returns the path of the first null value of a node tree, following
+// aliases, and whether there is any.
+func firstNull(n *Node, p path.Path) (path.Path, bool) {
+	switch n.Kind {
+	case AliasNode:
+		return firstNull(n.Alias, p)
+	case MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if np, ok := firstNull(n.Content[i+1], p.Child(n.Content[i].Value)); ok {
+				return np, true
+			}
+		}
+	case SequenceNode:
+		for i, item := range n.Content {
+			if np, ok := firstNull(item, p.Item(i)); ok {
+				return np, true
+			}
+		}
+	}
+	return p, isNull(n)
+}
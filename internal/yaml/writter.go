@@ -23,6 +23,8 @@ SOFTWARE.
 package yaml
 
 import (
+	"strings"
+
 	yaml2 "gopkg.in/yaml.v2"
 )
 
@@ -41,3 +43,31 @@ var Marshal = func(data map[string]interface{}) ([]byte, error) {
 	}
 	return buf, nil
 }
+
+// ComposeNode writes a document node as yaml with a style, keeping its
+// comments.
+var ComposeNode = func(doc *Node, style Style) (string, error) {
+	e := newEmitter(style)
+	e.document(doc)
+	if e.err != nil {
+		return "", e.err
+	}
+	return e.String(), nil
+}
+
+// ComposeNodes writes a stream of document nodes as yaml with a style,
+// separating them with "---".
+var ComposeNodes = func(docs []*Node, style Style) (string, error) {
+	var sb strings.Builder
+	for i, doc := range docs {
+		if i > 0 {
+			sb.WriteString("---\n")
+		}
+		composed, err := ComposeNode(doc, style)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(composed)
+	}
+	return sb.String(), nil
+}
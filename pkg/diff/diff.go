@@ -0,0 +1,123 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package diff
+
+import (
+	"errors"
+
+	ipath "github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
+	"github.com/amplia-iiot/yutil/pkg/merge"
+)
+
+// ChangeType is how a value changed.
+type ChangeType string
+
+const (
+	// Added is a value only in the second content.
+	Added ChangeType = "added"
+	// Removed is a value only in the first content.
+	Removed ChangeType = "removed"
+	// Changed is a value different in both contents.
+	Changed ChangeType = "changed"
+)
+
+var changeTypes = map[yaml.DifferenceKind]ChangeType{
+	yaml.Added:   Added,
+	yaml.Removed: Removed,
+	yaml.Changed: Changed,
+}
+
+// Change is a value that differs between two contents.
+type Change struct {
+	Type     ChangeType
+	Document int        // Position of the document in the streams (0-based)
+	Path     string     // Path of the value, empty for the root of the document
+	From     *yaml.Node // Value in the first content, nil if added
+	To       *yaml.Node // Value in the second content, nil if removed
+	path     ipath.Path
+}
+
+// Compare returns the changes between two yaml contents. Json and toml
+// contents are also read (see format.DetectFormat).
+func Compare(from string, to string) ([]Change, error) {
+	fromDocs, err := format.ParseDocuments(from, format.DetectFormat("", from))
+	if err != nil {
+		return nil, err
+	}
+	toDocs, err := format.ParseDocuments(to, format.DetectFormat("", to))
+	if err != nil {
+		return nil, err
+	}
+	return CompareDocuments(fromDocs, toDocs)
+}
+
+// CompareFiles returns the changes between the results of merging two sets of
+// yaml files (see merge.MergeAllFilesToDocuments), so a merged environment can
+// be compared with another one. A single file is compared as read.
+func CompareFiles(from []string, to []string, opts ...merge.Option) ([]Change, error) {
+	if len(from) == 0 || len(to) == 0 {
+		return nil, errors.New("each side must contain at least one file")
+	}
+	fromDocs, err := merge.MergeAllFilesToDocuments(from, opts...)
+	if err != nil {
+		return nil, err
+	}
+	toDocs, err := merge.MergeAllFilesToDocuments(to, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return CompareDocuments(fromDocs, toDocs)
+}
+
+// CompareDocuments returns the changes between two streams of documents,
+// compared by position. A document only in one of the streams is an added or
+// removed root.
+func CompareDocuments(from []*yaml.Node, to []*yaml.Node) ([]Change, error) {
+	var changes []Change
+	for i := 0; i < len(from) || i < len(to); i++ {
+		var a, b *yaml.Node
+		if i < len(from) {
+			a = from[i]
+		}
+		if i < len(to) {
+			b = to[i]
+		}
+		diffs, err := yaml.Compare(a, b)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range diffs {
+			changes = append(changes, Change{
+				Type:     changeTypes[d.Kind],
+				Document: i,
+				Path:     d.Path.String(),
+				From:     d.From,
+				To:       d.To,
+				path:     d.Path,
+			})
+		}
+	}
+	return changes, nil
+}
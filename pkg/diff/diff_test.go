@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package diff
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+const from = `# Base
+server:
+  host: localhost
+  port: 0x1F90
+  debug: true
+tags: [a, b, c]
+owner: &o {name: me}
+maintainer: *o
+`
+
+const to = `{"tags": ["a", "x"], "server": {"port": 8080, "host": "localhost", "tls": {"on": true}}, "owner": {"name": "me"}, "maintainer": {"name": "you"}}`
+
+func TestCompare(t *testing.T) {
+	changes, err := Compare(from, to)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, `- server.debug: true
++ server.tls: {"on": true}
+~ tags[1]: b -> x
+- tags[2]: c
+~ maintainer.name: me -> you
+`, Text(changes, false))
+}
+
+func TestCompareEqual(t *testing.T) {
+	changes, err := Compare("a: 1\nb: 'x' # comment\n", `{"b": "x", "a": 1}`)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, 0, len(changes))
+	changes, err = Compare("a: 1\n", "a: '1'\n")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "~ a: 1 -> \"1\"\n", Text(changes, false))
+}
+
+func TestCompareDocuments(t *testing.T) {
+	changes, err := Compare("a: 1\n---\nb: 1\n", "a: 1\n---\nb: 2\n---\nc: 3\n")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, `--- document 1
+~ b: 1 -> 2
+--- document 2
++ .: {c: 3}
+`, Text(changes, false))
+	_, err = Patch(changes)
+	itesting.AssertError(t, "only contain changes of the first document", err)
+}
+
+func TestTextColor(t *testing.T) {
+	changes, err := Compare("a: 1\nb: 2\n", "a: 2\nc: 3\n")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "\x1b[33m~ a: 1 -> 2\x1b[0m\n\x1b[31m- b: 2\x1b[0m\n\x1b[32m+ c: 3\x1b[0m\n", Text(changes, true))
+}
+
+func TestJSON(t *testing.T) {
+	changes, err := Compare("a: 1\nb: [1, 2]\n", "a: x\nb: [1]\nc: {d: null}\n")
+	itesting.AssertEqual(t, nil, err)
+	output, err := JSON(changes)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, `[
+  {
+    "type": "changed",
+    "document": 0,
+    "path": "a",
+    "from": 1,
+    "to": "x"
+  },
+  {
+    "type": "removed",
+    "document": 0,
+    "path": "b[1]",
+    "from": 2
+  },
+  {
+    "type": "added",
+    "document": 0,
+    "path": "c",
+    "to": {
+      "d": null
+    }
+  }
+]
+`, output)
+	output, err = JSON(nil)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "[]\n", output)
+}
+
+func TestPatch(t *testing.T) {
+	changes, err := Compare("a/b: 1\nl: [1, 2, 3]\nm: {x: 1}\n", "a/b: 2\nl: [1]\nm: {x: null, \"~y\": [1]}\n")
+	itesting.AssertEqual(t, nil, err)
+	output, err := Patch(changes)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, `[
+  {
+    "op": "replace",
+    "path": "/a~1b",
+    "value": 2
+  },
+  {
+    "op": "remove",
+    "path": "/l/2"
+  },
+  {
+    "op": "remove",
+    "path": "/l/1"
+  },
+  {
+    "op": "replace",
+    "path": "/m/x",
+    "value": null
+  },
+  {
+    "op": "add",
+    "path": "/m/~0y",
+    "value": [
+      1
+    ]
+  }
+]
+`, output)
+}
+
+func TestCompareFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := itesting.WriteFile(t, dir, "base.yml", "db:\n  host: localhost\n  port: 5432\n")
+	dev := itesting.WriteFile(t, dir, "dev.yml", "db:\n  host: dev.local\n")
+	prod := itesting.WriteFile(t, dir, "prod.yml", "db:\n  host: prod.local\n  port: 5433\n")
+	changes, err := CompareFiles([]string{base, dev}, []string{base, prod})
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "~ db.host: dev.local -> prod.local\n~ db.port: 5432 -> 5433\n", Text(changes, false))
+	changes, err = CompareFiles([]string{base}, []string{base})
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, 0, len(changes))
+	_, err = CompareFiles(nil, []string{base})
+	itesting.AssertError(t, "at least one file", err)
+}
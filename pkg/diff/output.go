@@ -0,0 +1,181 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// ANSI escape codes of the colored text
+const (
+	green  = "\x1b[32m"
+	red    = "\x1b[31m"
+	yellow = "\x1b[33m"
+	bold   = "\x1b[1m"
+	reset  = "\x1b[0m"
+)
+
+// Text returns the changes as lines "+ path: value" (added), "- path: value"
+// (removed) and "~ path: from -> to" (changed), with the values in yaml flow
+// style and the root path written as ".". If there are changes in any
+// document but the first one, the changes of each document follow a
+// "--- document N" line. The lines are colored with ANSI escape codes if color
+// is true.
+func Text(changes []Change, color bool) string {
+	paint := func(code string, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + reset
+	}
+	headers := false
+	for _, c := range changes {
+		headers = headers || c.Document > 0
+	}
+	var sb strings.Builder
+	document := -1
+	for _, c := range changes {
+		if headers && c.Document != document {
+			document = c.Document
+			sb.WriteString(paint(bold, fmt.Sprintf("--- document %d", document)) + "\n")
+		}
+		p := c.Path
+		if p == "" {
+			p = "."
+		}
+		switch c.Type {
+		case Added:
+			sb.WriteString(paint(green, fmt.Sprintf("+ %s: %s", p, yaml.FlowString(c.To))))
+		case Removed:
+			sb.WriteString(paint(red, fmt.Sprintf("- %s: %s", p, yaml.FlowString(c.From))))
+		case Changed:
+			sb.WriteString(paint(yellow, fmt.Sprintf("~ %s: %s -> %s", p, yaml.FlowString(c.From), yaml.FlowString(c.To))))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// jsonChange is a change written as json.
+type jsonChange struct {
+	Type     ChangeType      `json:"type"`
+	Document int             `json:"document"`
+	Path     string          `json:"path"`
+	From     json.RawMessage `json:"from,omitempty"`
+	To       json.RawMessage `json:"to,omitempty"`
+}
+
+// JSON returns the changes as a json array of objects with the type, the
+// document, the path and the values from and to (if any) of each change.
+func JSON(changes []Change) (string, error) {
+	values := make([]jsonChange, len(changes))
+	for i, c := range changes {
+		values[i] = jsonChange{Type: c.Type, Document: c.Document, Path: c.Path}
+		var err error
+		if values[i].From, err = rawJSON(c.From); err != nil {
+			return "", err
+		}
+		if values[i].To, err = rawJSON(c.To); err != nil {
+			return "", err
+		}
+	}
+	return marshalIndent(values)
+}
+
+// patchOperation is an operation of a json patch.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+var patchOps = map[ChangeType]string{
+	Added:   "add",
+	Removed: "remove",
+	Changed: "replace",
+}
+
+// Patch returns the changes as a RFC 6902 json patch (add, remove and replace
+// operations) that transforms the first content into the second one. A json
+// patch only applies to a single document, so there can only be changes in
+// the first document.
+func Patch(changes []Change) (string, error) {
+	ops := make([]patchOperation, len(changes))
+	for i, c := range changes {
+		if c.Document > 0 {
+			return "", errors.New("a json patch can only contain changes of the first document")
+		}
+		ops[i] = patchOperation{Op: patchOps[c.Type], Path: pointer(c)}
+		var err error
+		if ops[i].Value, err = rawJSON(c.To); err != nil {
+			return "", err
+		}
+	}
+	return marshalIndent(ops)
+}
+
+// pointer returns the path of a change as a RFC 6901 json pointer.
+func pointer(c Change) string {
+	var sb strings.Builder
+	for _, s := range c.path {
+		sb.WriteByte('/')
+		if s.IsIndex() {
+			sb.WriteString(strconv.Itoa(s.Index))
+		} else {
+			sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(s.Key))
+		}
+	}
+	return sb.String()
+}
+
+// rawJSON returns a value as json, nil if there is no value.
+func rawJSON(n *yaml.Node) (json.RawMessage, error) {
+	if n == nil {
+		return nil, nil
+	}
+	value, err := yaml.CompactJSON(n)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(value), nil
+}
+
+// marshalIndent returns a value as json indented with two spaces, ending in a
+// new line.
+func marshalIndent(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
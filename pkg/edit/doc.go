@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package edit sets and deletes values in a path of yaml contents changing
+// only the text of the edited value: comments, key order, quotes, indentation
+// and the rest of the format of the content are kept.
+//
+// Paths have the syntax of the path package, without wildcards. Setting a
+// value in a path adds the missing keys to their mappings (replacing null
+// values with new mappings) and an index one past the end of a list appends
+// the value. A scalar is replaced in its line, keeping its quotes if it is
+// still a string, and any other value is written in block style (or in flow
+// style inside a flow collection) with the indentation of the content.
+//
+// The edited content is parsed again to check that only the edited value has
+// changed, an error is returned if the value cannot be edited keeping the
+// format.
+package edit
@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package edit
+
+import (
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// ValueType is how the value set in a path is read.
+type ValueType string
+
+const (
+	// String is a string value.
+	String ValueType = "string"
+	// Int is an integer, in any notation of a yaml int.
+	Int ValueType = "int"
+	// Bool is a boolean, in any notation of a yaml bool.
+	Bool ValueType = "bool"
+	// YAML is any yaml value (a scalar, a mapping or a list).
+	YAML ValueType = "yaml"
+)
+
+// ValueTypes are the valid value types.
+var ValueTypes = []ValueType{String, Int, Bool, YAML}
+
+var valueTypes = map[ValueType]yaml.ValueType{
+	String: yaml.StringValue,
+	Int:    yaml.IntValue,
+	Bool:   yaml.BoolValue,
+	YAML:   yaml.YAMLValue,
+}
+
+// Validate returns an error if the value type is unknown.
+func (t ValueType) Validate() error {
+	if _, ok := valueTypes[t]; !ok && t != "" {
+		return fmt.Errorf("unknown value type %s, valid types are %v", t, ValueTypes)
+	}
+	return nil
+}
+
+// ErrPathNotFound is the error of deleting a path that does not exist.
+var ErrPathNotFound = yaml.ErrPathNotFound
+
+// Option configures an edit.
+type Option func(o *options)
+
+type options struct {
+	document  int
+	valueType ValueType
+}
+
+// WithDocument selects the document of a multi-document content that is edited
+// (0-based, the first one by default).
+func WithDocument(index int) Option {
+	return func(o *options) {
+		o.document = index
+	}
+}
+
+// WithValueType configures how the value set is read (String by default).
+func WithValueType(t ValueType) Option {
+	return func(o *options) {
+		o.valueType = t
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Set returns a yaml content with a value set in a path expression, changing
+// only the text of the value.
+func Set(content string, expr string, value string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	if err := o.valueType.Validate(); err != nil {
+		return "", err
+	}
+	p, err := path.Parse(expr)
+	if err != nil {
+		return "", err
+	}
+	t := valueTypes[String]
+	if o.valueType != "" {
+		t = valueTypes[o.valueType]
+	}
+	v, err := yaml.ParseValue(value, t)
+	if err != nil {
+		return "", err
+	}
+	return yaml.EditValue(content, o.document, p, v)
+}
+
+// Delete returns a yaml content without the value in a path expression (and
+// the comments right above it), changing only the text of the value. An error
+// wrapping ErrPathNotFound is returned if there is no value in the path.
+func Delete(content string, expr string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	p, err := path.Parse(expr)
+	if err != nil {
+		return "", err
+	}
+	return yaml.DeleteValue(content, o.document, p)
+}
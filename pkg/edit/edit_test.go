@@ -0,0 +1,260 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package edit
+
+import (
+	"errors"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+const content = `# Service config
+name: web  # the name
+version: "1.0"
+replicas: 2
+
+server:
+    host: localhost
+    # The port
+    port: 8080
+    tags: [a, b]
+
+items:
+  - first
+  - name: second
+    value: 2
+empty:
+labels: {app: web, tier: front}
+`
+
+func TestSet(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		path      string
+		value     string
+		valueType ValueType
+		expected  string
+	}{
+		{
+			name: "scalar keeps comment", path: "name", value: "api",
+			expected: itesting.Replace(t, content, "name: web  # the name", "name: api  # the name"),
+		},
+		{
+			name: "string keeps quotes", path: "version", value: "2.0",
+			expected: itesting.Replace(t, content, `version: "1.0"`, `version: "2.0"`),
+		},
+		{
+			name: "int", path: "server.port", value: "9090", valueType: Int,
+			expected: itesting.Replace(t, content, "port: 8080", "port: 9090"),
+		},
+		{
+			name: "string that looks like an int", path: "replicas", value: "3",
+			expected: itesting.Replace(t, content, "replicas: 2", `replicas: "3"`),
+		},
+		{
+			name: "bool", path: "items[0]", value: "true", valueType: Bool,
+			expected: itesting.Replace(t, content, "- first", "- true"),
+		},
+		{
+			name: "in flow sequence", path: "server.tags[1]", value: "c,d",
+			expected: itesting.Replace(t, content, "tags: [a, b]", `tags: [a, "c,d"]`),
+		},
+		{
+			name: "append to flow sequence", path: "server.tags[2]", value: "c",
+			expected: itesting.Replace(t, content, "tags: [a, b]", "tags: [a, b, c]"),
+		},
+		{
+			name: "new key in flow mapping", path: "labels.env", value: "prod",
+			expected: itesting.Replace(t, content, "labels: {app: web, tier: front}", "labels: {app: web, tier: front, env: prod}"),
+		},
+		{
+			name: "new key", path: "server.timeout", value: "30s",
+			expected: itesting.Replace(t, content, "    tags: [a, b]\n", "    tags: [a, b]\n    timeout: 30s\n"),
+		},
+		{
+			name: "new intermediate maps", path: "server.tls.cert.file", value: "cert.pem",
+			expected: itesting.Replace(t, content, "    tags: [a, b]\n", "    tags: [a, b]\n    tls:\n        cert:\n            file: cert.pem\n"),
+		},
+		{
+			name: "new root key", path: "owner", value: "me",
+			expected: content + "owner: me\n",
+		},
+		{
+			name: "null value", path: "empty.key", value: "v",
+			expected: itesting.Replace(t, content, "empty:\n", "empty:\n    key: v\n"),
+		},
+		{
+			name: "append item", path: "items[2]", value: "third",
+			expected: itesting.Replace(t, content, "    value: 2\n", "    value: 2\n  - third\n"),
+		},
+		{
+			name: "yaml mapping", path: "items[1]", value: "{name: other}", valueType: YAML,
+			expected: itesting.Replace(t, content, "  - name: second\n    value: 2\n", "  - name: other\n"),
+		},
+		{
+			name: "yaml block", path: "server", value: "a: 1\nb: [x]\n", valueType: YAML,
+			expected: itesting.Replace(t, content, "server:\n    host: localhost\n    # The port\n    port: 8080\n    tags: [a, b]\n", "server:\n    a: 1\n    b:\n        - x\n"),
+		},
+		{
+			name: "scalar replaced by list", path: "name", value: "[a, b]", valueType: YAML,
+			expected: itesting.Replace(t, content, "name: web  # the name", "name:  # the name\n    - a\n    - b"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			edited, err := Set(content, test.path, test.value, WithValueType(test.valueType))
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, edited)
+		})
+	}
+}
+
+func TestSetEmptyContent(t *testing.T) {
+	edited, err := Set("", "a.b", "c")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a:\n  b: c\n", edited)
+}
+
+func TestSetDocument(t *testing.T) {
+	edited, err := Set("a: 1\n---\n# second\na: 1\n", "a", "2", WithDocument(1), WithValueType(Int))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 1\n---\n# second\na: 2\n", edited)
+}
+
+func TestSetCRLF(t *testing.T) {
+	crlf := "a: 1\r\nb:\r\n  c: 2 # c\r\n  l:\r\n    - x\r\n"
+	for _, test := range []struct {
+		path      string
+		value     string
+		valueType ValueType
+		expected  string
+	}{
+		{path: "b.c", value: "3", valueType: Int, expected: "a: 1\r\nb:\r\n  c: 3 # c\r\n  l:\r\n    - x\r\n"},
+		{path: "b.d", value: "{x: 1}", valueType: YAML, expected: crlf + "  d:\r\n    x: 1\r\n"},
+		{path: "b.l[1]", value: "z", expected: crlf + "    - z\r\n"},
+		{path: "b", value: "none", expected: "a: 1\r\nb: none\r\n"},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			edited, err := Set(crlf, test.path, test.value, WithValueType(test.valueType))
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, edited)
+		})
+	}
+	edited, err := Delete(crlf, "b.c")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 1\r\nb:\r\n  l:\r\n    - x\r\n", edited)
+}
+
+func TestSetFlowKeepsEntries(t *testing.T) {
+	flow := "m: {x: 1, y: 2}  # m\nl: [x, \"on\", y,]\ne: []\nml: [\n  a,\n  b  # b\n]\n"
+	for _, test := range []struct {
+		path      string
+		value     string
+		valueType ValueType
+		expected  string
+	}{
+		{path: "m.z", value: "new", expected: itesting.Replace(t, flow, "{x: 1, y: 2}", "{x: 1, y: 2, z: new}")},
+		{path: "m.o", value: "{k: [v]}", valueType: YAML, expected: itesting.Replace(t, flow, "{x: 1, y: 2}", "{x: 1, y: 2, o: {k: [v]}}")},
+		{path: "l[3]", value: "a,b", expected: itesting.Replace(t, flow, `[x, "on", y,]`, `[x, "on", y, 'a,b']`)},
+		{path: "e[0]", value: "n", expected: itesting.Replace(t, flow, "e: []", `e: ["n"]`)},
+		{path: "ml[2]", value: "c", expected: itesting.Replace(t, flow, "  b  # b", "  b, c  # b")},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			edited, err := Set(flow, test.path, test.value, WithValueType(test.valueType))
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, edited)
+		})
+	}
+	for path, expected := range map[string]string{
+		"m.x":  itesting.Replace(t, flow, "{x: 1, y: 2}", "{y: 2}"),
+		"m.y":  itesting.Replace(t, flow, "{x: 1, y: 2}", "{x: 1}"),
+		"l[1]": itesting.Replace(t, flow, `[x, "on", y,]`, "[x, y,]"),
+		"l[2]": itesting.Replace(t, flow, `[x, "on", y,]`, `[x, "on"]`),
+	} {
+		t.Run("delete "+path, func(t *testing.T) {
+			edited, err := Delete(flow, path)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, expected, edited)
+		})
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	for _, test := range []struct {
+		path      string
+		value     string
+		valueType ValueType
+		opts      []Option
+		expected  string
+	}{
+		{path: "name", value: "x", valueType: "float", expected: "unknown value type float"},
+		{path: "name", value: "x", valueType: Int, expected: `invalid int value "x"`},
+		{path: "name", value: "1", valueType: Bool, expected: `invalid bool value "1"`},
+		{path: "items[*]", value: "x", expected: "must not contain wildcards"},
+		{path: "name.key", value: "x", expected: "path name is not a mapping"},
+		{path: "items[5]", value: "x", expected: "index 5 out of range in path items with 2 items"},
+		{path: "server[0]", value: "x", expected: "path server is not a list"},
+		{path: "name", value: "x", opts: []Option{WithDocument(1)}, expected: "document 1 not found, the content has 1 documents"},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			_, err := Set(content, test.path, test.value, append(test.opts, WithValueType(test.valueType))...)
+			itesting.AssertError(t, test.expected, err)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	for _, test := range []struct {
+		path     string
+		expected string
+	}{
+		{path: "replicas", expected: itesting.Replace(t, content, "replicas: 2\n", "")},
+		{path: "server.port", expected: itesting.Replace(t, content, "    # The port\n    port: 8080\n", "")},
+		{path: "server", expected: itesting.Replace(t, content, "server:\n    host: localhost\n    # The port\n    port: 8080\n    tags: [a, b]\n\n", "")},
+		{path: "items[1]", expected: itesting.Replace(t, content, "  - name: second\n    value: 2\n", "")},
+		{path: "items[1].name", expected: itesting.Replace(t, content, "  - name: second\n    value: 2\n", "  - value: 2\n")},
+		{path: "server.tags[0]", expected: itesting.Replace(t, content, "tags: [a, b]", "tags: [b]")},
+		{path: "labels.app", expected: itesting.Replace(t, content, "{app: web, tier: front}", "{tier: front}")},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			edited, err := Delete(content, test.path)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, edited)
+		})
+	}
+}
+
+func TestDeleteLast(t *testing.T) {
+	edited, err := Delete("a:\n  b: 1\nc: [1]\n", "a.b")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: {}\nc: [1]\n", edited)
+	edited, err = Delete(edited, "c[0]")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: {}\nc: []\n", edited)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	_, err := Delete(content, "server.missing")
+	itesting.AssertError(t, "path not found: server.missing", err)
+	itesting.AssertTrue(t, errors.Is(err, ErrPathNotFound))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package format
+
+import (
+	"errors"
+
+	"github.com/amplia-iiot/yutil/internal/diff"
+	"github.com/amplia-iiot/yutil/internal/io"
+)
+
+// StdinName is the name used for stdin in results and diffs.
+const StdinName = "<stdin>"
+
+// FileResult is the result of checking whether a yaml file is formatted.
+type FileResult struct {
+	File      string // Checked file
+	Formatted bool   // Whether the file is already formatted
+	Diff      string // Unified diff needed to format the file, empty if formatted
+	Err       error  // Error reading or formatting the file
+}
+
+// CheckContent returns the unified diff between a yaml content and its
+// formatted content, named after the given file. The diff is empty if the
+// content is already formatted. The content of markdown files (see IsMarkdown)
+// is formatted with FormatMarkdown.
+func CheckContent(file string, content string, opts ...Option) (string, error) {
+	formatted, err := formatAny(file, content, opts)
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified("a/"+file, "b/"+file, content, formatted), nil
+}
+
+// CheckStdin checks whether stdin yaml content is formatted.
+func CheckStdin(opts ...Option) FileResult {
+	content, err := io.ReadStdin()
+	if err != nil {
+		return FileResult{File: StdinName, Err: err}
+	}
+	return checkContent(StdinName, content, opts)
+}
+
+// CheckFile checks whether a yaml file is formatted.
+func CheckFile(file string, opts ...Option) FileResult {
+	content, err := io.ReadAsString(file)
+	if err != nil {
+		return FileResult{File: file, Err: err}
+	}
+	return checkContent(file, content, opts)
+}
+
+// CheckFiles checks whether a list of yaml files are formatted, returning a
+// result for each file. Every file is checked regardless of previous errors.
+// The final error joins a FileError for each errored file.
+func CheckFiles(files []string, opts ...Option) ([]FileResult, error) {
+	results := make([]FileResult, len(files))
+	var errs []error
+	for i, file := range files {
+		results[i] = CheckFile(file, opts...)
+		if results[i].Err != nil {
+			errs = append(errs, &FileError{File: file, Err: results[i].Err})
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func checkContent(file string, content string, opts []Option) FileResult {
+	d, err := CheckContent(file, content, opts...)
+	return FileResult{File: file, Formatted: err == nil && d == "", Diff: d, Err: err}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package format
+
+import (
+	"strings"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestCheckContent(t *testing.T) {
+	for _, i := range []struct {
+		content  string
+		expected string
+	}{
+		// Formatted
+		{
+			content:  "data:\n  one: 1\n  two: 2\n",
+			expected: "",
+		},
+		// Not formatted
+		{
+			content: "data: {two: 2, one: 1}\n",
+			expected: `--- a/file.yml
++++ b/file.yml
+@@ -1 +1,3 @@
+-data: {two: 2, one: 1}
++data:
++  one: 1
++  two: 2
+`,
+		},
+	} {
+		diff, err := CheckContent("file.yml", i.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, diff)
+	}
+}
+
+func TestCheckContentInvalid(t *testing.T) {
+	diff, err := CheckContent("file.yml", "data: {")
+	itesting.AssertError(t, "did not find expected node content", err)
+	itesting.AssertEqual(t, "", diff)
+}
+
+func TestCheckFiles(t *testing.T) {
+	data := []struct {
+		file      string
+		formatted bool
+		expected  string
+	}{
+		{file: expectedFile("base"), formatted: true},
+		{file: fileToBeFormatted("base"), formatted: false},
+		{file: expectedFile("comments"), formatted: true},
+		{file: fileToBeFormatted("invalid"), expected: "mapping values are not allowed"},
+		{file: fileToBeFormatted("not-exists"), expected: "no such file or directory"},
+	}
+	var files []string
+	for _, d := range data {
+		files = append(files, d.file)
+	}
+	results, err := CheckFiles(files)
+	itesting.AssertError(t, fileToBeFormatted("invalid"), err)
+	itesting.AssertError(t, fileToBeFormatted("not-exists"), err)
+	itesting.AssertEqual(t, len(data), len(results))
+	for i, d := range data {
+		r := results[i]
+		itesting.AssertEqual(t, d.file, r.File)
+		itesting.AssertError(t, d.expected, r.Err)
+		itesting.AssertEqual(t, d.formatted, r.Formatted)
+		itesting.AssertEqual(t, d.formatted || d.expected != "", r.Diff == "")
+	}
+}
+
+func TestCheckStdin(t *testing.T) {
+	itesting.SimulateStdinContent(t, "data: {b: b, a: a}", func() {
+		r := CheckStdin()
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		itesting.AssertEqual(t, StdinName, r.File)
+		itesting.AssertFalse(t, r.Formatted)
+		itesting.AssertTrue(t, strings.Contains(r.Diff, "+++ b/<stdin>"))
+	})
+}
@@ -27,20 +27,53 @@ import (
 	"github.com/amplia-iiot/yutil/internal/yaml"
 )
 
-// FormatContent formats a yaml content.
-func FormatContent(content string) (string, error) {
-	contentData, err := yaml.Parse(content)
+// FormatContent formats a yaml content. Each document of a multi-document
+// stream is formatted and separated with "---". Json and toml contents are
+// detected (see DetectFormat) and formatted as yaml unless another output
+// format is configured.
+func FormatContent(content string, opts ...Option) (string, error) {
+	docs, err := ParseDocuments(content, DetectFormat("", content))
 	if err != nil {
 		return "", err
 	}
-	return yaml.Compose(contentData)
+	return FormatDocuments(docs, opts...)
+}
+
+// FormatNode formats a yaml document node, which is modified.
+func FormatNode(doc *yaml.Node, opts ...Option) (string, error) {
+	return FormatDocuments([]*yaml.Node{doc}, opts...)
+}
+
+// FormatDocuments formats a stream of yaml document nodes, which are modified,
+// separating them with "---".
+func FormatDocuments(docs []*yaml.Node, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	if err := o.Validate(); err != nil {
+		return "", err
+	}
+	order, err := o.KeyOrder.keyOrder()
+	if err != nil {
+		return "", err
+	}
+	anchors := o.Anchors
+	if o.OutputFormat != "" && o.OutputFormat != YAML {
+		anchors = ExpandAnchors
+	}
+	for _, doc := range docs {
+		if err = anchors.normalize(doc); err != nil {
+			return "", err
+		}
+		yaml.OrderKeys(doc, order)
+		anchors.arrange(doc)
+	}
+	return o.composeDocuments(docs)
 }
 
 // FormatStdin formats stdin as yaml content.
-func FormatStdin() (string, error) {
+func FormatStdin(opts ...Option) (string, error) {
 	content, err := io.ReadStdin()
 	if err != nil {
 		return "", err
 	}
-	return FormatContent(content)
+	return FormatContent(content, opts...)
 }
@@ -72,10 +72,55 @@ func TestFormatContent(t *testing.T) {
 			expected: `data: null
 `,
 		},
-		// Comments are lost
+		// Comments are kept
 		{
 			content: `with-comment: 42 # The meaning of life`,
-			expected: `with-comment: 42
+			expected: `with-comment: 42 # The meaning of life
+`,
+		},
+		{
+			content: `# Numbers
+b: 2
+# Letters
+a: a`,
+			expected: `# Letters
+a: a
+# Numbers
+b: 2
+`,
+		},
+		{
+			content: `data: # Data
+  # The second one
+  two: 2 # Two
+  one: 1 # One`,
+			expected: `data: # Data
+  one: 1 # One
+  # The second one
+  two: 2 # Two
+`,
+		},
+		{
+			content: `data:
+  - one # One
+  # The second one
+  - two`,
+			expected: `data:
+- one # One
+# The second one
+- two
+`,
+		},
+		// Blank lines between root keys are kept
+		{
+			content: `b: 2
+
+a: 1
+c: 3`,
+			expected: `a: 1
+
+b: 2
+c: 3
 `,
 		},
 		// Numbers
@@ -279,6 +324,35 @@ func TestFormatContent(t *testing.T) {
 		{
 			content: `data: !!str false`,
 			expected: `data: "false"
+`,
+		},
+		// Also strings that yaml 1.1 parsers read as booleans or numbers
+		{
+			content: `data: "on"`,
+			expected: `data: "on"
+`,
+		},
+		{
+			content: `data: 'yes'`,
+			expected: `data: "yes"
+`,
+		},
+		{
+			content: `data: !!str n`,
+			expected: `data: "n"
+`,
+		},
+		{
+			content: `data: [Off, "y", 1:20]`,
+			expected: `data:
+- "Off"
+- "y"
+- "1:20"
+`,
+		},
+		{
+			content: `"no": value`,
+			expected: `"no": value
 `,
 		},
 		// Arrays are not reordered
@@ -288,6 +362,65 @@ func TestFormatContent(t *testing.T) {
 - b
 - c
 - a
+`,
+		},
+		// Each document of a stream is formatted
+		{
+			content: "---\ndata: {b: b, a: a}\n---\ndata: [b, a]\n",
+			expected: `data:
+  a: a
+  b: b
+---
+data:
+- b
+- a
+`,
+		},
+		// Empty documents are removed, null documents are empty
+		{
+			content: "data: 1\n---\n---\nnull\n---\n",
+			expected: `data: 1
+---
+{}
+`,
+		},
+		// Any node can be the root
+		{
+			content: "[b, {z: 1, a: 2}]",
+			expected: `- b
+- a: 2
+  z: 1
+`,
+		},
+		{
+			content:  `'hello'`,
+			expected: "hello\n",
+		},
+		// Keys of any type are kept, ordered by type and value
+		{
+			content: "{b: 1, 10: 2, 2: 3, true: 4, null: 5, '2': 6, [2, 1]: 7, [1, 2]: 8, {a: 1}: 9}",
+			expected: `true: 4
+2: 3
+10: 2
+null: 5
+? {a: 1}
+: 9
+? [1, 2]
+: 8
+? [2, 1]
+: 7
+"2": 6
+b: 1
+`,
+		},
+		// Documents with only comments are kept
+		{
+			content: "data: 1\n---\n# Nothing here\n---\ndata: 2\n",
+			expected: `data: 1
+---
+# Nothing here
+---
+data: 2
 `,
 		},
 	} {
@@ -305,8 +438,8 @@ func TestFormatContentInvalid(t *testing.T) {
 		expected string
 	}{
 		{
-			content:  `;`,
-			expected: "cannot unmarshal",
+			content:  `key: value: other`,
+			expected: "mapping values are not allowed",
 		},
 		{
 			content:  `data: {`,
@@ -379,8 +512,8 @@ func TestFormatstdinInvalid(t *testing.T) {
 	}{
 		// Parsing error
 		{
-			stdin:    `;`,
-			expected: "cannot unmarshal",
+			stdin:    `key: value: other`,
+			expected: "mapping values are not allowed",
 		},
 		{
 			stdin:    `data: {`,
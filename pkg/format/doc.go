@@ -22,19 +22,56 @@ SOFTWARE.
 
 // Package format provides primitives for formatting yaml files and content.
 //
-// Formatting a yaml includes sorting its nodes alphabetically and cleaning
-// the format of the values.
+// Formatting a yaml includes sorting its nodes and cleaning the format of the
+// values.
+//
+// Keys are sorted alphabetically by default. A KeyOrder policy (see
+// WithKeyOrder) may keep the keys in source order, put a list of priority keys
+// first and override the policy for the mappings in specific paths.
 //
 // Strings that do not need quotes to remain a primitive string lose the quotes.
 // When quotes are needed, single quotes are preferred for strings with special
 // characters. For strings containing a number, boolean or null values, double
-// quotes are used. Unicode escape sequences in a string are replaced with the
-// character.
+// quotes are used, also for the ones read as booleans or numbers by yaml 1.1
+// parsers (like on, yes or n) and for <<, which would be read as a merge key.
+// Unicode escape sequences in a string are replaced with the character.
 //
 // The proper formatting for null is null, not Null. The same happens to boolean
 // values, lowercase is used when formatting.
 //
 // Arrays maintain the order of elements, and each element appears on a new line.
 //
-// Comments are removed.
+// The root of a document can be any node and keys can be of any type. Keys are
+// sorted by type: booleans and numbers by value, then nulls, mappings,
+// sequences and strings. Mappings and sequences used as keys are compared and
+// written in flow style.
+//
+// Comments are kept attached to the node they describe: head comments (the
+// lines above a key or element), line comments (at the end of its line) and
+// foot comments (the lines below it, followed by a blank line) move with their
+// node when keys are sorted. Root keys separated by blank lines remain
+// separated after sorting.
+//
+// The output style (indentation, sequence indentation, line width, quotes and
+// escaping of non ASCII characters) is configured with Options.
+//
+// Each document of a multi-document stream is formatted, separated by "---".
+// Empty documents are removed.
+//
+// Json and toml contents are read as yaml (see DetectFormat and
+// ParseDocuments), json keeping the order of the keys. The formatted yaml can
+// be written as json or toml (see WithOutputFormat), losing the comments. Files
+// keep their format when formatted.
+//
+// Only the yaml regions of markdown files are formatted (see FormatMarkdown):
+// the front matter and the fenced code blocks with yaml info string.
+//
+// ListFiles expands directories into the yaml files inside them, so they can
+// be formatted in place or checked. Several files are formatted in place in
+// parallel (see WithWorkers), returning an InPlaceResult for each file and a
+// FileError for each errored file.
+//
+// Anchors, aliases and merge keys are expanded by default. They can be
+// preserved as written, or repeated nodes can be replaced with aliases (see
+// WithAnchors).
 package format
@@ -23,73 +23,160 @@ SOFTWARE.
 package format
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/amplia-iiot/yutil/internal/io"
 )
 
-// FormatFile returns the content of a yaml file formatted.
-func FormatFile(file string) (string, error) {
+// DefaultInclude are the glob patterns of the files formatted inside
+// directories when no include pattern is given.
+var DefaultInclude = []string{"*.yml", "*.yaml"}
+
+// ListFiles returns the files to be formatted from a list of files and
+// directories. Files are returned as given, even if they do not exist, and
+// directories are walked recursively returning the files that match any include
+// glob pattern (DefaultInclude if empty) and no exclude glob pattern. Patterns
+// are matched against both the file name and its path. Each file is returned
+// once.
+func ListFiles(paths []string, include []string, exclude []string) ([]string, error) {
+	if len(include) == 0 {
+		include = DefaultInclude
+	}
+	var files []string
+	listed := map[string]bool{}
+	for _, p := range paths {
+		found := []string{p}
+		if info, err := os.Stat(p); err == nil && info.IsDir() {
+			if found, err = io.ListFiles(p, include, exclude); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range found {
+			if !listed[filepath.Clean(file)] {
+				listed[filepath.Clean(file)] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
+
+// FormatFile returns the content of a yaml file formatted. Only the yaml
+// regions of markdown files (see IsMarkdown) are formatted, with
+// FormatMarkdown.
+func FormatFile(file string, opts ...Option) (string, error) {
 	content, err := io.ReadAsString(file)
 	if err != nil {
 		return "", err
 	}
-	return FormatContent(content)
+	return formatAny(file, content, opts)
 }
 
-// FormatInPlace formats a yaml file, modifying the original file.
-func FormatFileInPlace(file string) error {
-	formatted, err := FormatFile(file)
-	if err != nil {
-		return err
-	}
-	return io.WriteToFile(file, formatted)
+// FormatInPlace formats a yaml file, modifying the original file. The file is
+// not written if it is already formatted.
+func FormatFileInPlace(file string, opts ...Option) error {
+	_, err := formatFileInPlace(file, "", opts)
+	return err
 }
 
 // FormatInPlace formats a yaml file, creating a backup file with a suffix
 // before modifying the original file.
-func FormatFileInPlaceB(file, backupSuffix string) error {
-	if backupSuffix != "" {
-		err := io.Copy(file, file+backupSuffix)
-		if err != nil {
-			return err
-		}
-	}
-	return FormatFileInPlace(file)
+func FormatFileInPlaceB(file, backupSuffix string, opts ...Option) error {
+	_, err := formatFileInPlace(file, backupSuffix, opts)
+	return err
+}
+
+// InPlaceResult is the result of formatting a yaml file in place.
+type InPlaceResult struct {
+	File    string // Formatted file
+	Changed bool   // Whether the file has been modified (false if it was already formatted)
+	Err     error  // Error reading, formatting or writing the file
+}
+
+// FileError is the error of a file, returned for each errored file when
+// formatting several files. Use errors.As to inspect it.
+type FileError struct {
+	File string // Errored file
+	Err  error  // Cause
 }
 
-// FormatFilesInPlace formats a list of yaml files, modifying the original
-// files. An attempt to format each file will be made regardless of previous
-// errors. The final error message will specify all errored files.
-func FormatFilesInPlace(files []string) error {
-	var errors []string
-	for _, file := range files {
-		err := FormatFileInPlace(file)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s - %s", file, err.Error()))
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s - %s", e.File, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FormatFilesInPlace formats a list of yaml files in parallel (see
+// WithWorkers), modifying the original files, returning a result for each file
+// in the same order. An attempt to format each file will be made regardless of
+// previous errors. The final error joins a FileError for each errored file.
+func FormatFilesInPlace(files []string, opts ...Option) ([]InPlaceResult, error) {
+	return formatFilesInPlace(files, "", opts)
+}
+
+// FormatFilesInPlaceB formats a list of yaml files in parallel (see
+// WithWorkers), creating a backup for each file with a suffix before modifying
+// each file, returning a result for each file in the same order. An attempt to
+// format each file will be made regardless of previous errors. The final error
+// joins a FileError for each errored file.
+func FormatFilesInPlaceB(files []string, backupSuffix string, opts ...Option) ([]InPlaceResult, error) {
+	return formatFilesInPlace(files, backupSuffix, opts)
+}
+
+// formatFileInPlace formats a file in place, creating a backup first if there
+// is a suffix, returning whether the file has been modified.
+func formatFileInPlace(file, backupSuffix string, opts []Option) (bool, error) {
+	if backupSuffix != "" {
+		if err := io.Copy(file, file+backupSuffix); err != nil {
+			return false, err
 		}
 	}
-	if len(errors) > 0 {
-		return fmt.Errorf(strings.Join(errors, "\n"))
+	content, err := io.ReadAsString(file)
+	if err != nil {
+		return false, err
+	}
+	formatted, err := formatAny(file, content, opts)
+	if err != nil || formatted == content {
+		return false, err
 	}
-	return nil
+	return true, io.WriteToFile(file, formatted)
 }
 
-// FormatFilesInPlaceB formats a list of yaml files, creating a backup for each
-// file with a suffix before modifying each file. An attempt to format each file
-// will be made regardless of previous errors. The final error message will
-// specify all errored files.
-func FormatFilesInPlaceB(files []string, backupSuffix string) error {
-	var errors []string
-	for _, file := range files {
-		err := FormatFileInPlaceB(file, backupSuffix)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s - %s", file, err.Error()))
-		}
+// formatFilesInPlace formats files in place with a bounded pool of workers.
+func formatFilesInPlace(files []string, backupSuffix string, opts []Option) ([]InPlaceResult, error) {
+	o := newOptions(opts)
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	results := make([]InPlaceResult, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers(len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				changed, err := formatFileInPlace(files[i], backupSuffix, opts)
+				results[i] = InPlaceResult{File: files[i], Changed: changed, Err: err}
+			}
+		}()
 	}
-	if len(errors) > 0 {
-		return fmt.Errorf(strings.Join(errors, "\n"))
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, &FileError{File: r.File, Err: r.Err})
+		}
 	}
-	return nil
+	return results, errors.Join(errs...)
 }
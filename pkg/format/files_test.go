@@ -23,6 +23,7 @@ SOFTWARE.
 package format
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -53,9 +54,11 @@ func init() {
 func TestFormatFile(t *testing.T) {
 	for _, file := range []string{
 		"base",
+		"comments",
 		"dev",
 		"docker",
 		"prod",
+		"stream",
 	} {
 		formatted, err := FormatFile(fileToBeFormatted(file))
 		if err != nil {
@@ -71,7 +74,7 @@ func TestFormatFileInvalid(t *testing.T) {
 		// Parsing error
 		{
 			file:     "invalid",
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -119,7 +122,7 @@ func TestFormatFileInPlaceInvalid(t *testing.T) {
 		// Parsing error
 		{
 			file:     "invalid",
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -188,7 +191,7 @@ func TestFormatFileInPlaceBInvalid(t *testing.T) {
 		// Parsing error
 		{
 			file:              "invalid",
-			expected:          "cannot unmarshal",
+			expected:          "mapping values are not allowed",
 			shouldBackupExist: true,
 		},
 		// Not exists
@@ -254,7 +257,7 @@ func TestFormatFilesInPlace(t *testing.T) {
 		defer os.Remove(tmpFile)
 		tmpFiles = append(tmpFiles, tmpFile)
 	}
-	err = FormatFilesInPlace(tmpFiles)
+	_, err = FormatFilesInPlace(tmpFiles)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -281,7 +284,7 @@ func TestFormatFilesInPlaceInvalid(t *testing.T) {
 		{
 			file:     "invalid",
 			invalid:  true,
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -310,11 +313,16 @@ func TestFormatFilesInPlaceInvalid(t *testing.T) {
 			d.copiedToTmp = true
 		}
 	}
-	err := FormatFilesInPlace(data.tmpPaths())
-	for _, d := range data {
+	results, err := FormatFilesInPlace(data.tmpPaths())
+	for i, d := range data {
+		itesting.AssertEqual(t, d.tmpPath, results[i].File)
 		if d.invalid {
 			itesting.AssertError(t, d.expected, err)
 			itesting.AssertError(t, d.tmpPath, err)
+			itesting.AssertError(t, d.expected, results[i].Err)
+			itesting.AssertFalse(t, results[i].Changed)
+		} else {
+			itesting.AssertTrue(t, results[i].Err == nil)
 		}
 		if d.copiedToTmp {
 			if d.invalid {
@@ -357,7 +365,7 @@ func TestFormatFilesInPlaceB(t *testing.T) {
 		defer os.Remove(tmpFile + ".bak")
 		tmpFiles = append(tmpFiles, tmpFile)
 	}
-	err = FormatFilesInPlaceB(tmpFiles, ".bak")
+	_, err = FormatFilesInPlaceB(tmpFiles, ".bak")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -389,7 +397,7 @@ func TestFormatFilesInPlaceBInvalid(t *testing.T) {
 		{
 			file:              "invalid",
 			invalid:           true,
-			expected:          "cannot unmarshal",
+			expected:          "mapping values are not allowed",
 			shouldBackupExist: true,
 		},
 		// Not exists
@@ -421,11 +429,16 @@ func TestFormatFilesInPlaceBInvalid(t *testing.T) {
 			d.copiedToTmp = true
 		}
 	}
-	err := FormatFilesInPlaceB(data.tmpPaths(), ".bak")
-	for _, d := range data {
+	results, err := FormatFilesInPlaceB(data.tmpPaths(), ".bak")
+	for i, d := range data {
+		itesting.AssertEqual(t, d.tmpPath, results[i].File)
 		if d.invalid {
 			itesting.AssertError(t, d.expected, err)
 			itesting.AssertError(t, d.tmpPath, err)
+			itesting.AssertError(t, d.expected, results[i].Err)
+			itesting.AssertFalse(t, results[i].Changed)
+		} else {
+			itesting.AssertTrue(t, results[i].Err == nil)
 		}
 		if d.copiedToTmp {
 			if d.invalid {
@@ -477,3 +490,79 @@ func (d Data) tmpPaths() []string {
 	}
 	return tmpPaths
 }
+
+func TestListFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"a.yml", "b.yaml", "c.txt", "sub/d.yml", "sub/vendor/e.yml"} {
+		itesting.WriteFile(t, dir, file, "a: 1\n")
+	}
+	in := func(files ...string) []string {
+		var paths []string
+		for _, f := range files {
+			paths = append(paths, path.Join(dir, f))
+		}
+		return paths
+	}
+	for _, i := range []struct {
+		paths    []string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{[]string{dir}, nil, nil, in("a.yml", "b.yaml", "sub/d.yml", "sub/vendor/e.yml")},
+		{[]string{dir}, []string{"*.yml"}, nil, in("a.yml", "sub/d.yml", "sub/vendor/e.yml")},
+		{[]string{dir}, nil, []string{"*/vendor/*"}, in("a.yml", "b.yaml", "sub/d.yml")},
+		{[]string{dir}, []string{"*.txt"}, nil, in("c.txt")},
+		{in("c.txt", "sub"), nil, nil, in("c.txt", "sub/d.yml", "sub/vendor/e.yml")},
+		{in("sub/d.yml", "sub"), nil, nil, in("sub/d.yml", "sub/vendor/e.yml")},
+		{in("missing.yml"), nil, nil, in("missing.yml")},
+	} {
+		files, err := ListFiles(i.paths, i.include, i.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertDeepEqual(t, i.expected, files)
+	}
+	_, err := ListFiles([]string{dir}, []string{"["}, nil)
+	itesting.AssertError(t, "invalid glob pattern [", err)
+}
+
+func TestFormatFilesInPlaceResults(t *testing.T) {
+	for _, workers := range []int{0, 1, 2, 10} {
+		var files []string
+		var changed []bool
+		for i := 0; i < 5; i++ {
+			// Formatted and not formatted files
+			file := itesting.TempFilePath(t, "results-*.yml")
+			source := fileToBeFormatted("dev")
+			if i%2 == 0 {
+				source = expectedFile("dev")
+			}
+			if err := io.Copy(source, file); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(file)
+			files = append(files, file)
+			changed = append(changed, i%2 != 0)
+		}
+		files = append(files, "tmp/not-exists")
+		changed = append(changed, false)
+		results, err := FormatFilesInPlace(files, WithWorkers(workers))
+		itesting.AssertEqual(t, len(files), len(results))
+		for i, r := range results {
+			itesting.AssertEqual(t, files[i], r.File)
+			itesting.AssertEqual(t, changed[i], r.Changed)
+		}
+		var fileErr *FileError
+		if !errors.As(err, &fileErr) {
+			t.Fatalf("Expected a file error, got %v", err)
+		}
+		itesting.AssertEqual(t, "tmp/not-exists", fileErr.File)
+		itesting.AssertTrue(t, errors.Is(err, os.ErrNotExist))
+	}
+}
+
+func TestFormatFilesInPlaceInvalidWorkers(t *testing.T) {
+	_, err := FormatFilesInPlace([]string{"tmp/not-exists"}, WithWorkers(-1))
+	itesting.AssertError(t, "invalid workers -1", err)
+}
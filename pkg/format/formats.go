@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FileFormat is the format of a file that can be read as yaml.
+type FileFormat string
+
+const (
+	// YAML is the yaml format.
+	YAML FileFormat = "yaml"
+	// JSON is the json format, which may be a stream of json values.
+	JSON FileFormat = "json"
+	// TOML is the toml format, which does not keep the order of the keys.
+	TOML FileFormat = "toml"
+)
+
+// FileFormats are the valid file formats.
+var FileFormats = []FileFormat{YAML, JSON, TOML}
+
+// Validate returns an error if the file format is unknown.
+func (f FileFormat) Validate() error {
+	if f == "" {
+		return nil
+	}
+	for _, valid := range FileFormats {
+		if f == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown file format %s, valid formats are %v", f, FileFormats)
+}
+
+// DetectFormat returns the format of a file from its extension (.yml, .yaml,
+// .json or .toml) or, if the extension is unknown or there is no file name,
+// from its content: valid json (a value or a stream of values) is JSON, a
+// content that is not a yaml mapping or sequence but valid toml is TOML and
+// anything else is YAML.
+func DetectFormat(file string, content string) FileFormat {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yml", ".yaml":
+		return YAML
+	case ".json":
+		return JSON
+	case ".toml":
+		return TOML
+	}
+	if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if validJSON(trimmed) {
+			return JSON
+		}
+	}
+	if docs, err := yaml.ParseNodes(content); err == nil && len(docs) > 0 {
+		if root := yaml.Root(docs[0]); root == nil || root.Kind != yaml.ScalarNode {
+			return YAML
+		}
+	}
+	var data map[string]interface{}
+	if err := toml.Unmarshal([]byte(content), &data); err == nil && len(data) > 0 {
+		return TOML
+	}
+	return YAML
+}
+
+// validJSON returns whether a content is a stream of valid json values.
+func validJSON(content string) bool {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	for {
+		var v json.RawMessage
+		if err := decoder.Decode(&v); err == io.EOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+// ParseDocuments parses a content in a file format (YAML if empty) as a stream
+// of yaml document nodes.
+func ParseDocuments(content string, f FileFormat) ([]*yaml.Node, error) {
+	switch f {
+	case "", YAML:
+		return yaml.ParseNodes(content)
+	case JSON:
+		return yaml.ParseJSON(content)
+	case TOML:
+		return yaml.ParseTOML(content)
+	}
+	return nil, f.Validate()
+}
+
+// composeDocuments writes a stream of formatted yaml document nodes in the
+// output format.
+func (o Options) composeDocuments(docs []*yaml.Node) (string, error) {
+	switch o.OutputFormat {
+	case JSON:
+		indent := o.Indent
+		if indent == 0 {
+			indent = 2
+		}
+		return yaml.ComposeJSON(docs, indent)
+	case TOML:
+		return yaml.ComposeTOML(docs)
+	}
+	return yaml.ComposeNodes(docs, o.style())
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package format
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for _, i := range []struct {
+		file     string
+		content  string
+		expected FileFormat
+	}{
+		{file: "a.yml", content: `{"a": 1}`, expected: YAML},
+		{file: "a.YAML", content: "a = 1", expected: YAML},
+		{file: "a.json", content: "a: 1", expected: JSON},
+		{file: "a.toml", content: "a: 1", expected: TOML},
+		{content: `{"a": 1}`, expected: JSON},
+		{content: `[1, 2]`, expected: JSON},
+		{content: "{\"a\": 1}\n{\"a\": 2}\n", expected: JSON},
+		{content: "{\"a\": 1}\nb: 2\n", expected: YAML},
+		{content: "{a: 1}", expected: YAML},
+		{content: "a: 1\nb: [1, 2]\n", expected: YAML},
+		{content: "a = 1\n[b]\nc = 'c'\n", expected: TOML},
+		{content: "just text", expected: YAML},
+		{content: "", expected: YAML},
+	} {
+		itesting.AssertEqual(t, i.expected, DetectFormat(i.file, i.content))
+	}
+}
+
+func TestFileFormatValidate(t *testing.T) {
+	for _, f := range append(FileFormats, "") {
+		itesting.AssertEqual(t, nil, f.Validate())
+	}
+	itesting.AssertError(t, "unknown file format xml, valid formats are [yaml json toml]", FileFormat("xml").Validate())
+}
+
+func TestParseDocuments(t *testing.T) {
+	for name, i := range map[string]struct {
+		content  string
+		format   FileFormat
+		expected string
+	}{
+		"json keeps the order": {
+			content:  `{"b": {"z": 1, "y": [true, null]}, "a": "http:\/\/x<y>"}`,
+			format:   JSON,
+			expected: "b:\n  z: 1\n  \"y\":\n  - true\n  - null\na: http://x<y>\n",
+		},
+		"json stream": {
+			content:  "{\"a\": 1}\n{\"a\": 2}\n",
+			format:   JSON,
+			expected: "a: 1\n---\na: 2\n",
+		},
+		"toml": {
+			content:  "name = 'svc'\n[limits]\ncpu = 1.5\n",
+			format:   TOML,
+			expected: "limits:\n  cpu: 1.5\nname: svc\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			docs, err := ParseDocuments(i.content, i.format)
+			itesting.AssertEqual(t, nil, err)
+			actual, err := FormatDocuments(docs, WithKeyOrder(KeyOrder{Order: Source}))
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, i.expected, actual)
+		})
+	}
+}
+
+func TestParseDocumentsLines(t *testing.T) {
+	docs, err := ParseDocuments("{\n  \"a\": 1,\n  \"b\": {\n    \"c\": 2\n  }\n}\n", JSON)
+	itesting.AssertEqual(t, nil, err)
+	root := docs[0].Content[0]
+	itesting.AssertEqual(t, 2, root.Content[0].Line)
+	itesting.AssertEqual(t, 4, root.Content[3].Content[0].Line)
+}
+
+func TestParseDocumentsError(t *testing.T) {
+	_, err := ParseDocuments(`{"a": }`, JSON)
+	itesting.AssertError(t, "invalid json", err)
+	_, err = ParseDocuments("a = = 1", TOML)
+	itesting.AssertError(t, "invalid toml", err)
+	_, err = ParseDocuments("a: 1", "xml")
+	itesting.AssertError(t, "unknown file format xml", err)
+}
+
+func TestFormatContentOutputFormat(t *testing.T) {
+	content := "name: svc # comment\nports: [80, 443]\nlimits:\n  cpu: 1.5\n"
+	for name, i := range map[string]struct {
+		format   FileFormat
+		expected string
+	}{
+		"yaml": {
+			format:   YAML,
+			expected: "limits:\n  cpu: 1.5\nname: svc # comment\nports:\n- 80\n- 443\n",
+		},
+		"json": {
+			format:   JSON,
+			expected: "{\n  \"limits\": {\n    \"cpu\": 1.5\n  },\n  \"name\": \"svc\",\n  \"ports\": [\n    80,\n    443\n  ]\n}\n",
+		},
+		"toml": {
+			format:   TOML,
+			expected: "name = 'svc'\nports = [80, 443]\n\n[limits]\ncpu = 1.5\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := FormatContent(content, WithOutputFormat(i.format))
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, i.expected, actual)
+		})
+	}
+}
+
+func TestFormatContentOutputFormatError(t *testing.T) {
+	_, err := FormatContent("a: 1\n---\na: 2\n", WithOutputFormat(TOML))
+	itesting.AssertError(t, "toml requires a single document with a mapping", err)
+	_, err = FormatContent("[1, 2]", WithOutputFormat(TOML))
+	itesting.AssertError(t, "toml requires a single document with a mapping", err)
+	_, err = FormatContent("k: ~\n", WithOutputFormat(TOML))
+	itesting.AssertError(t, "toml cannot write null values, k is null", err)
+	_, err = FormatContent("a: 1\nn: {x: null}\n", WithOutputFormat(TOML))
+	itesting.AssertError(t, "toml cannot write null values, n.x is null", err)
+	_, err = FormatContent("n: &n\nl: [1, *n]\n", WithOutputFormat(TOML))
+	itesting.AssertError(t, "toml cannot write null values, l[1] is null", err)
+	_, err = FormatContent("a: 1", WithOutputFormat("xml"))
+	itesting.AssertError(t, "unknown file format xml", err)
+}
+
+func TestFormatContentJSONInput(t *testing.T) {
+	actual, err := FormatContent(`{"b": 1, "a": {"c": [1]}}`)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a:\n  c:\n  - 1\nb: 1\n", actual)
+}
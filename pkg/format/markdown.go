@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/markdown"
+)
+
+// IsMarkdown returns whether a file is a markdown file (.md or .markdown),
+// which is formatted with FormatMarkdown.
+func IsMarkdown(file string) bool {
+	return markdown.IsMarkdown(file)
+}
+
+// FormatMarkdown formats the yaml regions of a markdown content: the front
+// matter (between "---" lines at the start of the content) and the fenced code
+// blocks with yaml (or yml) info string. The rest of the content is left
+// untouched. Empty regions are not formatted.
+func FormatMarkdown(content string, opts ...Option) (string, error) {
+	var sb strings.Builder
+	last := 0
+	for _, r := range markdown.Regions(content) {
+		yaml := r.YAML(content)
+		if strings.TrimSpace(yaml) == "" {
+			continue
+		}
+		formatted, err := FormatContent(yaml, opts...)
+		if err != nil {
+			return "", fmt.Errorf("yaml at line %d: %w", r.Line, err)
+		}
+		sb.WriteString(content[last:r.Start])
+		sb.WriteString(r.Indented(formatted))
+		last = r.End
+	}
+	sb.WriteString(content[last:])
+	return sb.String(), nil
+}
+
+// formatAny formats a yaml or markdown content, depending on the file name.
+// Json and toml files keep their format unless another output format is
+// configured.
+func formatAny(file string, content string, opts []Option) (string, error) {
+	if IsMarkdown(file) {
+		return FormatMarkdown(content, opts...)
+	}
+	f := DetectFormat(file, content)
+	docs, err := ParseDocuments(content, f)
+	if err != nil {
+		return "", err
+	}
+	if newOptions(opts).OutputFormat == "" {
+		opts = append(opts, WithOutputFormat(f))
+	}
+	return FormatDocuments(docs, opts...)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package format
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	for name, i := range map[string]struct {
+		content  string
+		expected string
+	}{
+		"front matter": {
+			content:  "---\ntitle: Hello\ntags: [a, b]\n---\n# Title\n\nkey: value\n",
+			expected: "---\ntags:\n- a\n- b\ntitle: Hello\n---\n# Title\n\nkey: value\n",
+		},
+		"code blocks": {
+			content:  "Text\n\n```yaml\nb: 1\na: 'x'\n```\n\n```go\nb := 1\n```\n",
+			expected: "Text\n\n```yaml\na: x\nb: 1\n```\n\n```go\nb := 1\n```\n",
+		},
+		"indented code blocks": {
+			content:  "- Item\n\n  ```yml\n  a: {b: 1}\n  ```\n",
+			expected: "- Item\n\n  ```yml\n  a:\n    b: 1\n  ```\n",
+		},
+		"empty blocks": {
+			content:  "---\n---\n```yaml\n\n```\n",
+			expected: "---\n---\n```yaml\n\n```\n",
+		},
+		"no yaml": {
+			content:  "# Title\n---\na: {b: 1}\n---\n",
+			expected: "# Title\n---\na: {b: 1}\n---\n",
+		},
+	} {
+		formatted, err := FormatMarkdown(i.content)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, i.expected, formatted)
+	}
+}
+
+func TestFormatMarkdownInvalid(t *testing.T) {
+	formatted, err := FormatMarkdown("# Title\n\n```yaml\na: b: c\n```\n")
+	itesting.AssertError(t, "yaml at line 4: yaml: mapping values are not allowed", err)
+	itesting.AssertEqual(t, "", formatted)
+}
+
+func TestFormatFileMarkdown(t *testing.T) {
+	file := itesting.WriteFile(t, t.TempDir(), "doc.md", "---\nb: 1\na: 2\n---\nb: 1\na: 2\n")
+	result := CheckFile(file)
+	itesting.AssertFalse(t, result.Formatted)
+	if err := FormatFileInPlace(file); err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, "---\na: 2\nb: 1\n---\nb: 1\na: 2\n", itesting.ReadFile(t, file))
+	result = CheckFile(file)
+	itesting.AssertTrue(t, result.Formatted)
+}
@@ -0,0 +1,369 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package format
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// Order is the criteria to order the keys of a mapping.
+type Order string
+
+const (
+	// Alphabetical sorts keys alphabetically, numbers are compared by value.
+	Alphabetical Order = "alphabetical"
+	// Source keeps keys in the order they are written.
+	Source Order = "source"
+)
+
+// Orders are the valid key orders.
+var Orders = []Order{Alphabetical, Source}
+
+// KeyOrder is the policy to order the keys of mappings. Priority keys go first,
+// in the given order, and the rest of the keys follow the Order (alphabetical
+// if empty). Paths override the policy for the mappings in specific paths.
+type KeyOrder struct {
+	Order    Order          // Order of the keys without priority
+	Priority []string       // Keys that go first
+	Paths    []PathKeyOrder // Policies for specific paths
+}
+
+// PathKeyOrder overrides the key order of the mappings in a path.
+//
+// A path is a list of keys separated by dots, with [n] selecting the n-th
+// element of a sequence and * (or [*]) matching any key or element, for
+// example "spec.template.spec.containers[*]". Dots in keys are escaped with a
+// backslash or quoting the key. When several paths match a mapping the one
+// with less wildcards is used, then the first declared.
+type PathKeyOrder struct {
+	Path     string   // Path of the mappings
+	Order    Order    // Order of the keys without priority (policy order if empty)
+	Priority []string // Keys that go first, replacing the policy priority keys
+}
+
+// Quote is the preferred quote style for strings that need quotes.
+type Quote string
+
+const (
+	// AutoQuote uses single quotes, or double quotes for strings that would be
+	// read as a number, boolean or null without quotes.
+	AutoQuote Quote = "auto"
+	// SingleQuote uses single quotes unless the string needs escaping.
+	SingleQuote Quote = "single"
+	// DoubleQuote always uses double quotes.
+	DoubleQuote Quote = "double"
+)
+
+// Quotes are the valid quote styles.
+var Quotes = []Quote{AutoQuote, SingleQuote, DoubleQuote}
+
+// Anchors is how anchors, aliases and merge keys are formatted.
+type Anchors string
+
+const (
+	// ExpandAnchors replaces aliases and merge keys with the data they
+	// reference and removes the anchors.
+	ExpandAnchors Anchors = "expand"
+	// PreserveAnchors keeps anchors, aliases and merge keys as written. If the
+	// keys are reordered so that an alias goes before its anchor, they swap
+	// places.
+	PreserveAnchors Anchors = "preserve"
+	// DedupeAnchors keeps anchors, aliases and merge keys and replaces repeated
+	// mappings and sequences with aliases of an anchor in their first
+	// occurrence, named after its key.
+	DedupeAnchors Anchors = "dedupe"
+)
+
+// AnchorModes are the valid anchor modes.
+var AnchorModes = []Anchors{ExpandAnchors, PreserveAnchors, DedupeAnchors}
+
+// DefaultWidth is the maximum line width of the formatted yaml unless another
+// one is configured.
+const DefaultWidth = 80
+
+// Options configures how yaml is formatted.
+type Options struct {
+	KeyOrder        KeyOrder // Policy to order mapping keys
+	Indent          int      // Spaces of each indentation level, from 2 to 9 (2 if 0)
+	IndentSequences bool     // Whether sequences are indented inside mappings
+	Width           int      // Maximum line width to fold long strings (0 never folds, see DefaultWidth)
+	Quote           Quote    // Preferred quote style (AutoQuote if empty)
+	EscapeNonASCII  bool     // Whether non ASCII characters are escaped in strings
+	Anchors         Anchors  // How anchors and aliases are formatted (ExpandAnchors if empty)
+	Workers         int      // Files formatted in parallel (number of CPUs if 0)
+	// OutputFormat is the format of the formatted content, YAML if empty
+	// (files keep their format, see DetectFormat)
+	OutputFormat FileFormat
+}
+
+// Option configures the format options.
+type Option func(o *Options)
+
+// WithOptions replaces all the format options.
+func WithOptions(options Options) Option {
+	return func(o *Options) {
+		*o = options
+	}
+}
+
+// WithKeyOrder configures the policy to order mapping keys (alphabetically by
+// default).
+func WithKeyOrder(order KeyOrder) Option {
+	return func(o *Options) {
+		o.KeyOrder = order
+	}
+}
+
+// WithIndent configures the spaces of each indentation level, from 2 to 9 (2 by
+// default).
+func WithIndent(spaces int) Option {
+	return func(o *Options) {
+		o.Indent = spaces
+	}
+}
+
+// WithIndentSequences configures whether sequences are indented inside
+// mappings. By default the "- " of the elements is aligned with the parent key.
+func WithIndentSequences(indent bool) Option {
+	return func(o *Options) {
+		o.IndentSequences = indent
+	}
+}
+
+// WithWidth configures the maximum line width, longer strings are folded at the
+// first space past the width when possible (DefaultWidth by default, 0 never
+// folds).
+func WithWidth(width int) Option {
+	return func(o *Options) {
+		o.Width = width
+	}
+}
+
+// WithQuote configures the preferred quote style for strings that need quotes.
+func WithQuote(quote Quote) Option {
+	return func(o *Options) {
+		o.Quote = quote
+	}
+}
+
+// WithEscapeNonASCII configures whether non ASCII characters are escaped in
+// strings, which are written with double quotes.
+func WithEscapeNonASCII(escape bool) Option {
+	return func(o *Options) {
+		o.EscapeNonASCII = escape
+	}
+}
+
+// WithAnchors configures how anchors, aliases and merge keys are formatted
+// (expanded by default).
+func WithAnchors(anchors Anchors) Option {
+	return func(o *Options) {
+		o.Anchors = anchors
+	}
+}
+
+// WithWorkers configures how many files are formatted in parallel when
+// formatting several files (the number of CPUs by default).
+func WithWorkers(workers int) Option {
+	return func(o *Options) {
+		o.Workers = workers
+	}
+}
+
+// WithOutputFormat configures the format of the formatted content (YAML by
+// default, files keep their format). JSON and TOML lose the comments and
+// expand the anchors.
+func WithOutputFormat(f FileFormat) Option {
+	return func(o *Options) {
+		o.OutputFormat = f
+	}
+}
+
+func newOptions(opts []Option) *Options {
+	o := &Options{Width: DefaultWidth}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Validate returns an error if any option is not valid.
+func (o Options) Validate() error {
+	if o.Indent != 0 && (o.Indent < 2 || o.Indent > 9) {
+		return fmt.Errorf("invalid indent %d, it must be between 2 and 9", o.Indent)
+	}
+	if o.Workers < 0 {
+		return fmt.Errorf("invalid workers %d, it must not be negative", o.Workers)
+	}
+	if o.Width < 0 {
+		return fmt.Errorf("invalid width %d, it must not be negative", o.Width)
+	}
+	if err := o.Quote.validate(); err != nil {
+		return err
+	}
+	if err := o.Anchors.validate(); err != nil {
+		return err
+	}
+	if err := o.OutputFormat.Validate(); err != nil {
+		return err
+	}
+	return o.KeyOrder.Validate()
+}
+
+// workers returns how many workers format a number of files.
+func (o Options) workers(files int) int {
+	workers := o.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > files {
+		workers = files
+	}
+	return workers
+}
+
+// style returns the internal style of the options.
+func (o Options) style() yaml.Style {
+	style := yaml.Style{
+		Indent:          o.Indent,
+		IndentSequences: o.IndentSequences,
+		Width:           o.Width,
+		EscapeNonASCII:  o.EscapeNonASCII,
+	}
+	switch o.Quote {
+	case SingleQuote:
+		style.Quote = yaml.SingleQuote
+	case DoubleQuote:
+		style.Quote = yaml.DoubleQuote
+	}
+	return style
+}
+
+func (q Quote) validate() error {
+	if q == "" {
+		return nil
+	}
+	for _, valid := range Quotes {
+		if q == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown quote style %s, valid styles are %v", q, Quotes)
+}
+
+// normalize cleans a document expanding or keeping its anchors.
+func (a Anchors) normalize(doc *yaml.Node) error {
+	if a == "" || a == ExpandAnchors {
+		return yaml.Normalize(doc)
+	}
+	return yaml.NormalizeAnchors(doc)
+}
+
+// arrange deduplicates the nodes of a document with ordered keys, if
+// configured, and makes sure every anchor goes before its aliases.
+func (a Anchors) arrange(doc *yaml.Node) {
+	switch a {
+	case DedupeAnchors:
+		yaml.Dedupe(doc)
+		fallthrough
+	case PreserveAnchors:
+		yaml.OrderAnchors(doc)
+	}
+}
+
+func (a Anchors) validate() error {
+	if a == "" {
+		return nil
+	}
+	for _, valid := range AnchorModes {
+		if a == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown anchors mode %s, valid modes are %v", a, AnchorModes)
+}
+
+// Validate returns an error if the policy contains an unknown order or an
+// invalid path.
+func (k KeyOrder) Validate() error {
+	_, err := k.paths()
+	return err
+}
+
+// paths validates the policy returning its parsed paths.
+func (k KeyOrder) paths() ([]path.Path, error) {
+	if err := k.Order.validate(); err != nil {
+		return nil, err
+	}
+	paths := make([]path.Path, len(k.Paths))
+	for i, p := range k.Paths {
+		var err error
+		if paths[i], err = path.Parse(p.Path); err != nil {
+			return nil, err
+		}
+		if err = p.Order.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Path, err)
+		}
+	}
+	return paths, nil
+}
+
+// keyOrder returns the internal key order of the policy.
+func (k KeyOrder) keyOrder() (yaml.KeyOrder, error) {
+	paths, err := k.paths()
+	if err != nil {
+		return nil, err
+	}
+	return func(concrete path.Path) ([]string, bool) {
+		priority, order := k.Priority, k.Order
+		best := -1
+		for i, p := range paths {
+			if p.Match(concrete) && (best < 0 || p.Wildcards() < paths[best].Wildcards()) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			priority = k.Paths[best].Priority
+			if k.Paths[best].Order != "" {
+				order = k.Paths[best].Order
+			}
+		}
+		return priority, order == Source
+	}, nil
+}
+
+func (o Order) validate() error {
+	if o == "" {
+		return nil
+	}
+	for _, valid := range Orders {
+		if o == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown key order %s, valid orders are %v", o, Orders)
+}
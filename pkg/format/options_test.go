@@ -0,0 +1,405 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package format
+
+import (
+	"strings"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestFormatContentKeyOrder(t *testing.T) {
+	manifest := `spec:
+  replicas: 2
+  containers:
+  - name: app
+    image: app:1
+    env: []
+kind: Deployment
+metadata: {name: app, labels: {b: b, a: a}}
+apiVersion: apps/v1
+`
+	for name, i := range map[string]struct {
+		order    KeyOrder
+		expected string
+	}{
+		"alphabetical by default": {
+			order: KeyOrder{},
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    a: a
+    b: b
+  name: app
+spec:
+  containers:
+  - env: []
+    image: app:1
+    name: app
+  replicas: 2
+`,
+		},
+		"source": {
+			order: KeyOrder{Order: Source},
+			expected: `spec:
+  replicas: 2
+  containers:
+  - name: app
+    image: app:1
+    env: []
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    b: b
+    a: a
+apiVersion: apps/v1
+`,
+		},
+		"priority": {
+			order: KeyOrder{Priority: []string{"kind", "apiVersion", "name", "missing"}},
+			expected: `kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: app
+  labels:
+    a: a
+    b: b
+spec:
+  containers:
+  - name: app
+    env: []
+    image: app:1
+  replicas: 2
+`,
+		},
+		"priority with source order": {
+			order: KeyOrder{Order: Source, Priority: []string{"apiVersion", "kind"}},
+			expected: `apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 2
+  containers:
+  - name: app
+    image: app:1
+    env: []
+metadata:
+  name: app
+  labels:
+    b: b
+    a: a
+`,
+		},
+		"paths": {
+			order: KeyOrder{
+				Priority: []string{"apiVersion", "kind", "metadata", "spec"},
+				Paths: []PathKeyOrder{
+					{Path: "metadata.labels", Order: Source},
+					{Path: "spec.containers[*]", Priority: []string{"name", "image"}},
+					{Path: "spec.*", Priority: []string{"env"}},
+				},
+			},
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    b: b
+    a: a
+  name: app
+spec:
+  containers:
+  - name: app
+    image: app:1
+    env: []
+  replicas: 2
+`,
+		},
+		"most specific path": {
+			order: KeyOrder{
+				Paths: []PathKeyOrder{
+					{Path: "*", Priority: []string{"replicas", "name"}},
+					{Path: "spec", Priority: []string{"containers"}},
+				},
+			},
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    a: a
+    b: b
+spec:
+  containers:
+  - env: []
+    image: app:1
+    name: app
+  replicas: 2
+`,
+		},
+		"root path": {
+			order: KeyOrder{
+				Order: Source,
+				Paths: []PathKeyOrder{{Path: "", Order: Alphabetical}},
+			},
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    b: b
+    a: a
+spec:
+  replicas: 2
+  containers:
+  - name: app
+    image: app:1
+    env: []
+`,
+		},
+	} {
+		formatted, err := FormatContent(manifest, WithKeyOrder(i.order))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, i.expected, formatted)
+	}
+}
+
+func TestFormatContentKeyOrderInvalid(t *testing.T) {
+	for _, i := range []struct {
+		order    KeyOrder
+		expected string
+	}{
+		{
+			order:    KeyOrder{Order: "random"},
+			expected: "unknown key order random",
+		},
+		{
+			order:    KeyOrder{Paths: []PathKeyOrder{{Path: "a", Order: "random"}}},
+			expected: "a: unknown key order random",
+		},
+		{
+			order:    KeyOrder{Paths: []PathKeyOrder{{Path: "a[x]"}}},
+			expected: "invalid path a[x]",
+		},
+	} {
+		formatted, err := FormatContent("a: 1", WithKeyOrder(i.order))
+		itesting.AssertError(t, i.expected, err)
+		itesting.AssertEqual(t, "", formatted)
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	formatted, err := FormatContent("b: 1\na: 2", WithOptions(Options{KeyOrder: KeyOrder{Order: Source}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, "b: 1\na: 2\n", formatted)
+}
+
+func TestFormatContentStyle(t *testing.T) {
+	for name, i := range map[string]struct {
+		content  string
+		options  []Option
+		expected string
+	}{
+		"indent": {
+			content:  "a:\n  b: {c: [1]}\n  d: |\n    x\n    y\n",
+			options:  []Option{WithIndent(4)},
+			expected: "a:\n    b:\n        c:\n        - 1\n    d: |\n        x\n        y\n",
+		},
+		"indent sequences": {
+			content:  "a: {b: [1, {c: 2, d: [3]}]}",
+			options:  []Option{WithIndentSequences(true)},
+			expected: "a:\n  b:\n    - 1\n    - c: 2\n      d:\n        - 3\n",
+		},
+		"indent sequences with indent": {
+			content:  "a: [{c: 2, d: [3]}]",
+			options:  []Option{WithIndent(4), WithIndentSequences(true)},
+			expected: "a:\n    - c: 2\n      d:\n          - 3\n",
+		},
+		"width": {
+			content:  "a: {plain: one two three four five six, quoted: 'one: two three four five six'}",
+			options:  []Option{WithWidth(20)},
+			expected: "a:\n  plain: one two three\n    four five six\n  quoted: 'one: two three\n    four five six'\n",
+		},
+		"width breaks after the first word past the width": {
+			content:  "a: one -two ~three four",
+			options:  []Option{WithWidth(10)},
+			expected: "a: one -two\n  ~three four\n",
+		},
+		"width escapes spaces starting a line": {
+			content:  `a: "one two  three\t"`,
+			options:  []Option{WithWidth(8)},
+			expected: "a: \"one two\n  \\ three\\t\"\n",
+		},
+		"default width": {
+			content:  "a: " + strings.Repeat("word ", 20) + "end",
+			expected: "a: " + strings.TrimSpace(strings.Repeat("word ", 16)) + "\n  word word word word end\n",
+		},
+		"width 0 never folds": {
+			content:  "a: " + strings.Repeat("word ", 20) + "end",
+			options:  []Option{WithWidth(0)},
+			expected: "a: " + strings.Repeat("word ", 20) + "end\n",
+		},
+		"width does not break long words": {
+			content:  "a: onetwothreefourfivesix",
+			options:  []Option{WithWidth(10)},
+			expected: "a: onetwothreefourfivesix\n",
+		},
+		"width in sequences": {
+			content:  "a: [one two three four five six]",
+			options:  []Option{WithWidth(20)},
+			expected: "a:\n- one two three four five\n  six\n",
+		},
+		"merge key strings": {
+			content:  "a: \"<<\"\n'<<': b\nl: [\"<<\", {k: '<<'}]",
+			expected: "\"<<\": b\na: \"<<\"\nl:\n- \"<<\"\n- k: \"<<\"\n",
+		},
+		"double quotes": {
+			content:  "a: '{'\nb: \"123\"\nc: it's",
+			options:  []Option{WithQuote(DoubleQuote)},
+			expected: "a: \"{\"\nb: \"123\"\nc: it's\n",
+		},
+		"single quotes": {
+			content:  "a: \"{\"\nb: \"123\"\nc: \"tab\\t\"",
+			options:  []Option{WithQuote(SingleQuote)},
+			expected: "a: '{'\nb: '123'\nc: \"tab\\t\"\n",
+		},
+		"escape non ASCII": {
+			content:  "a: ñandú\nb: plain\nñ: 1",
+			options:  []Option{WithEscapeNonASCII(true)},
+			expected: "a: \"\\u00F1and\\u00FA\"\nb: plain\n\"\\u00F1\": 1\n",
+		},
+	} {
+		formatted, err := FormatContent(i.content, i.options...)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, i.expected, formatted)
+		// Formatting must be idempotent
+		again, err := FormatContent(formatted, i.options...)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, formatted, again)
+	}
+}
+
+func TestFormatContentStyleInvalid(t *testing.T) {
+	for _, i := range []struct {
+		options  Options
+		expected string
+	}{
+		{
+			options:  Options{Indent: 1},
+			expected: "invalid indent 1",
+		},
+		{
+			options:  Options{Indent: 10},
+			expected: "invalid indent 10",
+		},
+		{
+			options:  Options{Width: -1},
+			expected: "invalid width -1",
+		},
+		{
+			options:  Options{Quote: "backtick"},
+			expected: "unknown quote style backtick",
+		},
+		{
+			options:  Options{Anchors: "inline"},
+			expected: "unknown anchors mode inline",
+		},
+	} {
+		formatted, err := FormatContent("a: 1", WithOptions(i.options))
+		itesting.AssertError(t, i.expected, err)
+		itesting.AssertEqual(t, "", formatted)
+	}
+}
+
+func TestFormatContentAnchors(t *testing.T) {
+	for name, i := range map[string]struct {
+		content  string
+		anchors  Anchors
+		expected string
+	}{
+		"expand": {
+			content:  "b: &d {x: 1}\na: *d\nc:\n  <<: *d\n  y: 2\n",
+			anchors:  ExpandAnchors,
+			expected: "a:\n  x: 1\nb:\n  x: 1\nc:\n  x: 1\n  \"y\": 2\n",
+		},
+		"preserve": {
+			content:  "b: &d {x: 1}\nc:\n  y: 2\n  <<: *d\n",
+			anchors:  PreserveAnchors,
+			expected: "b: &d\n  x: 1\nc:\n  <<: *d\n  \"y\": 2\n",
+		},
+		"preserve scalars and sequences": {
+			content:  "a: &s [1, 2]\nb: *s\nc: &v value # comment\nd: *v\n",
+			anchors:  PreserveAnchors,
+			expected: "a: &s\n- 1\n- 2\nb: *s\nc: &v value # comment\nd: *v\n",
+		},
+		"preserve moves anchors before aliases": {
+			content:  "# Head\nb: &d # Line\n  x: 1\na: *d # Alias\n",
+			anchors:  PreserveAnchors,
+			expected: "a: &d # Alias\n  x: 1\n# Head\nb: *d # Line\n",
+		},
+		"preserve renames redefined anchors": {
+			content:  "a: &d 1\nb: *d\nc: &d 2\nd: *d\n",
+			anchors:  PreserveAnchors,
+			expected: "a: &d 1\nb: *d\nc: &d-2 2\nd: *d-2\n",
+		},
+		"dedupe": {
+			content:  "a: {labels: {app: web}}\nb: {selector: {app: web}}\nc: [[1], [1]]\n",
+			anchors:  DedupeAnchors,
+			expected: "a:\n  labels: &labels\n    app: web\nb:\n  selector: *labels\nc:\n- &c\n  - 1\n- *c\n",
+		},
+		"dedupe keeps anchors": {
+			content:  "a: &x {k: 1}\nb: {k: 1}\nc: *x\n",
+			anchors:  DedupeAnchors,
+			expected: "a: &x\n  k: 1\nb: *x\nc: *x\n",
+		},
+		"dedupe needs the same comments": {
+			content:  "a:\n  k: 1 # one\nb:\n  k: 1\n",
+			anchors:  DedupeAnchors,
+			expected: "a:\n  k: 1 # one\nb:\n  k: 1\n",
+		},
+	} {
+		formatted, err := FormatContent(i.content, WithAnchors(i.anchors))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, i.expected, formatted)
+		// Formatting must be idempotent and represent the same data
+		again, err := FormatContent(formatted, WithAnchors(i.anchors))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, formatted, again)
+		expanded, _ := FormatContent(i.content)
+		again, _ = FormatContent(formatted)
+		itesting.AssertEqual(t, expanded, again)
+	}
+}
@@ -24,45 +24,122 @@ package merge
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
 )
 
 // MergeContents returns the result of merging two yaml contents. A yaml leaf
 // node in the 'changes' content takes precedence over and replaces the value in
 // the 'base' content.
-func MergeContents(base string, changes string) (string, error) {
-	baseData, err := yaml.Parse(base)
-	if err != nil {
-		return "", err
+func MergeContents(base string, changes string, opts ...Option) (string, error) {
+	return MergeAllContents([]string{base, changes}, opts...)
+}
+
+// MergeAllContents returns the result of merging all yaml contents, which should
+// be ordered in ascending level of importance in the hierarchy. A yaml leaf node
+// in the last content takes precedence over and replaces the value in any
+// previous content.
+//
+// The merged yaml is formatted, keeping the comments. When keys are kept in
+// source order (see format.WithKeyOrder) the keys of the first content go first
+// and new keys are added after them. The documents of multi-document streams
+// are merged with the document strategy (see WithDocumentStrategy).
+func MergeAllContents(contents []string, opts ...Option) (string, error) {
+	return mergeSources(contentSources(contents), newOptions(opts))
+}
+
+// source is a yaml content to merge.
+type source struct {
+	name    string // Name in the provenance (file or content number)
+	offset  int    // Lines of the file before the content
+	content string
+	format  format.FileFormat
+}
+
+// contentSources returns the sources of contents named by their number, with
+// the format detected from their content.
+func contentSources(contents []string) []source {
+	sources := make([]source, len(contents))
+	for i, content := range contents {
+		sources[i] = source{name: fmt.Sprintf("content %d", i+1), content: content, format: format.DetectFormat("", content)}
 	}
-	changesData, err := yaml.Parse(changes)
+	return sources
+}
+
+// mergeSources returns the formatted result of merging yaml sources, with the
+// origin of each value as comment if explained.
+func mergeSources(sources []source, o *options) (string, error) {
+	merged, tracker, err := mergeTracked(sources, o, o.explain)
 	if err != nil {
 		return "", err
 	}
-	mergedData, err := yaml.Merge(baseData, changesData)
-	if err != nil {
-		return "", err
+	if o.explain {
+		for _, doc := range merged {
+			explain(doc, tracker)
+		}
 	}
-	return yaml.Compose(mergedData)
+	return format.FormatDocuments(merged, o.format...)
 }
 
-// MergeAllContents returns the result of merging all yaml contents, which should
-// be ordered in ascending level of importance in the hierarchy. A yaml leaf node
-// in the last content takes precedence over and replaces the value in any
-// previous content.
-func MergeAllContents(contents []string) (string, error) {
-	if len(contents) < 2 {
-		return "", errors.New("slice must contain at least two contents")
+// mergeDocuments returns the documents resulting of merging yaml sources, a
+// single source is returned as read.
+func mergeDocuments(sources []source, o *options) ([]*yaml.Node, error) {
+	o.single = true
+	docs, _, err := mergeTracked(sources, o, false)
+	return docs, err
+}
+
+// mergeTracked returns the documents resulting of merging yaml sources and,
+// if tracked, the tracker of the nodes that set each value.
+func mergeTracked(sources []source, o *options, track bool) ([]*yaml.Node, *yaml.Tracker, error) {
+	if len(sources) < o.minSources() {
+		return nil, nil, errors.New("slice must contain at least two contents")
+	}
+	mo, err := o.mergeOptions()
+	if err != nil {
+		return nil, nil, err
 	}
-	var result string
-	var err error
-	result = contents[0]
-	for i := 1; i < len(contents); i++ {
-		result, err = MergeContents(result, contents[i])
+	if mo.Locks, err = o.locks(); err != nil {
+		return nil, nil, err
+	}
+	if track || o.strict.enabled() || o.protects(sources) {
+		mo.Tracker = yaml.NewTracker()
+	}
+	var conflicts []Conflict
+	mo.Check = o.strict.check(mo.Tracker, &conflicts)
+	streams := make([][]*yaml.Node, len(sources))
+	for i, s := range sources {
+		docs, err := format.ParseDocuments(s.content, s.format)
 		if err != nil {
-			return "", err
+			return nil, nil, err
+		}
+		for _, doc := range docs {
+			// Locked tags are removed before their style is normalized
+			mo.Locks.Read(doc)
+			// Anchors are expanded, if configured, when formatting
+			if err = yaml.NormalizeAnchors(doc); err != nil {
+				return nil, nil, err
+			}
+			if mo.Tracker != nil {
+				mo.Tracker.Read(doc, s.name, s.offset)
+			}
+		}
+		streams[i] = docs
+	}
+	merged, err := mergeStreams(streams, o, mo)
+	if err == nil && (o.env.enabled() || len(o.sets) > 0) {
+		if len(merged) == 0 {
+			merged = []*yaml.Node{{Kind: yaml.DocumentNode}}
 		}
+		merged[0], err = o.overlay(merged[0], mo)
+	}
+	if err != nil {
+		return nil, nil, protectedError(err, mo.Tracker)
+	}
+	if len(conflicts) > 0 {
+		return nil, nil, &StrictError{Conflicts: conflicts}
 	}
-	return result, nil
+	return merged, mo.Tracker, nil
 }
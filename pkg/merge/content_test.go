@@ -24,10 +24,12 @@ package merge
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	itesting "github.com/amplia-iiot/yutil/internal/testing"
 	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
 )
 
 func TestMergeContents(t *testing.T) {
@@ -132,7 +134,451 @@ func TestMergeContents(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		itesting.AssertEqual(t, format(t, i.expected), merged)
+		itesting.AssertEqual(t, compose(t, i.expected), merged)
+	}
+}
+
+func TestMergeContentsKeyOrder(t *testing.T) {
+	base := "kind: Deployment\nspec: {replicas: 1, image: app}\napiVersion: v1\n"
+	changes := "spec: {replicas: 2, env: [a]}\nmetadata: {name: app}\n"
+	for _, i := range []struct {
+		order    format.KeyOrder
+		expected string
+	}{
+		{
+			order:    format.KeyOrder{},
+			expected: "apiVersion: v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  env:\n  - a\n  image: app\n  replicas: 2\n",
+		},
+		// New keys go after the base keys
+		{
+			order:    format.KeyOrder{Order: format.Source},
+			expected: "kind: Deployment\nspec:\n  replicas: 2\n  image: app\n  env:\n  - a\napiVersion: v1\nmetadata:\n  name: app\n",
+		},
+		{
+			order:    format.KeyOrder{Order: format.Source, Priority: []string{"apiVersion", "kind", "metadata"}},
+			expected: "apiVersion: v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 2\n  image: app\n  env:\n  - a\n",
+		},
+	} {
+		merged, err := MergeContents(base, changes, WithFormat(format.WithKeyOrder(i.order)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeContentsStyle(t *testing.T) {
+	merged, err := MergeContents("a: [1]\nb: {c: x}", "b: {c: ñ}", WithFormat(format.WithIndent(4), format.WithIndentSequences(true), format.WithEscapeNonASCII(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, "a:\n    - 1\nb:\n    c: \"\\u00F1\"\n", merged)
+}
+
+func TestMergeContentsComments(t *testing.T) {
+	base := `# Service
+service:
+  # Listening port
+  port: 80 # default
+  host: localhost # local
+`
+	changes := `service:
+  port: 8080 # production
+  # Public host
+  host: example.com
+`
+	merged, err := MergeContents(base, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, `# Service
+service:
+  # Public host
+  host: example.com # local
+  # Listening port
+  port: 8080 # production
+`, merged)
+}
+
+func TestMergeContentsDocuments(t *testing.T) {
+	base := "kind: A\nmetadata: {name: a}\nv: 1\n---\nkind: B\nmetadata: {name: b}\nv: 1\n"
+	changes := "kind: B\nmetadata: {name: b}\nv: 2\n---\nkind: C\nmetadata: {name: c}\n"
+	for _, i := range []struct {
+		opts     []Option
+		expected string
+	}{
+		{
+			opts:     nil,
+			expected: "kind: B\nmetadata: {name: b}\nv: 2\n---\nkind: C\nmetadata: {name: c}\nv: 1\n",
+		},
+		{
+			opts:     []Option{WithDocumentStrategy(ByIndex)},
+			expected: "kind: B\nmetadata: {name: b}\nv: 2\n---\nkind: C\nmetadata: {name: c}\nv: 1\n",
+		},
+		{
+			opts:     []Option{WithDocumentStrategy(ByKey)},
+			expected: "kind: A\nmetadata: {name: a}\nv: 1\n---\nkind: B\nmetadata: {name: b}\nv: 2\n---\nkind: C\nmetadata: {name: c}\n",
+		},
+		{
+			opts:     []Option{WithDocumentStrategy(ByKey), WithDocumentKeys("v")},
+			expected: base + "---\n" + changes,
+		},
+		{
+			opts:     []Option{WithDocumentStrategy(Concatenate)},
+			expected: base + "---\n" + changes,
+		},
+		{
+			opts:     []Option{WithDocumentStrategy(Flatten)},
+			expected: "kind: C\nmetadata: {name: c}\nv: 2\n",
+		},
+	} {
+		merged, err := MergeContents(base, changes, i.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, composeAll(t, i.expected), merged)
+	}
+}
+
+func TestMergeContentsAnchors(t *testing.T) {
+	base := `defaults: &defaults
+  timeout: 30
+  resources: &resources
+    cpu: 1
+web:
+  <<: *defaults
+  port: 80
+api:
+  <<: *defaults
+  resources: *resources
+`
+	for name, i := range map[string]struct {
+		changes  string
+		expected string
+	}{
+		"unchanged": {
+			changes:  "web: {port: 80, timeout: 30}\napi: {resources: {cpu: 1}}\n",
+			expected: base,
+		},
+		"change under an alias expands it": {
+			changes: "api: {resources: {mem: 2}}\n",
+			expected: `defaults: &defaults
+  timeout: 30
+  resources: &resources
+    cpu: 1
+web:
+  <<: *defaults
+  port: 80
+api:
+  <<: *defaults
+  resources:
+    cpu: 1
+    mem: 2
+`,
+		},
+		"change under a merge key overrides the key": {
+			changes: "web: {timeout: 60, resources: {mem: 2}}\n",
+			expected: `defaults: &defaults
+  timeout: 30
+  resources: &resources
+    cpu: 1
+web:
+  <<: *defaults
+  port: 80
+  timeout: 60
+  resources:
+    cpu: 1
+    mem: 2
+api:
+  <<: *defaults
+  resources: *resources
+`,
+		},
+		"change under an anchor keeps the aliases": {
+			changes: "defaults: {timeout: 60}\n",
+			expected: `defaults:
+  timeout: 60
+  resources: &resources
+    cpu: 1
+web:
+  <<: &defaults
+    timeout: 30
+    resources:
+      cpu: 1
+  port: 80
+api:
+  <<: *defaults
+  resources: *resources
+`,
+		},
+		"replaced anchor keeps the aliases": {
+			changes: "defaults: null\n",
+			expected: `defaults: null
+web:
+  <<: &defaults
+    timeout: 30
+    resources:
+      cpu: 1
+  port: 80
+api:
+  <<: *defaults
+  resources: &resources
+    cpu: 1
+`,
+		},
+	} {
+		merged, err := MergeContents(base, i.changes, WithFormat(format.WithKeyOrder(format.KeyOrder{Order: format.Source}), format.WithAnchors(format.PreserveAnchors)))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+		// Aliases represent the same data as if expanded before merging
+		expanded, err := MergeContents(base, i.changes)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		formatted, err := format.FormatContent(merged)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		itesting.AssertEqual(t, expanded, formatted)
+	}
+}
+
+func TestMergeContentsRoots(t *testing.T) {
+	for _, i := range []struct {
+		base     string
+		changes  string
+		expected string
+	}{
+		// Lists are replaced, also at root level
+		{
+			base:     "[1, 2]",
+			changes:  "[3]",
+			expected: "- 3\n",
+		},
+		// Roots of different kinds are replaced
+		{
+			base:     "a: 1",
+			changes:  "[3]",
+			expected: "- 3\n",
+		},
+		{
+			base:     "[1, 2]",
+			changes:  "a: 1",
+			expected: "a: 1\n",
+		},
+		{
+			base:     "a: 1",
+			changes:  "text",
+			expected: "text\n",
+		},
+		// Keys of any type are merged
+		{
+			base:     "{1: a, true: b, [1, 2]: {c: 1}, {d: 1}: e}",
+			changes:  "{1: x, '1': y, [1, 2]: {f: 2}, {d: 1}: z}",
+			expected: "true: b\n1: x\n? {d: 1}\n: z\n? [1, 2]\n:\n  c: 1\n  f: 2\n\"1\": \"y\"\n",
+		},
+	} {
+		merged, err := MergeContents(i.base, i.changes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeContentsDirectives(t *testing.T) {
+	for _, i := range []struct {
+		base     string
+		changes  string
+		opts     []Option
+		expected string
+	}{
+		// Replace instead of merging
+		{
+			base:     "a: {x: 1, y: 2}\nb: {x: 1}",
+			changes:  "a: !replace {z: 3}\nb: {$patch: replace, z: 3}",
+			expected: "a:\n  z: 3\nb:\n  z: 3\n",
+		},
+		{
+			base:     "a: [1, 2]",
+			changes:  "a: !replace [3]",
+			opts:     []Option{WithListMerge(ListMerge{Strategy: AppendList})},
+			expected: "a:\n- 3\n",
+		},
+		{
+			base:     "a: 1",
+			changes:  "a: !replace 2",
+			expected: "a: 2\n",
+		},
+		// Delete keys
+		{
+			base:     "a: 1\nb: {c: 1}\nc: 3",
+			changes:  "a: !delete\nb: {$patch: delete}\nd: !delete",
+			expected: "c: 3\n",
+		},
+		{
+			base:     "a: {b: {c: 1, d: 2}}",
+			changes:  "a: {b: {c: !delete null}}",
+			expected: "a:\n  b:\n    d: 2\n",
+		},
+		{
+			base:     "a: 1",
+			changes:  "!delete",
+			expected: "{}\n",
+		},
+		// Delete list items
+		{
+			base:     "a: [{name: x, v: 1}, {name: y, v: 2}]",
+			changes:  "a: [{name: x, $patch: delete}, !delete {name: z}, {name: w}]",
+			opts:     []Option{WithListMerge(ListMerge{Strategy: MergeByKey})},
+			expected: "a:\n- name: \"y\"\n  v: 2\n- name: w\n",
+		},
+		{
+			base:     "a: [x, y, x]",
+			changes:  "a: [!delete x, z]",
+			opts:     []Option{WithListMerge(ListMerge{Strategy: UnionList})},
+			expected: "a:\n- \"y\"\n- z\n",
+		},
+		{
+			base:     "a: [x, y]",
+			changes:  "a: [!delete x, z]",
+			expected: "a:\n- z\n",
+		},
+		// Retain keys
+		{
+			base:     "a: {x: 1, y: 2, z: 3}",
+			changes:  "a: {$retainKeys: [x, w], w: 4}",
+			expected: "a:\n  w: 4\n  x: 1\n",
+		},
+		// Directives are removed from new nodes and from the base
+		{
+			base:     "a: {b: !delete 1, c: !replace 2, $patch: replace}",
+			changes:  "d: {e: !delete null, f: [!delete 1, 2], $retainKeys: [f]}\ng: {$patch: delete}",
+			expected: "a:\n  c: 2\nd:\n  f:\n  - 2\n",
+		},
+		{
+			base:     "{}",
+			changes:  "a: !replace {b: !delete 1, c: [{d: 1, $patch: replace}]}",
+			expected: "a:\n  c:\n  - d: 1\n",
+		},
+		{
+			base:     "a: 1\n---\na: 2",
+			changes:  "a: !delete",
+			opts:     []Option{WithDocumentStrategy(Concatenate)},
+			expected: "a: 1\n---\na: 2\n---\n{}\n",
+		},
+		{
+			base:     "kind: A\nv: !delete 1",
+			changes:  "kind: B\nv: !replace 2",
+			opts:     []Option{WithDocumentStrategy(ByKey), WithDocumentKeys("kind")},
+			expected: "kind: A\n---\nkind: B\nv: 2\n",
+		},
+		// Anchors are kept when directives do not change the data
+		{
+			base:     "a: &a {x: 1}\nb: *a",
+			changes:  "b: {y: !delete null, x: !replace 1, $retainKeys: [x]}",
+			opts:     []Option{WithFormat(format.WithAnchors(format.PreserveAnchors))},
+			expected: "a: &a\n  x: 1\nb: *a\n",
+		},
+		{
+			base:     "a: &a {x: 1, y: 2}\nb: *a\nc: {<<: *a, z: 3}",
+			changes:  "a: {x: !delete null}\nc: {y: !delete null}",
+			opts:     []Option{WithFormat(format.WithAnchors(format.PreserveAnchors))},
+			expected: "a:\n  \"y\": 2\nb: &a\n  x: 1\n  \"y\": 2\nc:\n  x: 1\n  z: 3\n",
+		},
+	} {
+		merged, err := MergeContents(i.base, i.changes, i.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeContentsNullDeletes(t *testing.T) {
+	for _, i := range []struct {
+		base     string
+		changes  string
+		deletes  bool
+		expected string
+	}{
+		// Null replaces by default
+		{
+			base:     "a: {b: 1}\nc: 2",
+			changes:  "a: null\nc: ~",
+			expected: "a: null\nc: null\n",
+		},
+		// Null deletes the key and its subtree
+		{
+			base:     "a: {b: 1}\nc: 2\nd: 3",
+			changes:  "a: null\nc: ~\ne:",
+			deletes:  true,
+			expected: "d: 3\n",
+		},
+		{
+			base:     "a: {b: {c: 1, d: 2}}",
+			changes:  "a: {b: {c: null}}",
+			deletes:  true,
+			expected: "a:\n  b:\n    d: 2\n",
+		},
+		// New mappings do not keep null values, lists are kept as they are
+		{
+			base:     "a: 1",
+			changes:  "a: {b: null, c: {d: null, e: 1}}\nf: {g: null}\nh: [null, {i: null}]",
+			deletes:  true,
+			expected: "a:\n  c:\n    e: 1\nf: {}\nh:\n- null\n- i: null\n",
+		},
+		// Null strings are not null
+		{
+			base:     "a: 1",
+			changes:  "a: 'null'",
+			deletes:  true,
+			expected: "a: \"null\"\n",
+		},
+		// Keys inherited through merge keys are deleted
+		{
+			base:     "x: &x {a: 1, b: 2}\ny: {<<: *x, c: 3}",
+			changes:  "y: {a: null}",
+			deletes:  true,
+			expected: "x:\n  a: 1\n  b: 2\n\"y\":\n  b: 2\n  c: 3\n",
+		},
+	} {
+		merged, err := MergeContents(i.base, i.changes, WithNullDeletes(i.deletes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeContentsDocumentsByKey(t *testing.T) {
+	// Documents without any key are never matched
+	merged, err := MergeContents("a: 1\n---\nkind: A\n", "b: 2\n---\nkind: A\nv: 1\n", WithDocumentStrategy(ByKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, "a: 1\n---\nkind: A\nv: 1\n---\nb: 2\n", merged)
+}
+
+func TestMergeContentsDocumentsInvalid(t *testing.T) {
+	for _, i := range []struct {
+		opts     []Option
+		expected string
+	}{
+		{
+			opts:     []Option{WithDocumentStrategy("random")},
+			expected: "unknown document strategy random",
+		},
+		{
+			opts:     []Option{WithDocumentStrategy(ByKey), WithDocumentKeys("a[")},
+			expected: "invalid path a[",
+		},
+	} {
+		merged, err := MergeContents("a: 1", "a: 2", i.opts...)
+		itesting.AssertError(t, i.expected, err)
+		itesting.AssertEqual(t, "", merged)
 	}
 }
 
@@ -143,14 +589,14 @@ func TestMergeContentsInvalid(t *testing.T) {
 		expected string
 	}{
 		{
-			base:     `;`,
+			base:     `key: value: other`,
 			changes:  `data: 2`,
-			expected: `cannot unmarshal`,
+			expected: `mapping values are not allowed`,
 		},
 		{
 			base:     `data: 1`,
-			changes:  `;`,
-			expected: `cannot unmarshal`,
+			changes:  `key: value: other`,
+			expected: `mapping values are not allowed`,
 		},
 	} {
 		merged, err := MergeContents(i.base, i.changes)
@@ -163,9 +609,9 @@ func TestMergeContentsInvalid(t *testing.T) {
 
 func TestMergeContentsMergeError(t *testing.T) {
 	// Mock merge internal function
-	originalMerge := yaml.Merge
-	defer func() { yaml.Merge = originalMerge }()
-	yaml.Merge = func(base, changes map[string]interface{}) (map[string]interface{}, error) {
+	originalMerge := yaml.MergeNodes
+	defer func() { yaml.MergeNodes = originalMerge }()
+	yaml.MergeNodes = func(base, changes *yaml.Node, opts yaml.MergeOptions) (*yaml.Node, error) {
 		return nil, errors.New("merging error")
 	}
 	merged, err := MergeContents("", "")
@@ -194,17 +640,17 @@ func TestMergeAllContentsInvalid(t *testing.T) {
 			contents: []string{
 				"data: 1",
 				"data: 2",
-				";",
+				"key: value: other",
 			},
-			expected: `cannot unmarshal`,
+			expected: `mapping values are not allowed`,
 		},
 		{
 			contents: []string{
-				";",
+				"key: value: other",
 				"data: 2",
 				"data: 3",
 			},
-			expected: `cannot unmarshal`,
+			expected: `mapping values are not allowed`,
 		},
 	} {
 		merged, err := MergeAllContents(i.contents)
@@ -215,7 +661,7 @@ func TestMergeAllContentsInvalid(t *testing.T) {
 	}
 }
 
-func format(t *testing.T, content string) string {
+func compose(t *testing.T, content string) string {
 	data, err := yaml.Parse(content)
 	if err != nil {
 		t.Fatalf("Error formatting '%s'. %s", content, err)
@@ -227,3 +673,11 @@ func format(t *testing.T, content string) string {
 	}
 	return formatted
 }
+
+func composeAll(t *testing.T, content string) string {
+	var composed []string
+	for _, doc := range strings.Split(content, "---\n") {
+		composed = append(composed, compose(t, doc))
+	}
+	return strings.Join(composed, "---\n")
+}
@@ -30,13 +30,69 @@ SOFTWARE.
 // Primitive value nodes like strings, booleans, integers, etc. are considered
 // leaf nodes and will be replaced on less important leaf nodes.
 //
-// Array nodes are also considered leaf nodes and will be replaced, they will not
-// be merged. Merging "data: [1]" with "data: [2]" will result in "data: [2]",
-// not "data: [1, 2]".
+// Array nodes are also considered leaf nodes and will be replaced by default,
+// they will not be merged. Merging "data: [1]" with "data: [2]" will result in
+// "data: [2]", not "data: [1, 2]". The same applies to the root of a document:
+// a list root replaces the previous root, as does any root of a different kind.
+// Arrays can be appended, prepended, joined without repeated items or merged
+// item by item with the values of a key, for every array or for the arrays in
+// specific paths (see WithListMerge).
 //
 // Leaf nodes can replace map and array nodes entirely if they are more important
 // in the merge hierarchy, replacing a complex node with a primitive value if
 // they are on the same key path.
 //
-// The process of merging also formats the merged yaml (see format).
+// A null value replaces the value of any previous yaml, or deletes its key as in
+// a JSON merge patch (see WithNullDeletes).
+//
+// A strict merge fails with a StrictError listing every value that replaces a
+// value of a different kind and, optionally, every key that is not in the
+// previous yaml (see WithStrict).
+//
+// Protected values cannot be changed by the yaml merged after them, the merge
+// fails with a ProtectedError naming the file that changes one. Values are
+// protected by path (see WithProtectedPaths) or with the !locked tag (see
+// LockedTag), which is removed from the merged yaml.
+//
+// The changes may control the merge with inline directives, which are removed
+// from the merged yaml: a !replace tag (or "$patch: replace") replaces the value
+// instead of merging it, a !delete tag (or "$patch: delete") deletes the key or
+// the matching list items and a "$retainKeys" list keeps only the listed keys
+// of a mapping.
+//
+// Environment variables can be merged after every yaml as the most important
+// values, like APP__DB__HOST setting db.host (see WithEnv and Env). Values can
+// be set in paths as the last layer, like the --set flags of Helm (see WithSet).
+//
+// The origin of the merged values (file and line) can be written as comments
+// (see WithExplain) or returned as a Provenance with the merged documents (see
+// MergeAllFilesWithProvenance), which includes every yaml that set each value.
+//
+// The yaml of a markdown file (.md or .markdown) is its front matter. Json and
+// toml files and contents are merged as yaml, their format is detected by the
+// file extension or the content (see format.DetectFormat). The merged yaml can
+// be written as json or toml with format.WithOutputFormat.
+//
+// MergeThreeWay merges the changes made by two yaml (ours and theirs) to a
+// common base, reporting the values changed differently by both as conflicts.
+//
+// ListFiles expands directories (like a conf.d directory) and glob patterns
+// into the files to merge, in a deterministic order (see FileOrder).
+//
+// The documents of multi-document streams are merged following a
+// DocumentStrategy: by position, by the values of identifying keys,
+// concatenated or flattened into a single document.
+//
+// The process of merging also formats the merged yaml (see format), with the
+// format options passed with WithFormat. Comments are kept, a comment in a more
+// important yaml replaces the comment of the same node in a less important one.
+// When keys are kept in source order, the keys of the least important yaml go
+// first and new keys are added after them.
+//
+// When anchors are preserved (see format.WithAnchors) the merged yaml is the
+// same data as merging the yaml with its aliases expanded. The anchors, aliases
+// and merge keys of the least important yaml are kept while they still
+// represent that data: a change under an alias expands it, and a change under
+// an anchored node makes its first alias take the anchor with the original
+// data. Anchors in the rest of yaml are expanded.
 package merge
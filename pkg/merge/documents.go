@@ -0,0 +1,169 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// DocumentStrategy is the strategy to merge the documents of multi-document
+// yaml streams.
+type DocumentStrategy string
+
+const (
+	// ByIndex merges each document with the document in the same position of
+	// the previous contents. Documents without counterpart are added at the end.
+	ByIndex DocumentStrategy = "index"
+	// ByKey merges each document with the first document of the previous
+	// contents with the same values in the document keys (see
+	// WithDocumentKeys). Documents without counterpart, or without any of the
+	// keys, are added at the end.
+	ByKey DocumentStrategy = "key"
+	// Concatenate does not merge documents, the documents of every content are
+	// written one after the other.
+	Concatenate DocumentStrategy = "concat"
+	// Flatten merges every document of every content, in order, into a single
+	// document.
+	Flatten DocumentStrategy = "flatten"
+)
+
+// DocumentStrategies are the valid document strategies.
+var DocumentStrategies = []DocumentStrategy{ByIndex, ByKey, Concatenate, Flatten}
+
+// Validate returns an error if the document strategy is unknown.
+func (s DocumentStrategy) Validate() error {
+	if s == "" {
+		return nil
+	}
+	for _, valid := range DocumentStrategies {
+		if s == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown document strategy %s, valid strategies are %v", s, DocumentStrategies)
+}
+
+// DefaultDocumentKeys are the keys that identify a document with the ByKey
+// strategy if none are configured, they identify kubernetes resources.
+var DefaultDocumentKeys = []string{"kind", "metadata.name"}
+
+// mergeStreams merges the documents of yaml streams, ordered in ascending level
+// of importance, following the document strategy with the merge options.
+func mergeStreams(streams [][]*yaml.Node, o *options, mo yaml.MergeOptions) ([]*yaml.Node, error) {
+	switch o.documents {
+	case "", ByIndex:
+		return mergeStreamsBy(streams, mo, func(merged []*yaml.Node, i int, doc *yaml.Node) int {
+			return i
+		})
+	case ByKey:
+		keys, err := o.documentPaths()
+		if err != nil {
+			return nil, err
+		}
+		return mergeStreamsBy(streams, mo, func(merged []*yaml.Node, i int, doc *yaml.Node) int {
+			id, ok := identity(doc, keys)
+			if !ok {
+				return -1
+			}
+			for j, m := range merged {
+				if mid, ok := identity(m, keys); ok && mid == id {
+					return j
+				}
+			}
+			return -1
+		})
+	case Concatenate:
+		var merged []*yaml.Node
+		for _, docs := range streams {
+			for _, doc := range docs {
+				yaml.StripDirectives(doc)
+			}
+			merged = append(merged, docs...)
+		}
+		return merged, nil
+	case Flatten:
+		var all []*yaml.Node
+		for _, docs := range streams {
+			all = append(all, docs...)
+		}
+		merged, err := yaml.Flatten(all, mo)
+		if err != nil {
+			return nil, err
+		}
+		return []*yaml.Node{merged}, nil
+	}
+	return nil, o.documents.Validate()
+}
+
+// mergeStreamsBy merges each document of a stream with the merged document
+// returned by match, which receives the merged documents, the position of the
+// document in its stream and the document. A negative or out of range match
+// adds the document at the end.
+func mergeStreamsBy(streams [][]*yaml.Node, mo yaml.MergeOptions, match func(merged []*yaml.Node, i int, doc *yaml.Node) int) ([]*yaml.Node, error) {
+	merged := streams[0]
+	for _, doc := range merged {
+		yaml.StripDirectives(doc)
+	}
+	for _, docs := range streams[1:] {
+		previous := merged
+		for i, doc := range docs {
+			j := match(previous, i, doc)
+			if j < 0 || j >= len(previous) {
+				yaml.StripDirectives(doc)
+				merged = append(merged, doc)
+				continue
+			}
+			m, err := yaml.MergeNodes(merged[j], doc, mo)
+			if err != nil {
+				return nil, err
+			}
+			merged[j] = m
+		}
+	}
+	return merged, nil
+}
+
+// identity returns the values of the keys of a document and whether the
+// document has any of them.
+func identity(doc *yaml.Node, keys []path.Path) (string, bool) {
+	values := make([]string, len(keys))
+	found := false
+	for i, key := range keys {
+		n := yaml.Lookup(doc, key)
+		switch {
+		case n == nil:
+			values[i] = "\x00"
+		case n.Kind == yaml.ScalarNode:
+			values[i] = n.ShortTag() + " " + n.Value
+			found = true
+		default:
+			values[i] = fmt.Sprintf("\x01%d", n.Kind)
+			found = true
+		}
+	}
+	return strings.Join(values, "\n"), found
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"os"
+
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// DefaultEnvSeparator is the default separator of the keys in the names of the
+// environment variables of an overlay.
+const DefaultEnvSeparator = yaml.DefaultEnvSeparator
+
+// Env configures the overlay of environment variables, merged after every
+// content as the most important yaml. A variable named with the prefix followed
+// by the separator, like APP__DB__HOST with prefix APP, sets the value of the
+// path of the rest of its name (db.host).
+//
+// The keys of the path are matched ignoring case with the keys of the merged
+// yaml, new keys are written in lower case. The type of the value is inferred
+// as in a yaml scalar (8080 is an int, true a bool...), a value in yaml flow
+// style is a list or a mapping and any other value is a string. Lists are set
+// as a whole.
+type Env struct {
+	Prefix    string // Prefix of the variables (no overlay if empty)
+	Separator string // Separator of the keys (DefaultEnvSeparator if empty)
+}
+
+// enabled returns whether the overlay is enabled.
+func (e Env) enabled() bool {
+	return e.Prefix != ""
+}
+
+// variables returns the environment variables of the overlay.
+func (e Env) variables() []yaml.EnvVariable {
+	return yaml.ParseEnv(os.Environ(), e.Prefix, e.Separator)
+}
+
+// overlay merges the environment variables into a merged document, in order of
+// name. They are named $NAME in the tracker (if any), without line.
+func (e Env) overlay(doc *yaml.Node, mo yaml.MergeOptions) (*yaml.Node, error) {
+	for _, v := range e.variables() {
+		changes := v.Document(doc)
+		if mo.Tracker != nil {
+			mo.Tracker.Read(changes, "$"+v.Name, 0)
+		}
+		var err error
+		if doc, err = yaml.MergeNodes(doc, changes, mo); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
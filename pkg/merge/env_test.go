@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestMergeContentsEnv(t *testing.T) {
+	base := `db:
+  Host: localhost # db host
+  port: 5432
+ports: [80]
+name: app
+`
+	t.Setenv("APP__DB__HOST", "db.prod")
+	t.Setenv("APP__DB__PORT", "6543")
+	t.Setenv("APP__DEBUG", "true")
+	t.Setenv("APP__PORTS", "[80, 443]")
+	t.Setenv("APP__VERSION", `"1.10"`)
+	t.Setenv("APP__NOTE", "a # b")
+	t.Setenv("APP__LIMITS__CPU", "1.5")
+	t.Setenv("OTHER__NAME", "other")
+	t.Setenv("APP_NAME", "other")
+	merged, err := MergeContents(base, "name: prod\n", WithEnv(Env{Prefix: "APP"}))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, `db:
+  Host: db.prod # db host
+  port: 6543
+debug: true
+limits:
+  cpu: 1.5
+name: prod
+note: 'a # b'
+ports:
+- 80
+- 443
+version: "1.10"
+`, merged)
+}
+
+func TestMergeContentsEnvSeparator(t *testing.T) {
+	t.Setenv("APP_DB_PORT", "1")
+	t.Setenv("APP__DB__PORT", "2")
+	merged, err := MergeAllContents([]string{"db:\n  port: 0\n"}, WithEnv(Env{Prefix: "APP", Separator: "_"}))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "db:\n  port: 1\n", merged)
+}
+
+func TestMergeContentsEnvDisabled(t *testing.T) {
+	t.Setenv("APP__A", "2")
+	merged, err := MergeContents("a: 0\n", "b: 1\n", WithEnv(Env{}))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 0\nb: 1\n", merged)
+	_, err = MergeAllContents([]string{"a: 0\n"}, WithEnv(Env{}))
+	itesting.AssertError(t, "slice must contain at least two contents", err)
+}
+
+func TestMergeContentsEnvChecks(t *testing.T) {
+	t.Setenv("APP__IMAGE__REGISTRY", "docker.io")
+	t.Setenv("APP__EXTRA", "1")
+	_, err := MergeAllContents([]string{"image:\n  registry: example.com\n"}, WithEnv(Env{Prefix: "APP"}), WithStrict(Strict{UnknownKeys: true}))
+	itesting.AssertError(t, "$APP__EXTRA: extra: key not found in the previous files", err)
+	_, err = MergeAllContents([]string{"image:\n  registry: example.com\n"}, WithEnv(Env{Prefix: "APP"}), WithProtectedPaths("image.registry"))
+	itesting.AssertError(t, "$APP__IMAGE__REGISTRY: protected path image.registry cannot be changed", err)
+}
+
+func TestMergeAllContentsWithProvenanceEnv(t *testing.T) {
+	t.Setenv("APP__A", "2")
+	merged, err := MergeAllContentsWithProvenance([]string{"base.yml"}, []string{"a: 1\n"}, WithEnv(Env{Prefix: "APP"}))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "base.yml", Line: 1, Column: 4, Value: "1"},
+		{File: "$APP__A", Value: "2"},
+	}, merged[0].Provenance["a"])
+	explained, err := MergeAllContents([]string{"a: 1\n"}, WithEnv(Env{Prefix: "APP"}), WithExplain(true))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 2 # $APP__A\n", explained)
+}
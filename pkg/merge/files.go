@@ -26,41 +26,36 @@ import (
 	"errors"
 
 	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/markdown"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
 )
 
 // MergeFiles returns the result of merging two yaml files. A yaml leaf node in
 // the 'changes' file takes precedence over and replaces the value in the 'base'
 // file.
-func MergeFiles(base string, changes string) (string, error) {
-	baseContent, err := io.ReadAsString(base)
+func MergeFiles(base string, changes string, opts ...Option) (string, error) {
+	sources, err := fileSources([]string{base, changes})
 	if err != nil {
 		return "", err
 	}
-	changesContent, err := io.ReadAsString(changes)
-	if err != nil {
-		return "", err
-	}
-	return MergeContents(baseContent, changesContent)
+	return mergeSources(sources, newOptions(opts))
 }
 
 // MergeAllFiles returns the result of merging all yaml files, which should be
 // ordered in ascending level of importance in the hierarchy. A yaml leaf node in
 // the last file takes precedence over and replaces the value in any previous
 // file.
-func MergeAllFiles(files []string) (string, error) {
-	if len(files) < 2 {
+func MergeAllFiles(files []string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	if len(files) < o.minSources() {
 		return "", errors.New("slice must contain at least two files")
 	}
-	contents := make([]string, len(files))
-
-	for i, f := range files {
-		var err error
-		contents[i], err = io.ReadAsString(f)
-		if err != nil {
-			return "", err
-		}
+	sources, err := fileSources(files)
+	if err != nil {
+		return "", err
 	}
-	return MergeAllContents(contents)
+	return mergeSources(sources, o)
 }
 
 // MergeStdinWithFiles returns the result of merging stdin as yaml content with
@@ -68,36 +63,81 @@ func MergeAllFiles(files []string) (string, error) {
 // the hierarchy. Stdin is the least important yaml. A yaml leaf node in the last
 // file takes precedence over and replaces the value in any previous file,
 // including values in stdin.
-func MergeStdinWithFiles(files []string) (string, error) {
+func MergeStdinWithFiles(files []string, opts ...Option) (string, error) {
 	if len(files) < 1 {
 		return "", errors.New("slice must contain at least one file")
 	}
-	var err error
-
-	contents := make([]string, len(files)+1)
-
-	contents[0], err = io.ReadStdin()
+	stdin, err := io.ReadStdin()
 	if err != nil {
 		return "", err
 	}
-
-	for i, f := range files {
-		contents[i+1], err = io.ReadAsString(f)
-		if err != nil {
-			return "", err
-		}
+	sources, err := fileSources(files)
+	if err != nil {
+		return "", err
 	}
-	return MergeAllContents(contents)
+	sources = append([]source{{name: format.StdinName, content: stdin, format: format.DetectFormat("", stdin)}}, sources...)
+	return mergeSources(sources, newOptions(opts))
 }
 
 // MergeAllFilesToFile writes to an output file the result of merging all yaml
 // files, which should be ordered in ascending level of importance in the
 // hierarchy. A yaml leaf node in the last file takes precedence over and
 // replaces the value in any previous file.
-func MergeAllFilesToFile(files []string, output string) error {
-	merged, err := MergeAllFiles(files)
+func MergeAllFilesToFile(files []string, output string, opts ...Option) error {
+	merged, err := MergeAllFiles(files, opts...)
 	if err != nil {
 		return err
 	}
 	return io.WriteToFile(output, merged)
 }
+
+// MergeAllFilesToDocuments returns the documents (not formatted) resulting of
+// merging all yaml files like MergeAllFiles. A single file is returned as read,
+// with the environment variables and values set (if any).
+func MergeAllFilesToDocuments(files []string, opts ...Option) ([]*yaml.Node, error) {
+	if len(files) < 1 {
+		return nil, errors.New("slice must contain at least one file")
+	}
+	sources, err := fileSources(files)
+	if err != nil {
+		return nil, err
+	}
+	return mergeDocuments(sources, newOptions(opts))
+}
+
+// MergeStdinWithFilesToDocuments returns the documents (not formatted)
+// resulting of merging stdin with all yaml files like MergeStdinWithFiles.
+// Without files stdin is returned as read, with the environment variables and
+// values set (if any).
+func MergeStdinWithFilesToDocuments(files []string, opts ...Option) ([]*yaml.Node, error) {
+	stdin, err := io.ReadStdin()
+	if err != nil {
+		return nil, err
+	}
+	sources, err := fileSources(files)
+	if err != nil {
+		return nil, err
+	}
+	sources = append([]source{{name: format.StdinName, content: stdin, format: format.DetectFormat("", stdin)}}, sources...)
+	return mergeDocuments(sources, newOptions(opts))
+}
+
+// fileSources reads the yaml of files, detecting the format of json and toml
+// files. The yaml of a markdown file is its front matter, which starts in the
+// second line.
+func fileSources(files []string) ([]source, error) {
+	sources := make([]source, len(files))
+	for i, f := range files {
+		content, err := markdown.ReadYAML(f)
+		if err != nil {
+			return nil, err
+		}
+		sources[i] = source{name: f, content: content, format: format.YAML}
+		if markdown.IsMarkdown(f) {
+			sources[i].offset = 1
+		} else {
+			sources[i].format = format.DetectFormat(f, content)
+		}
+	}
+	return sources, nil
+}
@@ -32,6 +32,7 @@ import (
 
 	"github.com/amplia-iiot/yutil/internal/io"
 	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/amplia-iiot/yutil/pkg/format"
 )
 
 func init() {
@@ -64,7 +65,7 @@ func TestMergeFiles(t *testing.T) {
 			t.Fatal(err)
 		}
 		expectedContent := itesting.ReadFile(t, expectedFile([]string{i.base, i.changes}))
-		itesting.AssertEqual(t, format(t, expectedContent), merged)
+		itesting.AssertEqual(t, compose(t, expectedContent), merged)
 	}
 }
 
@@ -80,7 +81,7 @@ func TestMergeAllFiles(t *testing.T) {
 			t.Fatal(err)
 		}
 		expectedContent := itesting.ReadFile(t, expectedFile(files))
-		itesting.AssertEqual(t, format(t, expectedContent), merged)
+		itesting.AssertEqual(t, compose(t, expectedContent), merged)
 	}
 }
 
@@ -94,12 +95,12 @@ func TestMergeFilesInvalid(t *testing.T) {
 		{
 			base:     "invalid",
 			changes:  "dev",
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		{
 			base:     "base",
 			changes:  "invalid",
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -138,19 +139,19 @@ func TestMergeAllFilesInvalid(t *testing.T) {
 		// Parsing error
 		{
 			files:    []string{"base", "invalid"},
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		{
 			files:    []string{"invalid", "prod"},
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		{
 			files:    []string{"invalid", "prod", "docker"},
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		{
 			files:    []string{"base", "prod", "invalid"},
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -263,9 +264,9 @@ func TestMergeStdinWithFilesInvalid(t *testing.T) {
 		},
 		// Parsing error
 		{
-			stdin:    ";",
+			stdin:    "key: value: other",
 			files:    []string{"base"},
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -312,7 +313,7 @@ func TestMergeAllFilesToFile(t *testing.T) {
 		}
 		expectedContent := itesting.ReadFile(t, expectedFile(files))
 		mergedContent := itesting.ReadFile(t, tmpPath)
-		itesting.AssertEqual(t, format(t, expectedContent), mergedContent)
+		itesting.AssertEqual(t, compose(t, expectedContent), mergedContent)
 	}
 }
 
@@ -333,7 +334,7 @@ func TestMergeAllFilesToFileInvalid(t *testing.T) {
 		// Parsing error
 		{
 			files:    []string{"base", "invalid"},
-			expected: "cannot unmarshal",
+			expected: "mapping values are not allowed",
 		},
 		// Not exists
 		{
@@ -367,3 +368,57 @@ func filesToBeMerged(files []string) []string {
 	}
 	return completeFiles
 }
+
+func TestMergeFilesMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	doc := itesting.WriteFile(t, dir, "doc.md", "---\ntitle: Doc\ndraft: true\n---\n# Doc\n")
+	merged, err := MergeFiles(doc, fileToBeMerged("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := MergeContents("title: Doc\ndraft: true\n", itesting.ReadFile(t, fileToBeMerged("dev")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, expected, merged)
+	// Markdown files without front matter can not be merged
+	empty := itesting.WriteFile(t, dir, "empty.md", "# Doc\n")
+	_, err = MergeFiles(empty, fileToBeMerged("dev"))
+	itesting.AssertError(t, "empty.md has no front matter", err)
+}
+
+func TestMergeFilesFormats(t *testing.T) {
+	dir := t.TempDir()
+	base := itesting.WriteFile(t, dir, "base.json", `{"name": "svc", "svc": {"port": 80, "host": "localhost"}}`)
+	overrides := itesting.WriteFile(t, dir, "overrides.toml", "[svc]\nport = 8080\n")
+	local := itesting.WriteFile(t, dir, "local", "name: local\n")
+	merged, err := MergeAllFiles([]string{base, overrides, local})
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "name: local\nsvc:\n  host: localhost\n  port: 8080\n", merged)
+	merged, err = MergeAllFiles([]string{base, overrides}, WithFormat(format.WithOutputFormat(format.JSON)))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "{\n  \"name\": \"svc\",\n  \"svc\": {\n    \"host\": \"localhost\",\n    \"port\": 8080\n  }\n}\n", merged)
+	// The format of a file is detected from its content without extension
+	merged, err = MergeAllContents([]string{`{"a": 1}`, "a = 2\n"})
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 2\n", merged)
+}
+
+func TestMergeAllFilesToDocuments(t *testing.T) {
+	docs, err := MergeAllFilesToDocuments([]string{fileToBeMerged("base")})
+	itesting.AssertEqual(t, nil, err)
+	formatted, err := format.FormatDocuments(docs)
+	itesting.AssertEqual(t, nil, err)
+	expected, err := format.FormatFile(fileToBeMerged("base"))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, expected, formatted)
+	docs, err = MergeAllFilesToDocuments(filesToBeMerged([]string{"base", "dev"}))
+	itesting.AssertEqual(t, nil, err)
+	formatted, err = format.FormatDocuments(docs)
+	itesting.AssertEqual(t, nil, err)
+	expected, err = MergeAllFiles(filesToBeMerged([]string{"base", "dev"}))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, expected, formatted)
+	_, err = MergeAllFilesToDocuments(nil)
+	itesting.AssertError(t, "slice must contain at least one file", err)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+)
+
+// FileOrder is the order of the files found in a directory or matching a glob
+// pattern, which are merged in ascending level of importance.
+type FileOrder string
+
+const (
+	// LexicalOrder orders the files by path, byte by byte: "10-a.yml" goes
+	// before "9-b.yml".
+	LexicalOrder FileOrder = "lexical"
+	// NaturalOrder orders the files by path, comparing the numbers in them by
+	// value: "9-b.yml" goes before "10-a.yml".
+	NaturalOrder FileOrder = "natural"
+	// ExplicitOrder orders the files by the first name pattern (see FileList)
+	// they match, the files that match none go after them. Files that match
+	// the same pattern are ordered lexically.
+	ExplicitOrder FileOrder = "explicit"
+)
+
+// FileOrders are the valid file orders.
+var FileOrders = []FileOrder{LexicalOrder, NaturalOrder, ExplicitOrder}
+
+// DefaultFileInclude are the glob patterns of the files merged from
+// directories when no include pattern is given.
+var DefaultFileInclude = []string{"*.yml", "*.yaml"}
+
+// FileList configures how directories and glob patterns are expanded into the
+// files to merge.
+type FileList struct {
+	Order   FileOrder // Order of the files of each directory or pattern (LexicalOrder if empty)
+	Names   []string  // Glob patterns of the file names, in order, with ExplicitOrder
+	Include []string  // Glob patterns of the files to include (DefaultFileInclude if empty)
+	Exclude []string  // Glob patterns of the files to exclude (takes precedence over Include)
+}
+
+// Validate returns an error if the file order is unknown or there are no name
+// patterns with ExplicitOrder.
+func (l FileList) Validate() error {
+	switch l.Order {
+	case "", LexicalOrder, NaturalOrder:
+		return nil
+	case ExplicitOrder:
+		if len(l.Names) == 0 {
+			return errors.New("the explicit file order requires the patterns of the file names in order")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown file order %s, valid orders are %v", l.Order, FileOrders)
+}
+
+// ListFiles returns the files to merge from a list of files, directories and
+// glob patterns, in the given order. Files are returned as given, even if they
+// do not exist. Directories are walked recursively and glob patterns are
+// expanded, returning the files that match any include pattern and no exclude
+// pattern (matched against both the file name and its path) in the file order.
+// It is an error if a directory or a glob pattern has no files.
+func ListFiles(paths []string, list FileList) ([]string, error) {
+	if err := list.Validate(); err != nil {
+		return nil, err
+	}
+	include := list.Include
+	if len(include) == 0 {
+		include = DefaultFileInclude
+	}
+	var files []string
+	for _, p := range paths {
+		found, err := expand(p, include, list.Exclude)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			files = append(files, p)
+			continue
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no files to merge in %s", p)
+		}
+		list.sort(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// expand returns the included files of a directory or glob pattern, nil if the
+// path is neither.
+func expand(p string, include []string, exclude []string) ([]string, error) {
+	if info, err := os.Stat(p); err == nil {
+		if !info.IsDir() {
+			return nil, nil
+		}
+		return listed(io.ListFiles(p, include, exclude))
+	}
+	if !strings.ContainsAny(p, "*?[") {
+		return nil, nil
+	}
+	matches, err := filepath.Glob(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %s: %w", p, err)
+	}
+	found := []string{}
+	for _, match := range matches {
+		files, err := io.ListFiles(match, include, exclude)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, files...)
+	}
+	return found, nil
+}
+
+// listed returns the listed files, never nil without error.
+func listed(files []string, err error) ([]string, error) {
+	if files == nil && err == nil {
+		files = []string{}
+	}
+	return files, err
+}
+
+// sort orders files in the file order.
+func (l FileList) sort(files []string) {
+	less := func(i, j int) bool {
+		return files[i] < files[j]
+	}
+	switch l.Order {
+	case NaturalOrder:
+		less = func(i, j int) bool {
+			return naturalLess(files[i], files[j])
+		}
+	case ExplicitOrder:
+		less = func(i, j int) bool {
+			ri, rj := l.rank(files[i]), l.rank(files[j])
+			if ri != rj {
+				return ri < rj
+			}
+			return files[i] < files[j]
+		}
+	}
+	sort.SliceStable(files, less)
+}
+
+// rank returns the position of the first name pattern a file matches, the
+// number of patterns if it matches none.
+func (l FileList) rank(file string) int {
+	for i, pattern := range l.Names {
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return i
+		}
+	}
+	return len(l.Names)
+}
+
+// naturalLess compares two strings comparing the sequences of digits in them
+// by their value, the shorter sequence goes first if they have the same value.
+func naturalLess(a string, b string) bool {
+	for a != "" && b != "" {
+		da, db := digits(a), digits(b)
+		if da == 0 || db == 0 {
+			if a[0] != b[0] {
+				return a[0] < b[0]
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		na, nb := strings.TrimLeft(a[:da], "0"), strings.TrimLeft(b[:db], "0")
+		if len(na) != len(nb) {
+			return len(na) < len(nb)
+		}
+		if na != nb {
+			return na < nb
+		}
+		if da != db {
+			return da < db
+		}
+		a, b = a[da:], b[db:]
+	}
+	return len(a) < len(b)
+}
+
+// digits returns the number of digits at the start of a string.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"path/filepath"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+// confDir creates a directory with empty files and returns its path.
+func confDir(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+	for _, f := range files {
+		itesting.WriteFile(t, dir, f, "")
+	}
+	return dir
+}
+
+func TestListFiles(t *testing.T) {
+	dir := confDir(t, "conf.d/10-base.yml", "conf.d/9-team.yaml", "conf.d/50-local.yml", "conf.d/README.md", "conf.d/sub/90-extra.yml", "other.yml")
+	for _, i := range []struct {
+		paths    []string
+		list     FileList
+		expected []string
+	}{
+		{
+			paths:    []string{"conf.d"},
+			expected: []string{"conf.d/10-base.yml", "conf.d/50-local.yml", "conf.d/9-team.yaml", "conf.d/sub/90-extra.yml"},
+		},
+		{
+			paths:    []string{"conf.d"},
+			list:     FileList{Order: NaturalOrder},
+			expected: []string{"conf.d/9-team.yaml", "conf.d/10-base.yml", "conf.d/50-local.yml", "conf.d/sub/90-extra.yml"},
+		},
+		{
+			paths:    []string{"conf.d"},
+			list:     FileList{Order: ExplicitOrder, Names: []string{"*-team.*", "*-base.*"}},
+			expected: []string{"conf.d/9-team.yaml", "conf.d/10-base.yml", "conf.d/50-local.yml", "conf.d/sub/90-extra.yml"},
+		},
+		// Files are kept as given, directories and patterns are expanded in place
+		{
+			paths:    []string{"other.yml", "conf.d/*.yml", "missing.yml"},
+			list:     FileList{Order: NaturalOrder},
+			expected: []string{"other.yml", "conf.d/10-base.yml", "conf.d/50-local.yml", "missing.yml"},
+		},
+		// Include and exclude filters
+		{
+			paths:    []string{"conf.d"},
+			list:     FileList{Include: []string{"*.md", "*-base.yml"}},
+			expected: []string{"conf.d/10-base.yml", "conf.d/README.md"},
+		},
+		{
+			paths:    []string{"conf.d", "conf.d/*"},
+			list:     FileList{Exclude: []string{"*/sub/*", "*local*"}},
+			expected: []string{"conf.d/10-base.yml", "conf.d/9-team.yaml", "conf.d/10-base.yml", "conf.d/9-team.yaml"},
+		},
+	} {
+		var paths []string
+		for _, p := range i.paths {
+			paths = append(paths, filepath.Join(dir, p))
+		}
+		var expected []string
+		for _, e := range i.expected {
+			expected = append(expected, filepath.Join(dir, e))
+		}
+		files, err := ListFiles(paths, i.list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertDeepEqual(t, expected, files)
+	}
+}
+
+func TestListFilesError(t *testing.T) {
+	dir := confDir(t, "conf.d/README.md")
+	for _, i := range []struct {
+		paths []string
+		list  FileList
+		err   string
+	}{
+		{
+			paths: []string{filepath.Join(dir, "conf.d")},
+			err:   "no files to merge in " + filepath.Join(dir, "conf.d"),
+		},
+		{
+			paths: []string{filepath.Join(dir, "*.yml")},
+			err:   "no files to merge in " + filepath.Join(dir, "*.yml"),
+		},
+		{
+			paths: []string{"[a"},
+			err:   "invalid glob pattern [a",
+		},
+		{
+			list: FileList{Order: "random"},
+			err:  "unknown file order random, valid orders are [lexical natural explicit]",
+		},
+		{
+			list: FileList{Order: ExplicitOrder},
+			err:  "the explicit file order requires the patterns of the file names in order",
+		},
+	} {
+		_, err := ListFiles(i.paths, i.list)
+		itesting.AssertError(t, i.err, err)
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	for _, i := range []struct {
+		a, b string
+		less bool
+	}{
+		{"9-a.yml", "10-a.yml", true},
+		{"10-a.yml", "9-a.yml", false},
+		{"a2", "a10", true},
+		{"a02", "a2", false},
+		{"a2", "a02", true},
+		{"a2b", "a2c", true},
+		{"a", "a1", true},
+		{"a1", "a1", false},
+	} {
+		itesting.AssertEqual(t, i.less, naturalLess(i.a, i.b))
+	}
+}
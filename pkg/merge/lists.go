@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// ListStrategy is the strategy to merge a list with the list in the same path
+// of the previous contents.
+type ListStrategy string
+
+const (
+	// ReplaceList replaces the previous list.
+	ReplaceList ListStrategy = "replace"
+	// AppendList adds the items after the items of the previous list.
+	AppendList ListStrategy = "append"
+	// PrependList adds the items before the items of the previous list.
+	PrependList ListStrategy = "prepend"
+	// UnionList adds the items that are not in the previous list, nor
+	// repeated, after its items.
+	UnionList ListStrategy = "union"
+	// MergeByKey merges each item with the item of the previous list with the
+	// same value in the key (see ListMerge). The rest of the items are added
+	// after the items of the previous list.
+	MergeByKey ListStrategy = "key"
+)
+
+// ListStrategies are the valid list strategies.
+var ListStrategies = []ListStrategy{ReplaceList, AppendList, PrependList, UnionList, MergeByKey}
+
+// DefaultListKey is the key that identifies the items of a list with the
+// MergeByKey strategy if none is configured.
+const DefaultListKey = "name"
+
+// ListMerge is the policy to merge lists. Every list is merged with the
+// Strategy (ReplaceList if empty) unless a path policy matches it.
+type ListMerge struct {
+	Strategy ListStrategy    // Strategy of the lists
+	Key      string          // Path of the key identifying the items with MergeByKey (DefaultListKey if empty)
+	Paths    []PathListMerge // Policies for specific paths
+}
+
+// PathListMerge overrides the list strategy of the lists in a path.
+//
+// Paths follow the same syntax as the format paths (see format.PathKeyOrder),
+// for example "spec.template.spec.containers[*].env". When several paths
+// match a list the one with less wildcards is used, then the first declared.
+type PathListMerge struct {
+	Path     string       // Path of the lists
+	Strategy ListStrategy // Strategy of the lists (policy strategy if empty)
+	Key      string       // Path of the key identifying the items (policy key if empty)
+}
+
+// Validate returns an error if the list strategy is unknown.
+func (s ListStrategy) Validate() error {
+	if s == "" {
+		return nil
+	}
+	for _, valid := range ListStrategies {
+		if s == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown list strategy %s, valid strategies are %v", s, ListStrategies)
+}
+
+// internal returns the internal list strategy.
+func (s ListStrategy) internal() yaml.ListStrategy {
+	switch s {
+	case AppendList:
+		return yaml.AppendList
+	case PrependList:
+		return yaml.PrependList
+	case UnionList:
+		return yaml.UnionList
+	case MergeByKey:
+		return yaml.MergeListByKey
+	}
+	return yaml.ReplaceList
+}
+
+// Validate returns an error if the policy contains an unknown strategy or an
+// invalid path or key.
+func (l ListMerge) Validate() error {
+	_, err := l.lists()
+	return err
+}
+
+// lists validates the policy returning the internal list merge, nil if every
+// list is replaced.
+func (l ListMerge) lists() (yaml.ListMerge, error) {
+	if err := l.Strategy.Validate(); err != nil {
+		return nil, err
+	}
+	key, err := listKey(l.Key, DefaultListKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.Paths) == 0 && l.Strategy.internal() == yaml.ReplaceList {
+		return nil, nil
+	}
+	paths := make([]path.Path, len(l.Paths))
+	keys := make([]path.Path, len(l.Paths))
+	for i, p := range l.Paths {
+		if paths[i], err = path.Parse(p.Path); err != nil {
+			return nil, err
+		}
+		if err = p.Strategy.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Path, err)
+		}
+		if keys[i], err = listKey(p.Key, l.Key, DefaultListKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Path, err)
+		}
+	}
+	return func(concrete path.Path) (yaml.ListStrategy, path.Path) {
+		best := -1
+		for i, p := range paths {
+			if p.Match(concrete) && (best < 0 || p.Wildcards() < paths[best].Wildcards()) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return l.Strategy.internal(), key
+		}
+		strategy := l.Paths[best].Strategy
+		if strategy == "" {
+			strategy = l.Strategy
+		}
+		return strategy.internal(), keys[best]
+	}, nil
+}
+
+// listKey parses the path of the first list key that is not empty.
+func listKey(keys ...string) (path.Path, error) {
+	key := firstNonEmpty(keys...)
+	p, err := path.Parse(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list key: %w", err)
+	}
+	if len(p) == 0 || p.Wildcards() > 0 {
+		return nil, fmt.Errorf("invalid list key %s, it must be a path without wildcards", key)
+	}
+	return p, nil
+}
+
+// firstNonEmpty returns the first string that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
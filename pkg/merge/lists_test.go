@@ -0,0 +1,179 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+func TestMergeContentsLists(t *testing.T) {
+	for _, i := range []struct {
+		base     string
+		changes  string
+		policy   ListMerge
+		expected string
+	}{
+		// Replaced by default
+		{
+			base:     "a: [1, 2]",
+			changes:  "a: [2, 3]",
+			expected: "a:\n- 2\n- 3\n",
+		},
+		{
+			base:     "a: [1, 2]",
+			changes:  "a: [2, 3]",
+			policy:   ListMerge{Strategy: ReplaceList},
+			expected: "a:\n- 2\n- 3\n",
+		},
+		// Global strategies
+		{
+			base:     "a: [1, 2]",
+			changes:  "a: [2, 3]",
+			policy:   ListMerge{Strategy: AppendList},
+			expected: "a:\n- 1\n- 2\n- 2\n- 3\n",
+		},
+		{
+			base:     "a: [1, 2]",
+			changes:  "a: [2, 3]",
+			policy:   ListMerge{Strategy: PrependList},
+			expected: "a:\n- 2\n- 3\n- 1\n- 2\n",
+		},
+		{
+			base:     "a: [1, 2, {b: 1}]",
+			changes:  "a: [2, 3, 3, {b: 1}, '1']",
+			policy:   ListMerge{Strategy: UnionList},
+			expected: "a:\n- 1\n- 2\n- b: 1\n- 3\n- \"1\"\n",
+		},
+		{
+			base:     "[1, 2]",
+			changes:  "[3]",
+			policy:   ListMerge{Strategy: AppendList},
+			expected: "- 1\n- 2\n- 3\n",
+		},
+		// Items merged by key, the rest are added
+		{
+			base:     "containers: [{name: app, image: app:1, ports: [80]}, {name: sidecar, image: proxy}]",
+			changes:  "containers: [{name: app, image: app:2}, {name: debug, image: busybox}, {image: other}]",
+			policy:   ListMerge{Strategy: MergeByKey},
+			expected: "containers:\n- image: app:2\n  name: app\n  ports:\n  - 80\n- image: proxy\n  name: sidecar\n- image: busybox\n  name: debug\n- image: other\n",
+		},
+		{
+			base:     "items: [{metadata: {name: a}, value: 1}, {metadata: {name: b}, value: 2}]",
+			changes:  "items: [{metadata: {name: b}, value: 3}]",
+			policy:   ListMerge{Strategy: MergeByKey, Key: "metadata.name"},
+			expected: "items:\n- metadata:\n    name: a\n  value: 1\n- metadata:\n    name: b\n  value: 3\n",
+		},
+		// Strategies of specific paths, the less wildcards the better
+		{
+			base:    "allow: [a]\ndeny: [a]\nspec: {containers: [{name: app, env: [{name: A, value: '1'}]}]}",
+			changes: "allow: [b]\ndeny: [b]\nspec: {containers: [{name: app, env: [{name: B, value: '2'}]}]}",
+			policy: ListMerge{
+				Strategy: ReplaceList,
+				Paths: []PathListMerge{
+					{Path: "*", Strategy: PrependList},
+					{Path: "allow", Strategy: AppendList},
+					{Path: "spec.containers", Strategy: MergeByKey},
+					{Path: "spec.containers[*].env", Strategy: MergeByKey},
+				},
+			},
+			expected: "allow:\n- a\n- b\ndeny:\n- b\n- a\nspec:\n  containers:\n  - env:\n    - name: A\n      value: \"1\"\n    - name: B\n      value: \"2\"\n    name: app\n",
+		},
+		{
+			base:     "items: [{id: 1, v: a}, {name: 1, v: b}]",
+			changes:  "items: [{id: 1, v: c}]",
+			policy:   ListMerge{Strategy: AppendList, Paths: []PathListMerge{{Path: "items", Key: "id"}, {Path: "other", Strategy: MergeByKey}}},
+			expected: "items:\n- id: 1\n  v: a\n- name: 1\n  v: b\n- id: 1\n  v: c\n",
+		},
+		{
+			base:     "items: [{id: 1, v: a}, {name: 1, v: b}]",
+			changes:  "items: [{id: 1, v: c}]",
+			policy:   ListMerge{Key: "id", Paths: []PathListMerge{{Path: "items", Strategy: MergeByKey}}},
+			expected: "items:\n- id: 1\n  v: c\n- name: 1\n  v: b\n",
+		},
+		// Anchored lists keep their aliases when not modified
+		{
+			base:     "a: &list [1, 2]\nb: *list",
+			changes:  "b: [2]",
+			policy:   ListMerge{Strategy: UnionList},
+			expected: "a:\n- 1\n- 2\nb:\n- 1\n- 2\n",
+		},
+		{
+			base:     "a: &list [1, 2]\nb: *list",
+			changes:  "b: [3]",
+			policy:   ListMerge{Strategy: AppendList},
+			expected: "a:\n- 1\n- 2\nb:\n- 1\n- 2\n- 3\n",
+		},
+	} {
+		merged, err := MergeContents(i.base, i.changes, WithListMerge(i.policy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeContentsListsAnchors(t *testing.T) {
+	merged, err := MergeContents("a: &list [1, 2]\nb: *list", "b: [2]", WithListMerge(ListMerge{Strategy: UnionList}), WithFormat(format.WithAnchors(format.PreserveAnchors)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, "a: &list\n- 1\n- 2\nb: *list\n", merged)
+}
+
+func TestListMergeInvalid(t *testing.T) {
+	for _, i := range []struct {
+		policy   ListMerge
+		expected string
+	}{
+		{
+			policy:   ListMerge{Strategy: "other"},
+			expected: "unknown list strategy other, valid strategies are [replace append prepend union key]",
+		},
+		{
+			policy:   ListMerge{Paths: []PathListMerge{{Path: "a", Strategy: "other"}}},
+			expected: "a: unknown list strategy other",
+		},
+		{
+			policy:   ListMerge{Paths: []PathListMerge{{Path: "a[", Strategy: AppendList}}},
+			expected: "invalid path a[",
+		},
+		{
+			policy:   ListMerge{Key: "*"},
+			expected: "invalid list key *, it must be a path without wildcards",
+		},
+		{
+			policy:   ListMerge{Paths: []PathListMerge{{Path: "a", Key: "b..c"}}},
+			expected: "a: invalid list key",
+		},
+	} {
+		itesting.AssertError(t, i.expected, i.policy.Validate())
+		merged, err := MergeContents("a: 1", "a: 2", WithListMerge(i.policy))
+		itesting.AssertError(t, i.expected, err)
+		if merged != "" {
+			t.Fatalf("Should not have merged")
+		}
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+// Option configures a merge.
+type Option func(o *options)
+
+type options struct {
+	format       []format.Option
+	documents    DocumentStrategy
+	documentKeys []string
+	lists        ListMerge
+	explain      bool
+	nullDeletes  bool
+	strict       Strict
+	protected    []string
+	env          Env
+	sets         []set
+	single       bool // Whether a single yaml can be merged
+}
+
+// WithFormat configures how the merged yaml is formatted (see format package).
+func WithFormat(opts ...format.Option) Option {
+	return func(o *options) {
+		o.format = append(o.format, opts...)
+	}
+}
+
+// WithDocumentStrategy configures how the documents of multi-document yaml
+// streams are merged (ByIndex by default).
+func WithDocumentStrategy(strategy DocumentStrategy) Option {
+	return func(o *options) {
+		o.documents = strategy
+	}
+}
+
+// WithDocumentKeys configures the paths of the keys that identify a document
+// with the ByKey document strategy (DefaultDocumentKeys by default), like
+// "metadata.name".
+func WithDocumentKeys(keys ...string) Option {
+	return func(o *options) {
+		o.documentKeys = append(o.documentKeys, keys...)
+	}
+}
+
+// WithListMerge configures the policy to merge lists, globally and for specific
+// paths (lists are replaced by default).
+func WithListMerge(policy ListMerge) Option {
+	return func(o *options) {
+		o.lists = policy
+	}
+}
+
+// WithExplain configures whether the file (or content number) and line that set
+// each merged value is written as a comment next to it.
+func WithExplain(explain bool) Option {
+	return func(o *options) {
+		o.explain = explain
+	}
+}
+
+// WithNullDeletes configures whether a null value deletes its key, and
+// everything under it, from the previous contents, as in a JSON merge patch
+// (RFC 7386). By default a null value replaces the previous value.
+func WithNullDeletes(deletes bool) Option {
+	return func(o *options) {
+		o.nullDeletes = deletes
+	}
+}
+
+// WithStrict configures the checks of a strict merge, which fails with a
+// StrictError listing the conflicts found (nothing is checked by default).
+func WithStrict(strict Strict) Option {
+	return func(o *options) {
+		o.strict = strict
+	}
+}
+
+// WithProtectedPaths configures paths, which may contain wildcards, whose values
+// cannot be changed by the contents merged after the first content that has
+// them: the merge fails with a ProtectedError naming the content that changes
+// them. Values can also be protected in the contents with LockedTag.
+func WithProtectedPaths(paths ...string) Option {
+	return func(o *options) {
+		o.protected = append(o.protected, paths...)
+	}
+}
+
+// WithEnv configures the overlay of environment variables, merged after every
+// content as the most important yaml (no overlay by default).
+func WithEnv(env Env) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+// WithSet sets the value of a path after merging every content and the
+// environment variables (see WithEnv), like "a.b[0].c". The type of the value
+// is inferred as in a yaml scalar (8080 is an int, true a bool...) and a value
+// in yaml flow style is a list or a mapping. The missing keys of the path are
+// added, an index one past the end of a list appends the value. The values are
+// set in order, in the first merged document.
+func WithSet(path string, value string) Option {
+	return withSet(path, value, yaml.InferredValue)
+}
+
+// WithSetString sets a string value in a path as WithSet.
+func WithSetString(path string, value string) Option {
+	return withSet(path, value, yaml.StringValue)
+}
+
+// WithSetJSON sets a json value in a path as WithSet.
+func WithSetJSON(path string, value string) Option {
+	return withSet(path, value, yaml.JSONValue)
+}
+
+// WithSetFile sets the content of a file as string value in a path as WithSet.
+func WithSetFile(path string, file string) Option {
+	return withSet(path, file, yaml.FileValue)
+}
+
+func withSet(path string, value string, kind yaml.ValueType) Option {
+	return func(o *options) {
+		o.sets = append(o.sets, set{path: path, value: value, kind: kind})
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// minSources returns the minimum number of yaml to merge, a single yaml can be
+// read as it is or merged with the environment variables or the values set.
+func (o *options) minSources() int {
+	if o.single || o.env.enabled() || len(o.sets) > 0 {
+		return 1
+	}
+	return 2
+}
+
+// mergeOptions returns the internal merge options.
+func (o *options) mergeOptions() (yaml.MergeOptions, error) {
+	lists, err := o.lists.lists()
+	if err != nil {
+		return yaml.MergeOptions{}, err
+	}
+	return yaml.MergeOptions{Lists: lists, NullDeletes: o.nullDeletes}, nil
+}
+
+// documentPaths returns the parsed paths of the document keys.
+func (o *options) documentPaths() ([]path.Path, error) {
+	keys := o.documentKeys
+	if len(keys) == 0 {
+		keys = DefaultDocumentKeys
+	}
+	paths := make([]path.Path, len(keys))
+	for i, key := range keys {
+		var err error
+		if paths[i], err = path.Parse(key); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// LockedTag is the tag that protects a value in a yaml: the yaml merged after
+// it cannot change the value.
+const LockedTag = yaml.LockedTag
+
+// ProtectedError is the error of a merge in which a file changes a protected
+// value.
+type ProtectedError struct {
+	File string // File that changes the value ("content n" for the n-th content, $NAME for an environment variable)
+	Line int    // Line of the change in the file (1-based, 0 for an environment variable)
+	Path string // Path of the protected value (empty for the root)
+}
+
+func (e *ProtectedError) Error() string {
+	p := e.Path
+	if p == "" {
+		p = "."
+	}
+	return fmt.Sprintf("%s: protected path %s cannot be changed", location(e.File, e.Line), p)
+}
+
+// locks returns the locks of the protected paths, which may contain wildcards.
+func (o *options) locks() (*yaml.Locks, error) {
+	paths := make([]path.Path, len(o.protected))
+	for i, p := range o.protected {
+		var err error
+		if paths[i], err = path.Parse(p); err != nil {
+			return nil, err
+		}
+	}
+	return yaml.NewLocks(paths...), nil
+}
+
+// protects returns whether any value of the sources may be protected, which
+// requires tracking their nodes to find the file that changes it.
+func (o *options) protects(sources []source) bool {
+	if len(o.protected) > 0 {
+		return true
+	}
+	for _, s := range sources {
+		if strings.Contains(s.content, LockedTag) {
+			return true
+		}
+	}
+	return false
+}
+
+// protectedError returns the error of a change of a protected value, with the
+// file and line of the change, or the error as is if it is not.
+func protectedError(err error, tracker *yaml.Tracker) error {
+	var lockErr *yaml.LockError
+	if !errors.As(err, &lockErr) {
+		return err
+	}
+	source, ok := tracker.Source(lockErr.Changes)
+	if !ok {
+		source, _ = tracker.Source(lockErr.Document)
+	}
+	return &ProtectedError{File: source.Name, Line: source.Line, Path: lockErr.Path.String()}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"errors"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestMergeContentsProtected(t *testing.T) {
+	for _, i := range []struct {
+		contents  []string
+		protected []string
+		err       ProtectedError
+	}{
+		// Locked tags
+		{
+			contents: []string{"security:\n  tls: !locked true\n  port: 443", "security:\n  port: 8443\n  tls: false"},
+			err:      ProtectedError{File: "content 2", Line: 3, Path: "security.tls"},
+		},
+		{
+			contents: []string{"security: !locked\n  tls: true", "a: 1", "security:\n  ciphers: [a]"},
+			err:      ProtectedError{File: "content 3", Line: 2, Path: "security"},
+		},
+		{
+			contents: []string{"list: !locked [1, 2]", "list: [1]"},
+			err:      ProtectedError{File: "content 2", Line: 1, Path: "list"},
+		},
+		// Locked in a later content
+		{
+			contents: []string{"a: 1", "a: !locked 2", "a: 3"},
+			err:      ProtectedError{File: "content 3", Line: 1, Path: "a"},
+		},
+		// Protected paths
+		{
+			contents:  []string{"image:\n  registry: example.com\n  tag: v1", "image:\n  tag: v2\n  registry: docker.io"},
+			protected: []string{"image.registry"},
+			err:       ProtectedError{File: "content 2", Line: 3, Path: "image.registry"},
+		},
+		{
+			contents:  []string{"apps: [{name: a, image: x}]", "apps: [{name: a, image: y}]"},
+			protected: []string{"apps[*].image"},
+			err:       ProtectedError{File: "content 2", Line: 1, Path: "apps[0].image"},
+		},
+		// Deleted values
+		{
+			contents:  []string{"a:\n  b: 1\nc: 2", "a:\n  b: !delete null"},
+			protected: []string{"a.b"},
+			err:       ProtectedError{File: "content 2", Line: 2, Path: "a.b"},
+		},
+		{
+			contents: []string{"a:\n  b: !locked 1\nc: 2", "c: 3\na: !replace {c: 1}"},
+			err:      ProtectedError{File: "content 2", Line: 2, Path: "a.b"},
+		},
+		{
+			contents: []string{"a:\n  b: !locked 1", "a: null"},
+			err:      ProtectedError{File: "content 2", Line: 1, Path: "a.b"},
+		},
+	} {
+		_, err := MergeAllContents(i.contents, WithProtectedPaths(i.protected...))
+		var protectedErr *ProtectedError
+		if !errors.As(err, &protectedErr) {
+			t.Fatalf("Expected a protected error, got %v", err)
+		}
+		itesting.AssertEqual(t, i.err, *protectedErr)
+	}
+}
+
+func TestMergeContentsProtectedValid(t *testing.T) {
+	for _, i := range []struct {
+		contents  []string
+		protected []string
+		expected  string
+	}{
+		// Same values and other keys
+		{
+			contents: []string{"tls: !locked true\nport: 443", "tls: true\nport: 8443"},
+			expected: "port: 8443\ntls: true\n",
+		},
+		{
+			contents: []string{"a: !locked {b: 1, c: [1]}", "a: {c: [1]}\nd: 1", "a: {b: 1}"},
+			expected: "a:\n  b: 1\n  c:\n  - 1\nd: 1\n",
+		},
+		// The locked tag does not change the type of values
+		{
+			contents: []string{"a: !locked '5'\nb: !locked 5", "a: '5'\nb: 5"},
+			expected: "a: \"5\"\nb: 5\n",
+		},
+		// Protected paths can be set when they are not there
+		{
+			contents:  []string{"a: 1", "b: 2"},
+			protected: []string{"b"},
+			expected:  "a: 1\nb: 2\n",
+		},
+		{
+			contents:  []string{"x: &x {a: 1}\ny: *x", "y: {a: 1}"},
+			protected: []string{"y.a"},
+			expected:  "x:\n  a: 1\n\"y\":\n  a: 1\n",
+		},
+	} {
+		merged, err := MergeAllContents(i.contents, WithProtectedPaths(i.protected...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeContentsInvalidProtectedPath(t *testing.T) {
+	_, err := MergeAllContents([]string{"a: 1", "a: 2"}, WithProtectedPaths("a[x"))
+	itesting.AssertError(t, "invalid path a[x", err)
+}
+
+func TestMergeFilesProtected(t *testing.T) {
+	dir := t.TempDir()
+	base := itesting.WriteFile(t, dir, "base.yml", "registry: !locked example.com\ntag: v1\n")
+	overlay := itesting.WriteFile(t, dir, "prod.yml", "tag: v2\nregistry: docker.io\n")
+	_, err := MergeAllFiles([]string{base, overlay})
+	itesting.AssertError(t, overlay+":2: protected path registry cannot be changed", err)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// Origin is where a merged value was set.
+type Origin struct {
+	File   string // File that set the value ("content n" for the n-th content, $NAME for an environment variable)
+	Line   int    // Line of the value in the file (1-based, 0 for an environment variable)
+	Column int    // Column of the value in the file (1-based)
+	Value  string // Value set, written in flow style
+}
+
+func (o Origin) String() string {
+	return location(o.File, o.Line)
+}
+
+// location returns the file and line of a value, only the name for the values
+// without line (set by environment variables).
+func location(file string, line int) string {
+	if line == 0 {
+		return file
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// Provenance maps the paths of the nodes of a merged document to the origins of
+// their values, from the first yaml that set it to the one that prevails. The
+// paths follow the same syntax as the format paths (see
+// format.PathKeyOrder), the root path is the empty string.
+type Provenance map[string][]Origin
+
+// Merged is a merged yaml document with the provenance of its values.
+type Merged struct {
+	Document   *yaml.Node // Merged document, before formatting
+	Provenance Provenance // Origins of the values of the document
+}
+
+// MergeAllContentsWithProvenance merges all yaml contents like
+// MergeAllContents, returning each merged document (not formatted) with the
+// provenance of its values. The names identify the contents in the
+// provenance, like their file names.
+func MergeAllContentsWithProvenance(names []string, contents []string, opts ...Option) ([]Merged, error) {
+	if len(names) != len(contents) {
+		return nil, errors.New("there must be a name for each content")
+	}
+	sources := make([]source, len(contents))
+	for i, content := range contents {
+		sources[i] = source{name: names[i], content: content}
+	}
+	return mergeWithProvenance(sources, newOptions(opts))
+}
+
+// MergeAllFilesWithProvenance merges all yaml files like MergeAllFiles,
+// returning each merged document (not formatted) with the provenance of its
+// values.
+func MergeAllFilesWithProvenance(files []string, opts ...Option) ([]Merged, error) {
+	sources, err := fileSources(files)
+	if err != nil {
+		return nil, err
+	}
+	return mergeWithProvenance(sources, newOptions(opts))
+}
+
+// mergeWithProvenance merges yaml sources returning the merged documents with
+// the provenance of their values.
+func mergeWithProvenance(sources []source, o *options) ([]Merged, error) {
+	docs, tracker, err := mergeTracked(sources, o, true)
+	if err != nil {
+		return nil, err
+	}
+	merged := make([]Merged, len(docs))
+	for i, doc := range docs {
+		merged[i] = Merged{Document: doc, Provenance: provenance(doc, tracker)}
+	}
+	return merged, nil
+}
+
+// provenance returns the origins of the values of every node of a document.
+func provenance(doc *yaml.Node, tracker *yaml.Tracker) Provenance {
+	p := Provenance{}
+	walkPaths(yaml.Root(doc), path.Path{}, func(n *yaml.Node, np path.Path) {
+		if origins := origins(n, tracker); len(origins) > 0 {
+			p[np.String()] = origins
+		}
+	})
+	return p
+}
+
+// origins returns the origins of the value of a node.
+func origins(n *yaml.Node, tracker *yaml.Tracker) []Origin {
+	var origins []Origin
+	for _, setter := range tracker.Chain(n) {
+		if s, ok := tracker.Source(setter); ok {
+			origins = append(origins, Origin{File: s.Name, Line: s.Line, Column: s.Column, Value: s.Value})
+		}
+	}
+	return origins
+}
+
+// explain writes the origin of each leaf value of a document as a comment next
+// to it.
+func explain(doc *yaml.Node, tracker *yaml.Tracker) {
+	walkPaths(yaml.Root(doc), path.Path{}, func(n *yaml.Node, _ path.Path) {
+		if len(n.Content) > 0 && n.Kind != yaml.AliasNode {
+			return
+		}
+		origins := origins(n, tracker)
+		if len(origins) == 0 {
+			return
+		}
+		comment := "# " + origins[len(origins)-1].String()
+		if n.LineComment != "" {
+			comment = strings.TrimSpace(n.LineComment) + " " + comment
+		}
+		n.LineComment = comment
+	})
+}
+
+// walkPaths visits every node of a tree (without following aliases) with its
+// path.
+func walkPaths(n *yaml.Node, p path.Path, visit func(n *yaml.Node, p path.Path)) {
+	if n == nil {
+		return
+	}
+	visit(n, p)
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			walkPaths(n.Content[i+1], p.Child(n.Content[i].Value), visit)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			walkPaths(item, p.Item(i), visit)
+		}
+	}
+}
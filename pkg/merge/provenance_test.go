@@ -0,0 +1,168 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"strings"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+func TestMergeAllContentsWithProvenance(t *testing.T) {
+	contents := []string{
+		"a: 1\nb:\n  c: x\nlist: [1, 2]\nkeep: &k {v: 1}\nalias: *k",
+		"a: 2\nb:\n  d: y\nlist: [3]",
+		"# Last\na: 3\nb: {c: z}\nalias: {v: 1}",
+	}
+	merged, err := MergeAllContentsWithProvenance([]string{"base.yml", "dev.yml", "prod.yml"}, contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, 1, len(merged))
+	p := merged[0].Provenance
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "base.yml", Line: 1, Column: 4, Value: "1"},
+		{File: "dev.yml", Line: 1, Column: 4, Value: "2"},
+		{File: "prod.yml", Line: 2, Column: 4, Value: "3"},
+	}, p["a"])
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "base.yml", Line: 3, Column: 6, Value: "x"},
+		{File: "prod.yml", Line: 3, Column: 8, Value: "z"},
+	}, p["b.c"])
+	itesting.AssertDeepEqual(t, []Origin{{File: "dev.yml", Line: 3, Column: 6, Value: `"y"`}}, p["b.d"])
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "base.yml", Line: 4, Column: 7, Value: "[1, 2]"},
+		{File: "dev.yml", Line: 4, Column: 7, Value: "[3]"},
+	}, p["list"])
+	itesting.AssertDeepEqual(t, []Origin{{File: "dev.yml", Line: 4, Column: 8, Value: "3"}}, p["list[0]"])
+	// Aliases are explained with the value they point to, a value set again is
+	// the last origin
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "base.yml", Line: 6, Column: 8, Value: "{v: 1}"},
+		{File: "prod.yml", Line: 4, Column: 8, Value: "{v: 1}"},
+	}, p["alias"])
+	itesting.AssertDeepEqual(t, []Origin{{File: "base.yml", Line: 5, Column: 14, Value: "1"}}, p["keep.v"])
+}
+
+func TestMergeAllContentsWithProvenanceSameMapping(t *testing.T) {
+	merged, err := MergeAllContentsWithProvenance(
+		[]string{"e1.yml", "e2.yml", "e3.yml"},
+		[]string{"a:\n  b: 1\n", "a:\n  c: 2\n", "a:\n  b: 3\n"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := merged[0].Provenance
+	// The values are the ones read from each file, not the merged ones
+	itesting.AssertDeepEqual(t, []Origin{{File: "e1.yml", Line: 2, Column: 3, Value: "{b: 1}"}}, p["a"])
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "e1.yml", Line: 2, Column: 6, Value: "1"},
+		{File: "e3.yml", Line: 2, Column: 6, Value: "3"},
+	}, p["a.b"])
+	itesting.AssertDeepEqual(t, []Origin{{File: "e2.yml", Line: 2, Column: 6, Value: "2"}}, p["a.c"])
+}
+
+func TestMergeAllContentsWithProvenanceStrategies(t *testing.T) {
+	merged, err := MergeAllContentsWithProvenance(
+		[]string{"base.yml", "dev.yml"},
+		[]string{"list: [{name: a, v: 1}, {name: b}]\nold: 1", "list: [{name: a, v: 2}, {name: c}]\nold: !delete"},
+		WithListMerge(ListMerge{Strategy: MergeByKey}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := merged[0].Provenance
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: "base.yml", Line: 1, Column: 21, Value: "1"},
+		{File: "dev.yml", Line: 1, Column: 21, Value: "2"},
+	}, p["list[0].v"])
+	itesting.AssertDeepEqual(t, []Origin{{File: "base.yml", Line: 1, Column: 32, Value: "b"}}, p["list[1].name"])
+	itesting.AssertDeepEqual(t, []Origin{{File: "dev.yml", Line: 1, Column: 32, Value: "c"}}, p["list[2].name"])
+	_, ok := p["old"]
+	itesting.AssertFalse(t, ok)
+}
+
+func TestMergeAllContentsWithProvenanceInvalid(t *testing.T) {
+	_, err := MergeAllContentsWithProvenance([]string{"base.yml"}, []string{"a: 1", "a: 2"})
+	itesting.AssertError(t, "there must be a name for each content", err)
+	_, err = MergeAllContentsWithProvenance([]string{"base.yml"}, []string{"a: 1"})
+	itesting.AssertError(t, "slice must contain at least two contents", err)
+	_, err = MergeAllContentsWithProvenance([]string{"base.yml", "dev.yml"}, []string{"a: 1", "key: value: other"})
+	itesting.AssertError(t, "mapping values are not allowed", err)
+}
+
+func TestMergeContentsExplain(t *testing.T) {
+	for _, i := range []struct {
+		contents []string
+		opts     []Option
+		expected string
+	}{
+		{
+			contents: []string{"a: 1\nb: 2 # Two\nc: [x]", "a: 3\nc: [y, z]"},
+			expected: "a: 3 # content 2:1\nb: 2 # Two # content 1:2\nc:\n- \"y\" # content 2:2\n- z # content 2:2\n",
+		},
+		// Empty collections are leaves
+		{
+			contents: []string{"a: {b: 1}", "a: {}\nc: []"},
+			expected: "a:\n  b: 1 # content 1:1\nc: [] # content 2:2\n",
+		},
+		// Aliases are leaves
+		{
+			contents: []string{"a: &a {b: 1}\nc: *a", "d: 1"},
+			opts:     []Option{WithFormat(format.WithAnchors(format.PreserveAnchors))},
+			expected: "a: &a\n  b: 1 # content 1:1\nc: *a # content 1:2\nd: 1 # content 2:1\n",
+		},
+	} {
+		merged, err := MergeAllContents(i.contents, append(i.opts, WithExplain(true))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, merged)
+	}
+}
+
+func TestMergeAllFilesWithProvenance(t *testing.T) {
+	doc := itesting.WriteFile(t, t.TempDir(), "doc.md", "---\ntitle: Doc\n---\n# Doc\n")
+	merged, err := MergeAllFilesWithProvenance([]string{fileToBeMerged("base"), fileToBeMerged("dev"), doc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := merged[0].Provenance
+	itesting.AssertDeepEqual(t, []Origin{
+		{File: fileToBeMerged("base"), Line: 5, Column: 12, Value: "1.0.0"},
+		{File: fileToBeMerged("dev"), Line: 2, Column: 12, Value: "1.0.0-alpha"},
+	}, p["app.version"])
+	itesting.AssertDeepEqual(t, []Origin{{File: doc, Line: 2, Column: 8, Value: "Doc"}}, p["title"])
+	_, err = MergeAllFilesWithProvenance([]string{fileToBeMerged("base"), "not-exists.yml"})
+	itesting.AssertError(t, "no such file or directory", err)
+}
+
+func TestMergeAllFilesExplain(t *testing.T) {
+	merged, err := MergeFiles(fileToBeMerged("base"), fileToBeMerged("dev"), WithExplain(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertTrue(t, strings.Contains(merged, "version: 1.0.0-alpha # testdata/dev.yml:2\n"))
+}
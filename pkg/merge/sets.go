@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// set is a value set in a path after merging.
+type set struct {
+	path  string
+	value string
+	kind  yaml.ValueType
+}
+
+// name returns the name of the set in the provenance, like its flag.
+func (s set) name() string {
+	switch s.kind {
+	case yaml.StringValue:
+		return "--set-string " + s.path
+	case yaml.JSONValue:
+		return "--set-json " + s.path
+	case yaml.FileValue:
+		return "--set-file " + s.path
+	}
+	return "--set " + s.path
+}
+
+// overlay merges the environment variables and sets the values of the paths
+// in a merged document.
+func (o *options) overlay(doc *yaml.Node, mo yaml.MergeOptions) (*yaml.Node, error) {
+	var err error
+	if o.env.enabled() {
+		if doc, err = o.env.overlay(doc, mo); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range o.sets {
+		if doc, err = s.apply(doc, mo); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// apply sets the value in a merged document, reading it in the tracker (if
+// any) named as its flag, without line.
+func (s set) apply(doc *yaml.Node, mo yaml.MergeOptions) (*yaml.Node, error) {
+	p, err := path.Parse(s.path)
+	if err != nil {
+		return nil, err
+	}
+	value, err := yaml.ParseValue(s.value, s.kind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of %s: %w", s.name(), err)
+	}
+	changes := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{value}}
+	if mo.Tracker != nil {
+		mo.Tracker.Read(changes, s.name(), 0)
+	}
+	return yaml.SetNode(doc, p, changes, mo)
+}
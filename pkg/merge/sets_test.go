@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+func TestMergeContentsSet(t *testing.T) {
+	base := `spec:
+  containers:
+  - name: app # main
+    image: app:1
+  replicas: 1
+a.b: dotted
+`
+	ca := itesting.WriteFile(t, t.TempDir(), "ca.pem", "line 1\nline 2\n")
+	for name, i := range map[string]struct {
+		opts     []Option
+		expected string
+	}{
+		"inferred types": {
+			opts: []Option{WithSet("spec.replicas", "3"), WithSet("debug", "true"), WithSet("name", "app"), WithSet("ports", "[80, 443]")},
+			expected: `spec:
+  containers:
+  - name: app # main
+    image: app:1
+  replicas: 3
+a.b: dotted
+debug: true
+name: app
+ports:
+- 80
+- 443
+`,
+		},
+		"list indexes": {
+			opts: []Option{WithSet("spec.containers[0].image", "app:2"), WithSet("spec.containers[1].name", "sidecar"), WithSet("new[0].a", "1")},
+			expected: `spec:
+  containers:
+  - name: app # main
+    image: app:2
+  - name: sidecar
+  replicas: 1
+a.b: dotted
+new:
+- a: 1
+`,
+		},
+		"escaped and quoted keys": {
+			opts: []Option{WithSet(`a\.b`, "escaped"), WithSet(`'c.d'.e`, "quoted")},
+			expected: `spec:
+  containers:
+  - name: app # main
+    image: app:1
+  replicas: 1
+a.b: escaped
+c.d:
+  e: quoted
+`,
+		},
+		"string, json and file": {
+			opts: []Option{WithSetString("spec.replicas", "3"), WithSetJSON("spec.containers[0]", `{"name": "web", "ports": [80]}`), WithSetFile("ca", ca)},
+			expected: `spec:
+  containers:
+  - name: web # main
+    image: app:1
+    ports:
+    - 80
+  replicas: "3"
+a.b: dotted
+ca: |
+  line 1
+  line 2
+`,
+		},
+		"in order": {
+			opts: []Option{WithSet("spec.replicas", "2"), WithSet("spec.replicas", "3")},
+			expected: `spec:
+  containers:
+  - name: app # main
+    image: app:1
+  replicas: 3
+a.b: dotted
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			merged, err := MergeAllContents([]string{base}, append(i.opts, WithFormat(format.WithKeyOrder(format.KeyOrder{Order: format.Source})))...)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, i.expected, merged)
+		})
+	}
+}
+
+func TestMergeContentsSetAfterEnv(t *testing.T) {
+	t.Setenv("APP__A", "2")
+	merged, err := MergeContents("a: 0\n", "a: 1\n", WithEnv(Env{Prefix: "APP"}), WithSet("a", "3"))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 3\n", merged)
+}
+
+func TestMergeContentsSetInvalid(t *testing.T) {
+	for _, i := range []struct {
+		opt      Option
+		expected string
+	}{
+		{opt: WithSet("list[2]", "x"), expected: "index 2 out of range in path list with 1 items"},
+		{opt: WithSet("new[1]", "x"), expected: "index 1 out of range in path new with 0 items"},
+		{opt: WithSet("a[0]", "x"), expected: "path a is not a list"},
+		{opt: WithSet("list[*]", "x"), expected: "path list.* must not contain wildcards"},
+		{opt: WithSet("a[", "x"), expected: "a["},
+		{opt: WithSetJSON("a", "{"), expected: "invalid value of --set-json a: invalid json"},
+		{opt: WithSetFile("a", "missing.pem"), expected: "invalid value of --set-file a"},
+	} {
+		_, err := MergeAllContents([]string{"a: 1\nlist: [1]\n"}, i.opt)
+		itesting.AssertError(t, i.expected, err)
+	}
+}
+
+func TestMergeContentsSetChecks(t *testing.T) {
+	_, err := MergeAllContents([]string{"a: 1\n"}, WithSet("b.c", "1"), WithStrict(Strict{UnknownKeys: true}))
+	itesting.AssertError(t, "--set b.c: b: key not found in the previous files", err)
+	_, err = MergeAllContents([]string{"a: {b: 1}\n"}, WithSet("a", "1"), WithStrict(Strict{TypeConflicts: true}))
+	itesting.AssertError(t, "--set a: a: a mapping replaced by a scalar", err)
+	_, err = MergeAllContents([]string{"a: !locked 1\n"}, WithSet("a", "2"))
+	itesting.AssertError(t, "--set a: protected path a cannot be changed", err)
+	merged, err := MergeAllContents([]string{"a: 1\n"}, WithSet("a", "2"), WithExplain(true))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 2 # --set a\n", merged)
+}
+
+func TestMergeContentsSetAnchors(t *testing.T) {
+	merged, err := MergeAllContents([]string{"base: &b {x: 1}\nother: *b\n"}, WithSet("other.x", "2"), WithFormat(format.WithAnchors(format.PreserveAnchors)))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "base: &b\n  x: 1\nother:\n  x: 2\n", merged)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// Strict configures the checks of a strict merge, which fails if any of them
+// finds a conflict.
+type Strict struct {
+	TypeConflicts bool // Values of a different kind (mapping, list or scalar) than the previous value
+	UnknownKeys   bool // Keys that are not in the previous contents
+}
+
+// enabled returns whether any check is enabled.
+func (s Strict) enabled() bool {
+	return s.TypeConflicts || s.UnknownKeys
+}
+
+// Conflict is a value that conflicts with the previous contents in a strict
+// merge.
+type Conflict struct {
+	File    string // File of the value ("content n" for the n-th content, $NAME for an environment variable)
+	Line    int    // Line of the value in the file (1-based, 0 for an environment variable)
+	Path    string // Path of the value (empty for the root)
+	Message string // Description of the conflict
+}
+
+func (c Conflict) String() string {
+	p := c.Path
+	if p == "" {
+		p = "."
+	}
+	return fmt.Sprintf("%s: %s: %s", location(c.File, c.Line), p, c.Message)
+}
+
+// StrictError is the error of a strict merge that found conflicts.
+type StrictError struct {
+	Conflicts []Conflict // Conflicts in merge order
+}
+
+func (e *StrictError) Error() string {
+	lines := []string{fmt.Sprintf("strict merge found %d conflict(s):", len(e.Conflicts))}
+	for _, c := range e.Conflicts {
+		lines = append(lines, "  "+c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// check returns the function that collects the conflicts enabled in the strict
+// checks, nil if there are none.
+func (s Strict) check(tracker *yaml.Tracker, conflicts *[]Conflict) func(c yaml.Conflict) {
+	if !s.enabled() {
+		return nil
+	}
+	return func(c yaml.Conflict) {
+		var message string
+		switch {
+		case c.TypeConflict() && s.TypeConflicts:
+			message = fmt.Sprintf("%s replaced by %s", kindName(c.Base), kindName(c.Changes))
+		case !c.TypeConflict() && s.UnknownKeys:
+			message = "key not found in the previous files"
+		default:
+			return
+		}
+		source, _ := tracker.Source(c.Changes)
+		*conflicts = append(*conflicts, Conflict{File: source.Name, Line: source.Line, Path: c.Path.String(), Message: message})
+	}
+}
+
+// kindName returns the name of the kind of a value, with its article.
+func kindName(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	return "a scalar"
+}
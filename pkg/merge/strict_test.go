@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"errors"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestMergeContentsStrict(t *testing.T) {
+	for _, i := range []struct {
+		contents  []string
+		strict    Strict
+		conflicts []Conflict
+	}{
+		// Values of a different kind
+		{
+			contents: []string{"db: {host: x}\nlist: [1]\nport: 80", "db: localhost\nlist: {a: 1}\nport: '80'"},
+			strict:   Strict{TypeConflicts: true},
+			conflicts: []Conflict{
+				{File: "content 2", Line: 1, Path: "db", Message: "a mapping replaced by a scalar"},
+				{File: "content 2", Line: 2, Path: "list", Message: "a list replaced by a mapping"},
+			},
+		},
+		{
+			contents: []string{"a: 1", "b: 1", "[1]"},
+			strict:   Strict{TypeConflicts: true},
+			conflicts: []Conflict{
+				{File: "content 3", Line: 1, Path: "", Message: "a mapping replaced by a list"},
+			},
+		},
+		// Unknown keys, in any layer
+		{
+			contents: []string{"spec: {replicas: 1}", "spec: {replcas: 3}", "spec: {replcas: 4, other: {a: 1}}"},
+			strict:   Strict{UnknownKeys: true},
+			conflicts: []Conflict{
+				{File: "content 2", Line: 1, Path: "spec.replcas", Message: "key not found in the previous files"},
+				{File: "content 3", Line: 1, Path: "spec.other", Message: "key not found in the previous files"},
+			},
+		},
+		{
+			contents: []string{"db: {host: x}", "db: localhost\nnew: 1"},
+			strict:   Strict{TypeConflicts: true, UnknownKeys: true},
+			conflicts: []Conflict{
+				{File: "content 2", Line: 1, Path: "db", Message: "a mapping replaced by a scalar"},
+				{File: "content 2", Line: 2, Path: "new", Message: "key not found in the previous files"},
+			},
+		},
+	} {
+		merged, err := MergeAllContents(i.contents, WithStrict(i.strict))
+		var strictErr *StrictError
+		if !errors.As(err, &strictErr) {
+			t.Fatalf("Expected a strict error, got %v", err)
+		}
+		itesting.AssertDeepEqual(t, i.conflicts, strictErr.Conflicts)
+		itesting.AssertEqual(t, "", merged)
+	}
+}
+
+func TestMergeContentsStrictValid(t *testing.T) {
+	for _, contents := range [][]string{
+		// Same kinds and existing keys
+		{"a: {b: 1}\nc: [1]", "a: {b: x}\nc: [2, 3]"},
+		// Nulls never conflict
+		{"a: {b: 1}\nc: null", "a: null\nc: {d: 1}"},
+		// Replace and delete directives are intended
+		{"a: {b: 1}\nc: 1", "a: !replace 2\nc: !delete"},
+		// Keys inherited through merge keys exist
+		{"x: &x {a: 1}\ny: {<<: *x}", "y: {a: 2}"},
+		// Aliases are resolved
+		{"x: &x {a: 1}\ny: *x", "y: {a: 2}"},
+	} {
+		_, err := MergeAllContents(contents, WithStrict(Strict{TypeConflicts: true, UnknownKeys: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStrictError(t *testing.T) {
+	err := &StrictError{Conflicts: []Conflict{
+		{File: "dev.yml", Line: 3, Path: "db", Message: "a mapping replaced by a scalar"},
+		{File: "dev.yml", Line: 1, Message: "a mapping replaced by a list"},
+	}}
+	itesting.AssertEqual(t, "strict merge found 2 conflict(s):\n  dev.yml:3: db: a mapping replaced by a scalar\n  dev.yml:1: .: a mapping replaced by a list", err.Error())
+}
+
+func TestMergeFilesStrict(t *testing.T) {
+	_, err := MergeAllFiles(filesToBeMerged([]string{"base", "dev"}), WithStrict(Strict{UnknownKeys: true}))
+	itesting.AssertError(t, "testdata/dev.yml:6: app.env: key not found in the previous files", err)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/diff"
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+// Labels of the sides of the conflict markers of a three-way merge.
+const (
+	OursLabel   = "ours"
+	TheirsLabel = "theirs"
+)
+
+// ThreeWayConflict is a value changed differently by both sides of a three-way
+// merge. The values are written in flow style, empty if the value is not
+// there.
+type ThreeWayConflict struct {
+	Document int    `json:"document"` // Position of the document in the streams (0-based)
+	Path     string `json:"path"`     // Path of the value (empty for the root)
+	Base     string `json:"base,omitempty"`
+	Ours     string `json:"ours,omitempty"`
+	Theirs   string `json:"theirs,omitempty"`
+}
+
+// ThreeWayResult is the result of a three-way merge.
+type ThreeWayResult struct {
+	// Merged yaml, with the conflicting lines of both sides between git
+	// conflict markers
+	Merged    string
+	Conflicts []ThreeWayConflict // Conflicts in document and path order
+}
+
+// MergeThreeWay merges the changes made by two sides (ours and theirs) to a
+// common base yaml content. The changes of a single side are taken, both sides
+// merge recursively the keys of mappings and any other value (lists included)
+// changed differently by both sides is a conflict. Adding the same value in
+// both sides, or deleting it, is not a conflict. The documents of multi-document
+// streams are merged by position.
+//
+// The merged yaml keeps the layout of our content: only the values changed by
+// the merge are edited, as the edit package does, so the merge does not
+// reformat unrelated lines. If our content cannot be edited keeping its format
+// (like values with anchors used by aliases) the merged yaml is formatted (see
+// WithFormat), keeping the comments of our side, with its anchors expanded. The
+// rest of merge options do not apply. If there are conflicts, the merged yaml is resolved twice, once with our values
+// and once with their values, and the lines that differ between both are
+// written between conflict markers, as git does. Each conflict is returned.
+func MergeThreeWay(base string, ours string, theirs string, opts ...Option) (ThreeWayResult, error) {
+	o := newOptions(opts)
+	var streams [3][]*yaml.Node
+	for i, content := range []string{base, ours, theirs} {
+		docs, err := yaml.ParseNodes(content)
+		if err != nil {
+			return ThreeWayResult{}, err
+		}
+		for _, doc := range docs {
+			if err = yaml.Normalize(doc); err != nil {
+				return ThreeWayResult{}, err
+			}
+		}
+		streams[i] = docs
+	}
+	var result ThreeWayResult
+	var oursDocs, theirsDocs []*yaml.Node
+	for i := 0; i < len(streams[1]) || i < len(streams[2]); i++ {
+		b, ou, th := document(streams[0], i), document(streams[1], i), document(streams[2], i)
+		o, t, conflicts := yaml.MergeThreeWay(b, ou, th)
+		oursDocs = appendDocument(oursDocs, o, ou)
+		theirsDocs = appendDocument(theirsDocs, t, ou)
+		for _, c := range conflicts {
+			result.Conflicts = append(result.Conflicts, ThreeWayConflict{
+				Document: i,
+				Path:     c.Path.String(),
+				Base:     flowString(c.Base),
+				Ours:     flowString(c.Ours),
+				Theirs:   flowString(c.Theirs),
+			})
+		}
+	}
+	resolutions := [][]*yaml.Node{oursDocs}
+	if len(result.Conflicts) > 0 {
+		resolutions = append(resolutions, theirsDocs)
+	}
+	merged, err := keepLayout(ours, resolutions, o)
+	if err != nil {
+		return ThreeWayResult{}, err
+	}
+	result.Merged = merged[0]
+	if len(result.Conflicts) > 0 {
+		result.Merged = diff.Markers(OursLabel, TheirsLabel, merged[0], merged[1])
+	}
+	return result, nil
+}
+
+// keepLayout returns each resolution of a three-way merge written as our
+// content edited with the merged values. If our content cannot be edited for
+// any of them every resolution is formatted, so the differences between them
+// are only in the merged values.
+func keepLayout(ours string, resolutions [][]*yaml.Node, o *options) ([]string, error) {
+	contents := make([]string, len(resolutions))
+	for i, docs := range resolutions {
+		edited, ok := editDocuments(ours, docs, o)
+		if !ok {
+			for i, docs := range resolutions {
+				var err error
+				if contents[i], err = format.FormatDocuments(docs, o.format...); err != nil {
+					return nil, err
+				}
+			}
+			return contents, nil
+		}
+		contents[i] = edited
+	}
+	return contents, nil
+}
+
+// editDocuments returns our content with the values that differ from the
+// merged documents edited keeping its format, and whether it could be edited.
+// Documents added after ours are formatted.
+func editDocuments(ours string, merged []*yaml.Node, o *options) (string, bool) {
+	docs, err := yaml.ParseNodes(ours)
+	if err != nil || len(docs) > len(merged) {
+		return "", false
+	}
+	content := ours
+	for i, doc := range docs {
+		diffs, err := yaml.Compare(doc, merged[i])
+		if err != nil {
+			return "", false
+		}
+		for _, d := range diffs {
+			if d.Kind == yaml.Removed {
+				content, err = yaml.DeleteValue(content, i, d.Path)
+			} else {
+				content, err = yaml.EditValue(content, i, d.Path, d.To)
+			}
+			if err != nil {
+				return "", false
+			}
+		}
+	}
+	if len(merged) > len(docs) {
+		added, err := format.FormatDocuments(merged[len(docs):], o.format...)
+		if err != nil {
+			return "", false
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += "---\n" + added
+	}
+	// The documents of the edited content must be the merged ones
+	edited, err := yaml.ParseNodes(content)
+	if err != nil || len(edited) != len(merged) {
+		return "", false
+	}
+	for i, doc := range edited {
+		if diffs, err := yaml.Compare(doc, merged[i]); err != nil || len(diffs) > 0 {
+			return "", false
+		}
+	}
+	return content, true
+}
+
+// MergeThreeWayFiles merges the changes made by two sides (ours and theirs) to
+// a common base yaml file (see MergeThreeWay).
+func MergeThreeWayFiles(base string, ours string, theirs string, opts ...Option) (ThreeWayResult, error) {
+	var contents [3]string
+	for i, file := range []string{base, ours, theirs} {
+		var err error
+		if contents[i], err = io.ReadAsString(file); err != nil {
+			return ThreeWayResult{}, err
+		}
+	}
+	return MergeThreeWay(contents[0], contents[1], contents[2], opts...)
+}
+
+// document returns the document in a position of a stream, nil if there is
+// none.
+func document(docs []*yaml.Node, i int) *yaml.Node {
+	if i < len(docs) {
+		return docs[i]
+	}
+	return nil
+}
+
+// appendDocument appends a document with a merged root, keeping the comments of
+// our document, unless the root is nil because it has been deleted.
+func appendDocument(docs []*yaml.Node, root *yaml.Node, ours *yaml.Node) []*yaml.Node {
+	if root == nil {
+		return docs
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	if ours != nil {
+		doc.HeadComment = ours.HeadComment
+		doc.FootComment = ours.FootComment
+	}
+	return append(docs, doc)
+}
+
+// flowString returns a value in flow style, empty if it is nil.
+func flowString(n *yaml.Node) string {
+	if n == nil {
+		return ""
+	}
+	return yaml.FlowString(n)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package merge
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+func TestMergeThreeWay(t *testing.T) {
+	for _, i := range []struct {
+		base, ours, theirs string
+		expected           string
+		conflicts          []ThreeWayConflict
+	}{
+		// Changes of a single side
+		{
+			base:     "a: 1\nb: 2\nc: 3",
+			ours:     "a: one\nb: 2\nc: 3",
+			theirs:   "a: 1\nb: two",
+			expected: "a: one\nb: two",
+		},
+		// Changes in different keys of the same mapping
+		{
+			base:     "db:\n  host: x\n  port: 1\n",
+			ours:     "db:\n  host: y # ours\n  port: 1\n",
+			theirs:   "db:\n  host: x\n  port: 2\n  user: u\n",
+			expected: "db:\n  host: y # ours\n  port: 2\n  user: u\n",
+		},
+		// Same changes in both sides
+		{
+			base:     "a: 1\nlist: [1]",
+			ours:     "a: 2\nlist: [1, 2]\nnew: {x: 1}",
+			theirs:   "list: [1, 2]\nnew: {x: 1}\na: 2",
+			expected: "a: 2\nlist: [1, 2]\nnew: {x: 1}",
+		},
+		// New keys in both sides without a base
+		{
+			ours:     "a: 1",
+			theirs:   "b: 2",
+			expected: "a: 1\nb: 2",
+		},
+		// Conflicts, b is deleted by theirs
+		{
+			base:   "a: 1\nb: 2\nlist: [1]\n",
+			ours:   "a: one\nb: 2\nlist: [1, 2]\n",
+			theirs: "a: uno\nlist: [1, 3]\n",
+			expected: `<<<<<<< ours
+a: one
+list: [1, 2]
+=======
+a: uno
+list: [1, 3]
+>>>>>>> theirs
+`,
+			conflicts: []ThreeWayConflict{
+				{Path: "a", Base: "1", Ours: "one", Theirs: "uno"},
+				{Path: "list", Base: "[1]", Ours: "[1, 2]", Theirs: "[1, 3]"},
+			},
+		},
+		// Deleted in one side and changed in the other
+		{
+			base:     "a: {b: 1}\nc: 1\n",
+			ours:     "c: 1\n",
+			theirs:   "a: {b: 2}\nc: 1\n",
+			expected: "c: 1\n<<<<<<< ours\n=======\na:\n  b: 2\n>>>>>>> theirs\n",
+			conflicts: []ThreeWayConflict{
+				{Path: "a", Base: "{b: 1}", Theirs: "{b: 2}"},
+			},
+		},
+		// Multi-document streams
+		{
+			base:     "a: 1\n---\nb: 1\n",
+			ours:     "a: 2\n---\nb: 1\n",
+			theirs:   "a: 1\n---\nb: 2\n---\nc: 1\n",
+			expected: "a: 2\n---\nb: 2\n---\nc: 1\n",
+		},
+		{
+			base:     "a: 1\n---\nb: 1\n",
+			ours:     "a: 1\n---\nb: 2\n",
+			theirs:   "a: 1\n---\nb: 3\n",
+			expected: "a: 1\n---\n<<<<<<< ours\nb: 2\n=======\nb: 3\n>>>>>>> theirs\n",
+			conflicts: []ThreeWayConflict{
+				{Document: 1, Path: "b", Base: "1", Ours: "2", Theirs: "3"},
+			},
+		},
+	} {
+		result, err := MergeThreeWay(i.base, i.ours, i.theirs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		itesting.AssertEqual(t, i.expected, result.Merged)
+		itesting.AssertDeepEqual(t, i.conflicts, result.Conflicts)
+	}
+}
+
+func TestMergeThreeWayKeepsLayout(t *testing.T) {
+	ours := `# Service
+name: web  # the name
+server:
+    port: 8080
+    tags: [a, b]
+labels: {app: web}
+`
+	result, err := MergeThreeWay(ours, ours, itesting.Replace(t, ours, "port: 8080", "port: 9090")+"owner: me\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, itesting.Replace(t, ours, "port: 8080", "port: 9090")+"owner: me\n", result.Merged)
+}
+
+func TestMergeThreeWayFormatsAliases(t *testing.T) {
+	// Editing the anchored value would change the alias too
+	result, err := MergeThreeWay("b: &a 1\nc: *a\n", "b: &a 1\nc: *a\n", "b: &a 2\nc: 1\n", WithFormat(format.WithKeyOrder(format.KeyOrder{Order: format.Source})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, "b: 2\nc: 1\n", result.Merged)
+}
+
+func TestMergeThreeWayFiles(t *testing.T) {
+	result, err := MergeThreeWayFiles(fileToBeMerged("base"), fileToBeMerged("base"), fileToBeMerged("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The keys deleted by dev are deleted
+	expected, err := format.FormatFile(fileToBeMerged("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := format.FormatContent(result.Merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	itesting.AssertEqual(t, expected, merged)
+	itesting.AssertEqual(t, 0, len(result.Conflicts))
+	_, err = MergeThreeWayFiles(fileToBeMerged("base"), fileToBeMerged("missing"), fileToBeMerged("dev"))
+	itesting.AssertError(t, "missing", err)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package patch applies RFC 6902 json patches and RFC 7386 json merge patches,
+// written in yaml or json, to yaml contents changing only the text of the
+// patched values: comments, key order and the rest of the format of the
+// content are kept (see the edit package).
+//
+// A json patch is a list of operations (add, remove, replace, move, copy and
+// test) with RFC 6901 json pointers as paths, like "/spec/containers/0". A
+// merge patch is a mapping merged into the content: null values remove keys,
+// mappings are merged and any other value replaces the value in the content.
+// The type of a patch is detected from its root (a list is a json patch)
+// unless it is configured.
+//
+// A patch is applied to a document of the content as a whole: if any operation
+// fails (or a test does not pass) the content is not patched.
+package patch
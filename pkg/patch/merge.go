@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch
+
+import (
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// mergeEdit is a value set (or removed, if nil) in a path by a merge patch.
+type mergeEdit struct {
+	path  path.Path
+	value *yaml.Node
+}
+
+// applyMergePatch applies a RFC 7386 merge patch to a document of a yaml
+// content.
+func applyMergePatch(content string, patch *yaml.Node, document int) (string, error) {
+	root, err := yaml.ContentRoot(content, document)
+	if err != nil {
+		return "", err
+	}
+	var edits []mergeEdit
+	mergeEdits(root, yaml.Detach(patch), path.Path{}, &edits)
+	for _, e := range edits {
+		if e.value == nil {
+			content, err = yaml.DeleteValue(content, document, e.path)
+		} else {
+			content, err = yaml.EditValue(content, document, e.path, e.value)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return content, nil
+}
+
+// mergeEdits appends the edits of merging a patch into a target in a path: the
+// null values of a mapping remove their keys, mappings are merged and any
+// other value (or a mapping merged into a value that is not a mapping) is set
+// without its null values.
+func mergeEdits(target *yaml.Node, patch *yaml.Node, p path.Path, edits *[]mergeEdit) {
+	if target != nil && target.Kind == yaml.AliasNode {
+		target = target.Alias
+	}
+	if patch.Kind != yaml.MappingNode || target == nil || target.Kind != yaml.MappingNode {
+		*edits = append(*edits, mergeEdit{path: p, value: withoutNulls(patch)})
+		return
+	}
+	for j := 0; j+1 < len(patch.Content); j += 2 {
+		k, v := patch.Content[j].Value, patch.Content[j+1]
+		child := yaml.Lookup(target, path.Path{path.Key(k)})
+		switch {
+		case !isNull(v):
+			mergeEdits(child, v, p.Child(k), edits)
+		case child != nil:
+			*edits = append(*edits, mergeEdit{path: p.Child(k)})
+		}
+	}
+}
+
+// withoutNulls returns a value without the null values of its mappings.
+func withoutNulls(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return n
+	}
+	c := *n
+	c.Content = nil
+	for j := 0; j+1 < len(n.Content); j += 2 {
+		if !isNull(n.Content[j+1]) {
+			c.Content = append(c.Content, n.Content[j], withoutNulls(n.Content[j+1]))
+		}
+	}
+	return &c
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
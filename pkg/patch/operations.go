@@ -0,0 +1,227 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+)
+
+// operation is an operation of a json patch.
+type operation struct {
+	op    string
+	path  string
+	from  string
+	value *yaml.Node // Nil if the operation has no value
+}
+
+// parseOperations reads the operations of a json patch.
+func parseOperations(root *yaml.Node) ([]operation, error) {
+	var ops []operation
+	for i, item := range root.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("operation %d is not a mapping", i)
+		}
+		var op operation
+		hasPath, hasFrom := false, false
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			k, v := item.Content[j].Value, item.Content[j+1]
+			switch k {
+			case "op", "path", "from":
+				if v.Kind != yaml.ScalarNode || v.ShortTag() != "!!str" {
+					return nil, fmt.Errorf("%s of operation %d must be a string", k, i)
+				}
+				switch k {
+				case "op":
+					op.op = v.Value
+				case "path":
+					op.path, hasPath = v.Value, true
+				default:
+					op.from, hasFrom = v.Value, true
+				}
+			case "value":
+				op.value = yaml.Detach(v)
+			}
+		}
+		switch op.op {
+		case "add", "replace", "test":
+			if op.value == nil {
+				return nil, fmt.Errorf("operation %d (%s) requires a value", i, op.op)
+			}
+		case "move", "copy":
+			if !hasFrom {
+				return nil, fmt.Errorf("operation %d (%s) requires a from path", i, op.op)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d has an unknown op %q", i, op.op)
+		}
+		if !hasPath {
+			return nil, fmt.Errorf("operation %d (%s) requires a path", i, op.op)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// applyOperations applies the operations of a json patch, one after the
+// other, to a document of a yaml content.
+func applyOperations(content string, ops []operation, document int) (string, error) {
+	for i, op := range ops {
+		var err error
+		if content, err = applyOperation(content, op, document); err != nil {
+			return "", fmt.Errorf("operation %d (%s %s): %w", i, op.op, op.path, err)
+		}
+	}
+	return content, nil
+}
+
+// applyOperation applies an operation of a json patch to a document of a yaml
+// content.
+func applyOperation(content string, op operation, document int) (string, error) {
+	root, err := yaml.ContentRoot(content, document)
+	if err != nil {
+		return "", err
+	}
+	switch op.op {
+	case "add":
+		return add(content, root, op.path, op.value, document)
+	case "remove":
+		p, err := resolve(root, op.path, false)
+		if err != nil {
+			return "", err
+		}
+		return yaml.DeleteValue(content, document, p)
+	case "replace":
+		p, err := resolve(root, op.path, false)
+		if err != nil {
+			return "", err
+		}
+		return yaml.EditValue(content, document, p, op.value)
+	case "move", "copy":
+		from, err := resolve(root, op.from, false)
+		if err != nil {
+			return "", fmt.Errorf("from %w", err)
+		}
+		value := yaml.Detach(yaml.Lookup(root, from))
+		if op.op == "move" {
+			if op.path == op.from {
+				return content, nil
+			}
+			if strings.HasPrefix(op.path, op.from+"/") {
+				return "", errors.New("a value cannot be moved into itself")
+			}
+			if content, err = yaml.DeleteValue(content, document, from); err != nil {
+				return "", err
+			}
+			if root, err = yaml.ContentRoot(content, document); err != nil {
+				return "", err
+			}
+		}
+		return add(content, root, op.path, value, document)
+	}
+	// Test
+	p, err := resolve(root, op.path, false)
+	if err != nil {
+		return "", err
+	}
+	actual := yaml.Lookup(root, p)
+	diffs, err := yaml.Compare(actual, op.value)
+	if err != nil {
+		return "", err
+	}
+	if len(diffs) > 0 {
+		return "", fmt.Errorf("test failed: the value is %s, not %s", yaml.FlowString(actual), yaml.FlowString(op.value))
+	}
+	return content, nil
+}
+
+// add adds a value in a json pointer: an element inserted in a list or a value
+// set in a mapping (or the root).
+func add(content string, root *yaml.Node, pointer string, value *yaml.Node, document int) (string, error) {
+	p, err := resolve(root, pointer, true)
+	if err != nil {
+		return "", err
+	}
+	if len(p) > 0 && p[len(p)-1].IsIndex() {
+		return yaml.InsertValue(content, document, p, value)
+	}
+	return yaml.EditValue(content, document, p, value)
+}
+
+// arrayIndex is a valid array index of a json pointer.
+var arrayIndex = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+// resolve returns the path of a RFC 6901 json pointer in a node. The last
+// token of the pointer of an added value can be a missing key or the index
+// after the last element of a list (or "-").
+func resolve(root *yaml.Node, pointer string, add bool) (path.Path, error) {
+	p := path.Path{}
+	if pointer == "" {
+		return p, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q, it must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	n := root
+	for i, token := range tokens {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		last := i == len(tokens)-1
+		if n != nil && n.Kind == yaml.AliasNode {
+			n = n.Alias
+		}
+		switch {
+		case n != nil && n.Kind == yaml.MappingNode:
+			p = p.Child(token)
+			if n = yaml.Lookup(n, path.Path{path.Key(token)}); n == nil && !(last && add) {
+				return nil, fmt.Errorf("%w: %s", yaml.ErrPathNotFound, pointer)
+			}
+		case n != nil && n.Kind == yaml.SequenceNode:
+			size := len(n.Content)
+			index := size
+			if token != "-" || !last || !add {
+				if !arrayIndex.MatchString(token) {
+					return nil, fmt.Errorf("invalid index %q in json pointer %s", token, pointer)
+				}
+				index, _ = strconv.Atoi(token)
+			}
+			if index > size || index == size && !(last && add) {
+				return nil, fmt.Errorf("index %d out of range in json pointer %s with %d items", index, pointer, size)
+			}
+			p = p.Item(index)
+			if index < size {
+				n = n.Content[index]
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", yaml.ErrPathNotFound, pointer)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+// Type is the type of a patch.
+type Type string
+
+const (
+	// AutoPatch detects the type of the patch: a list is a json patch and
+	// anything else a merge patch.
+	AutoPatch Type = "auto"
+	// JSONPatch is a RFC 6902 json patch.
+	JSONPatch Type = "json"
+	// MergePatch is a RFC 7386 json merge patch.
+	MergePatch Type = "merge"
+)
+
+// Types are the valid patch types.
+var Types = []Type{AutoPatch, JSONPatch, MergePatch}
+
+// Validate returns an error if the patch type is unknown.
+func (t Type) Validate() error {
+	if t == "" {
+		return nil
+	}
+	for _, valid := range Types {
+		if t == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown patch type %s, valid types are %v", t, Types)
+}
+
+// Option configures how a patch is applied.
+type Option func(o *options)
+
+type options struct {
+	patchType Type
+	document  int
+}
+
+// WithType configures the type of the patch (AutoPatch by default).
+func WithType(t Type) Option {
+	return func(o *options) {
+		o.patchType = t
+	}
+}
+
+// WithDocument selects the document of a multi-document content that is
+// patched (0-based, the first one by default).
+func WithDocument(index int) Option {
+	return func(o *options) {
+		o.document = index
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Apply returns a yaml content with a patch applied. The patch is a yaml or
+// json content (see format.DetectFormat) with a single document.
+func Apply(content string, patch string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	if err := o.patchType.Validate(); err != nil {
+		return "", err
+	}
+	docs, err := format.ParseDocuments(patch, format.DetectFormat("", patch))
+	if err != nil {
+		return "", fmt.Errorf("invalid patch: %w", err)
+	}
+	if len(docs) != 1 || yaml.Root(docs[0]) == nil {
+		return "", errors.New("invalid patch: a patch must have a single document")
+	}
+	root := yaml.Root(docs[0])
+	t := o.patchType
+	if t == "" || t == AutoPatch {
+		t = MergePatch
+		if root.Kind == yaml.SequenceNode {
+			t = JSONPatch
+		}
+	}
+	if t == MergePatch {
+		return applyMergePatch(content, root, o.document)
+	}
+	ops, err := parseOperations(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid patch: %w", err)
+	}
+	return applyOperations(content, ops, o.document)
+}
+
+// ApplyFile returns the content of a yaml file with a patch file applied.
+func ApplyFile(file string, patchFile string, opts ...Option) (string, error) {
+	content, err := io.ReadAsString(file)
+	if err != nil {
+		return "", err
+	}
+	patch, err := io.ReadAsString(patchFile)
+	if err != nil {
+		return "", err
+	}
+	return Apply(content, patch, opts...)
+}
+
+// ApplyFileInPlace applies a patch file to a yaml file, modifying it. The file
+// is not written if the patch does not change it.
+func ApplyFileInPlace(file string, patchFile string, opts ...Option) error {
+	return applyFileInPlace(file, patchFile, "", opts)
+}
+
+// ApplyFileInPlaceB applies a patch file to a yaml file, creating a backup
+// file with a suffix before modifying the original file.
+func ApplyFileInPlaceB(file string, patchFile string, backupSuffix string, opts ...Option) error {
+	return applyFileInPlace(file, patchFile, backupSuffix, opts)
+}
+
+// applyFileInPlace applies a patch file to a yaml file, creating a backup
+// first if there is a suffix.
+func applyFileInPlace(file string, patchFile string, backupSuffix string, opts []Option) error {
+	content, err := io.ReadAsString(file)
+	if err != nil {
+		return err
+	}
+	patched, err := ApplyFile(file, patchFile, opts...)
+	if err != nil || patched == content {
+		return err
+	}
+	if backupSuffix != "" {
+		if err := io.Copy(file, file+backupSuffix); err != nil {
+			return err
+		}
+	}
+	return io.WriteToFile(file, patched)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package patch
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+const content = `# App config
+app:
+  name: web # the name
+  replicas: 2
+  ports:
+    - 80
+    - 443
+db: {host: localhost, port: 5432}
+`
+
+func TestApplyJSONPatch(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "add key",
+			patch:    `[{op: add, path: /app/debug, value: true}]`,
+			expected: itesting.Replace(t, content, "    - 443\n", "    - 443\n  debug: true\n"),
+		},
+		{
+			name:     "insert item",
+			patch:    `[{op: add, path: /app/ports/1, value: 8080}]`,
+			expected: itesting.Replace(t, content, "    - 80\n", "    - 80\n    - 8080\n"),
+		},
+		{
+			name:     "append item",
+			patch:    `[{"op": "add", "path": "/app/ports/-", "value": 8443}]`,
+			expected: itesting.Replace(t, content, "    - 443\n", "    - 443\n    - 8443\n"),
+		},
+		{
+			name:     "remove",
+			patch:    "- op: remove\n  path: /app/replicas\n",
+			expected: itesting.Replace(t, content, "  replicas: 2\n", ""),
+		},
+		{
+			name:     "replace keeps comment",
+			patch:    `[{op: replace, path: /app/name, value: api}]`,
+			expected: itesting.Replace(t, content, "name: web", "name: api"),
+		},
+		{
+			name:     "replace in flow mapping",
+			patch:    `[{op: replace, path: /db/port, value: 5433}]`,
+			expected: itesting.Replace(t, content, "port: 5432", "port: 5433"),
+		},
+		{
+			name:     "move",
+			patch:    `[{op: move, from: /db/host, path: /app/host}]`,
+			expected: itesting.Replace(t, itesting.Replace(t, content, "    - 443\n", "    - 443\n  host: localhost\n"), "{host: localhost, port: 5432}", "{port: 5432}"),
+		},
+		{
+			name:     "copy",
+			patch:    `[{op: copy, from: /app/ports/0, path: /app/ports/0}]`,
+			expected: itesting.Replace(t, content, "    - 80\n", "    - 80\n    - 80\n"),
+		},
+		{
+			name:     "test and escaped keys",
+			patch:    `[{op: test, path: /db/port, value: 5432}, {op: add, path: /db/a~1b~0c, value: x}]`,
+			expected: itesting.Replace(t, content, "port: 5432}", "port: 5432, a/b~c: x}"),
+		},
+		{
+			name:     "explicit json patch",
+			patch:    `[]`,
+			expected: content,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := Apply(content, test.patch)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, patched)
+		})
+	}
+}
+
+func TestApplyJSONPatchFlowList(t *testing.T) {
+	flow := "list: [x, z, y, on] # list\n"
+	for patch, expected := range map[string]string{
+		`[{op: add, path: /list/1, value: "y"}]`:     `list: [x, "y", z, y, on] # list` + "\n",
+		`[{op: add, path: /list/-, value: n}]`:       `list: [x, z, y, on, "n"] # list` + "\n",
+		`[{op: remove, path: /list/0}]`:              "list: [z, y, on] # list\n",
+		`[{op: move, from: /list/0, path: /list/-}]`: "list: [z, y, on, x] # list\n",
+	} {
+		t.Run(patch, func(t *testing.T) {
+			patched, err := Apply(flow, patch)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, expected, patched)
+		})
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	for _, test := range []struct {
+		patch    string
+		expected string
+	}{
+		{patch: `[{op: add, path: /app/x}]`, expected: "operation 0 (add) requires a value"},
+		{patch: `[{op: copy, path: /app/x}]`, expected: "operation 0 (copy) requires a from path"},
+		{patch: `[{op: remove}]`, expected: "operation 0 (remove) requires a path"},
+		{patch: `[{op: other, path: /a}]`, expected: `operation 0 has an unknown op "other"`},
+		{patch: `[1]`, expected: "operation 0 is not a mapping"},
+		{patch: `[{op: remove, path: app}]`, expected: `invalid json pointer "app", it must start with /`},
+		{patch: `[{op: remove, path: /app/missing}]`, expected: "operation 0 (remove /app/missing): path not found: /app/missing"},
+		{patch: `[{op: add, path: /app/missing/x, value: 1}]`, expected: "path not found: /app/missing/x"},
+		{patch: `[{op: add, path: /app/ports/3, value: 1}]`, expected: "index 3 out of range in json pointer /app/ports/3 with 2 items"},
+		{patch: `[{op: replace, path: /app/ports/01, value: 1}]`, expected: `invalid index "01"`},
+		{patch: `[{op: remove, path: ""}]`, expected: "the root of a document cannot be deleted"},
+		{patch: `[{op: move, from: /app, path: /app/x}]`, expected: "a value cannot be moved into itself"},
+		{patch: `[{op: replace, path: /app/name, value: x}, {op: test, path: /db/port, value: "5432"}]`, expected: `operation 1 (test /db/port): test failed: the value is 5432, not "5432"`},
+	} {
+		t.Run(test.expected, func(t *testing.T) {
+			_, err := Apply(content, test.patch)
+			itesting.AssertError(t, test.expected, err)
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "yaml",
+			patch:    "app:\n  name: api\n  replicas: null\n  tls: {enabled: true, cert: null}\n",
+			expected: itesting.Replace(t, itesting.Replace(t, itesting.Replace(t, content, "name: web", "name: api"), "  replicas: 2\n", ""), "    - 443\n", "    - 443\n  tls:\n    enabled: true\n"),
+		},
+		{
+			name:     "json",
+			patch:    `{"db": {"port": 5433, "user": "admin"}, "missing": null}`,
+			expected: itesting.Replace(t, content, "{host: localhost, port: 5432}", "{host: localhost, port: 5433, user: admin}"),
+		},
+		{
+			name:     "list replaced",
+			patch:    `{"app": {"ports": [8080]}}`,
+			expected: itesting.Replace(t, content, "    - 80\n    - 443\n", "    - 8080\n"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := Apply(content, test.patch)
+			itesting.AssertEqual(t, nil, err)
+			itesting.AssertEqual(t, test.expected, patched)
+		})
+	}
+}
+
+func TestApplyType(t *testing.T) {
+	_, err := Apply(content, `{op: add, path: /a, value: 1}`, WithType(JSONPatch))
+	itesting.AssertError(t, "invalid patch", err)
+	patched, err := Apply("a: 1\n", `[1, 2]`, WithType(MergePatch))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "- 1\n- 2\n", patched)
+	_, err = Apply(content, `{}`, WithType("strategic"))
+	itesting.AssertError(t, "unknown patch type strategic", err)
+	_, err = Apply(content, "a: 1\n---\nb: 2\n")
+	itesting.AssertError(t, "a patch must have a single document", err)
+}
+
+func TestApplyDocument(t *testing.T) {
+	patched, err := Apply("a: 1\n---\na: 1\n", `[{op: replace, path: /a, value: 2}]`, WithDocument(1))
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, "a: 1\n---\na: 2\n", patched)
+}
+
+func TestApplyFileInPlaceB(t *testing.T) {
+	dir := t.TempDir()
+	file := itesting.WriteFile(t, dir, "config.yml", content)
+	patchFile := itesting.WriteFile(t, dir, "patch.json", `[{"op": "replace", "path": "/app/replicas", "value": 3}]`)
+	itesting.AssertEqual(t, nil, ApplyFileInPlaceB(file, patchFile, ".bak"))
+	itesting.AssertEqual(t, itesting.Replace(t, content, "replicas: 2", "replicas: 3"), itesting.ReadFile(t, file))
+	itesting.AssertEqual(t, content, itesting.ReadFile(t, file+".bak"))
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package path evaluates path expressions on yaml documents, selecting the
+// values in a path.
+//
+// A path is a list of segments separated by dots, like "spec.containers[0].name".
+// A segment can be:
+//   - a key: a bare word, where dots, brackets and backslashes are escaped with a
+//     backslash ("a\.b"), or a quoted key ("'a.b'" or "\"a.b\"").
+//   - an index between brackets: "[0]".
+//   - a wildcard, matching any key or index: "*" or "[*]".
+//
+// The root path is the empty string (or a single dot). Aliases and merge keys
+// are followed when selecting values.
+//
+// This is the syntax of the paths of the rest of packages (like the key orders
+// of format or the list strategies of merge).
+package path
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package path
+
+import (
+	ipath "github.com/amplia-iiot/yutil/internal/path"
+	"github.com/amplia-iiot/yutil/internal/yaml"
+	"github.com/amplia-iiot/yutil/pkg/format"
+)
+
+// Path is a parsed path expression.
+type Path struct {
+	p ipath.Path
+}
+
+// Parse parses a path expression.
+func Parse(expr string) (Path, error) {
+	p, err := ipath.Parse(expr)
+	if err != nil {
+		return Path{}, err
+	}
+	return Path{p: p}, nil
+}
+
+// MustParse parses a path expression, panicking if it is invalid.
+func MustParse(expr string) Path {
+	return Path{p: ipath.MustParse(expr)}
+}
+
+// String returns the path expression in its canonical form.
+func (p Path) String() string {
+	return p.p.String()
+}
+
+// Concrete returns whether the path has no wildcards, so it selects a single
+// value at most.
+func (p Path) Concrete() bool {
+	return p.p.Wildcards() == 0
+}
+
+// Match is a value selected by a path.
+type Match struct {
+	Document int        // Position of the document in the stream (0-based)
+	Path     string     // Concrete path of the value (without wildcards)
+	Value    *yaml.Node // Value (the node an alias points to)
+}
+
+// Select returns the values of a document (or a node) in the path, in
+// document order. There are no values if the path is not found.
+func (p Path) Select(doc *yaml.Node) []Match {
+	var matches []Match
+	for _, s := range yaml.Select(doc, p.p) {
+		matches = append(matches, Match{Path: s.Path.String(), Value: s.Node})
+	}
+	return matches
+}
+
+// SelectAll returns the values in the path of every document of a stream, in
+// order.
+func (p Path) SelectAll(docs []*yaml.Node) []Match {
+	var matches []Match
+	for i, doc := range docs {
+		for _, m := range p.Select(doc) {
+			m.Document = i
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// Get returns the values in a path expression of every document of a yaml
+// content, in order. Json and toml contents are also read (see
+// format.DetectFormat).
+func Get(content string, expr string) ([]Match, error) {
+	p, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := format.ParseDocuments(content, format.DetectFormat("", content))
+	if err != nil {
+		return nil, err
+	}
+	return p.SelectAll(docs), nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package path
+
+import (
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse(`a.'b.c'[0].*`)
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, `a."b.c"[0].*`, p.String())
+	itesting.AssertFalse(t, p.Concrete())
+	itesting.AssertTrue(t, MustParse("a.b[1]").Concrete())
+	_, err = Parse("a[")
+	itesting.AssertError(t, "missing ]", err)
+}
+
+func TestGet(t *testing.T) {
+	content := `metadata:
+  labels:
+    app.kubernetes.io/name: web
+base: &b {x: 1}
+other:
+  <<: *b
+  y: *b
+spec:
+  containers:
+  - name: app
+  - name: side
+empty: null
+`
+	for expr, expected := range map[string][]string{
+		"spec.containers[1].name":                  {"spec.containers[1].name=side"},
+		`metadata.labels."app.kubernetes.io/name"`: {`metadata.labels."app.kubernetes.io/name"=web`},
+		`metadata.labels.app\.kubernetes\.io/name`: {`metadata.labels."app.kubernetes.io/name"=web`},
+		"spec.containers[*].name":                  {"spec.containers[0].name=app", "spec.containers[1].name=side"},
+		"spec.containers.*.name":                   {"spec.containers[0].name=app", "spec.containers[1].name=side"},
+		"other.x":                                  {"other.x=1"},
+		"other.y.x":                                {"other.y.x=1"},
+		"empty":                                    {"empty=null"},
+		"":                                         {"=!!map"},
+		"spec.containers[2]":                       nil,
+		"missing.key":                              nil,
+		"empty.key":                                nil,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			matches, err := Get(content, expr)
+			itesting.AssertEqual(t, nil, err)
+			var actual []string
+			for _, m := range matches {
+				value := m.Value.Value
+				if value == "" {
+					value = m.Value.Tag
+				}
+				actual = append(actual, m.Path+"="+value)
+			}
+			itesting.AssertDeepEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestGetDocuments(t *testing.T) {
+	matches, err := Get("{\"a\": 1}\n{\"b\": 2}\n{\"a\": 3}\n", "a")
+	itesting.AssertEqual(t, nil, err)
+	itesting.AssertEqual(t, 2, len(matches))
+	itesting.AssertEqual(t, 0, matches[0].Document)
+	itesting.AssertEqual(t, "1", matches[0].Value.Value)
+	itesting.AssertEqual(t, 2, matches[1].Document)
+	itesting.AssertEqual(t, "3", matches[1].Value.Value)
+}
+
+func TestGetInvalid(t *testing.T) {
+	_, err := Get("a: 1", "a[")
+	itesting.AssertError(t, "missing ]", err)
+	_, err = Get("a: [", "a")
+	itesting.AssertError(t, "did not find expected node content", err)
+}
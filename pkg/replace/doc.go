@@ -22,4 +22,17 @@ SOFTWARE.
 
 // Package replace provides primitives for replacing files using a template engine
 // and using replacements that are defined inside yaml files.
+//
+// Replacement files (and stdin) are merged in ascending level of importance. A
+// replacement file may be a multi-document yaml stream, its documents are
+// merged in order as if they were separate files. The yaml of a markdown
+// replacement file (.md or .markdown) is its front matter.
+//
+// Environment variables can override the merged replacements, merged after
+// every replacement file (see WithEnvOverlay), and replacements can be set in
+// paths as the last layer (see WithSet).
+//
+// The merged replacements must be a mapping. Keys that are not strings are
+// named as they are written in yaml ("80", "true", "null"...), a string key
+// with the same name takes precedence.
 package replace
@@ -23,18 +23,21 @@ SOFTWARE.
 package replace
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
 
 	"github.com/amplia-iiot/yutil/internal/io"
+	"github.com/amplia-iiot/yutil/internal/markdown"
+	"github.com/amplia-iiot/yutil/internal/path"
 	"github.com/amplia-iiot/yutil/internal/replace"
 	"github.com/amplia-iiot/yutil/internal/yaml"
-	"github.com/amplia-iiot/yutil/pkg/merge"
 )
 
-type option func(o *options)
+// Option configures a replace.
+type Option func(o *options)
 
 type Engine int
 
@@ -43,25 +46,29 @@ const (
 	Jinja2
 )
 
+// set is a replacement set in a path.
+type set struct {
+	path  string
+	value string
+	kind  yaml.ValueType
+}
+
 type options struct {
 	replace.Options
 	rootNode                         string
 	replacementFiles                 []string
 	includeStdinInReplacements       bool
 	includeEnvironmentInReplacements bool
+	envPrefix                        string
+	envSeparator                     string
+	sets                             []set
 	extensions                       []string
 }
 
 func (o *options) changeReplacementsRootNode() error {
 	if node, ok := o.Options.Replacements[o.rootNode]; ok {
 		if node, ok := node.(map[any]any); ok {
-			reps := map[string]interface{}{}
-			for k, v := range node {
-				if name, ok := k.(string); ok {
-					reps[name] = v
-				}
-			}
-			o.Options.Replacements = reps
+			o.Options.Replacements = replace.SanitizeKeys(node)
 		} else {
 			return fmt.Errorf("node %s does not contain more elements", o.rootNode)
 		}
@@ -72,80 +79,124 @@ func (o *options) changeReplacementsRootNode() error {
 }
 
 // WithDirectory configures the root directory to search for files to be replaced (defaults to current directory).
-func WithDirectory(directory string) option {
+func WithDirectory(directory string) Option {
 	return func(o *options) {
 		o.Directory = directory
 	}
 }
 
 // WithInclude configures the glob pattern for files to be included.
-func WithInclude(pattern ...string) option {
+func WithInclude(pattern ...string) Option {
 	return func(o *options) {
 		o.Include = append(o.Include, pattern...)
 	}
 }
 
 // WithExclude configures the glob pattern for files to be excluded.
-func WithExclude(pattern ...string) option {
+func WithExclude(pattern ...string) Option {
 	return func(o *options) {
 		o.Exclude = append(o.Exclude, pattern...)
 	}
 }
 
 // WithRootNode configures the root node to include only replacements from inside that node.
-func WithRootNode(node string) option {
+func WithRootNode(node string) Option {
 	return func(o *options) {
 		o.rootNode = node
 	}
 }
 
 // WithReplacementFile adds a file to be used as replacement file.
-func WithReplacementFile(file string) option {
+func WithReplacementFile(file string) Option {
 	return func(o *options) {
 		o.replacementFiles = append(o.replacementFiles, file)
 	}
 }
 
 // WithReplacementFiles adds multiple files to be used as replacement files (they will be merged).
-func WithReplacementFiles(files ...string) option {
+func WithReplacementFiles(files ...string) Option {
 	return func(o *options) {
 		o.replacementFiles = append(o.replacementFiles, files...)
 	}
 }
 
 // IncludeStdinInReplacements includes stdin to be used as replacement file.
-func IncludeStdinInReplacements() option {
+func IncludeStdinInReplacements() Option {
 	return WithIncludeStdinInReplacements(true)
 }
 
 // WithIncludeStdinInReplacements configures whether to use stdin as replacement file.
-func WithIncludeStdinInReplacements(include bool) option {
+func WithIncludeStdinInReplacements(include bool) Option {
 	return func(o *options) {
 		o.includeStdinInReplacements = include
 	}
 }
 
 // IncludeEnvironmentInReplacements includes all environment variables to be used in the template engine inside the env node.
-func IncludeEnvironmentInReplacements(include bool) option {
+func IncludeEnvironmentInReplacements(include bool) Option {
 	return WithIncludeEnvironmentInReplacements(true)
 }
 
 // WithIncludeEnvironmentInReplacements configures whether to use include all environment variables to be used in the template engine inside the env node.
-func WithIncludeEnvironmentInReplacements(include bool) option {
+func WithIncludeEnvironmentInReplacements(include bool) Option {
 	return func(o *options) {
 		o.includeEnvironmentInReplacements = include
 	}
 }
 
+// WithEnvOverlay configures the overlay of environment variables, merged after
+// the replacement files as the most important replacements (no overlay if the
+// prefix is empty). A variable named with the prefix followed by the separator
+// ("__" if empty), like APP__DB__HOST with prefix APP, sets the replacement of
+// the path of the rest of its name (db.host), matching the keys ignoring case.
+// The type of the value is inferred as in a yaml scalar.
+func WithEnvOverlay(prefix string, separator string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+		o.envSeparator = separator
+	}
+}
+
+// WithSet sets the replacement of a path after merging the replacement files and
+// the environment overlay, like "a.b[0].c". The type of the value is inferred
+// as in a yaml scalar and a value in yaml flow style is a list or a mapping.
+// The missing keys of the path are added, an index one past the end of a list
+// appends the value.
+func WithSet(path string, value string) Option {
+	return withSet(path, value, yaml.InferredValue)
+}
+
+// WithSetString sets a string replacement in a path as WithSet.
+func WithSetString(path string, value string) Option {
+	return withSet(path, value, yaml.StringValue)
+}
+
+// WithSetJSON sets a json replacement in a path as WithSet.
+func WithSetJSON(path string, value string) Option {
+	return withSet(path, value, yaml.JSONValue)
+}
+
+// WithSetFile sets the content of a file as string replacement in a path as
+// WithSet.
+func WithSetFile(path string, file string) Option {
+	return withSet(path, file, yaml.FileValue)
+}
+
+func withSet(path string, value string, kind yaml.ValueType) Option {
+	return func(o *options) {
+		o.sets = append(o.sets, set{path: path, value: value, kind: kind})
+	}
+}
+
 // WithExtension configures the extensions to be removed from a file name when it's saved after being passed through the template engine.
-func WithExtension(extension ...string) option {
+func WithExtension(extension ...string) Option {
 	return func(o *options) {
 		o.extensions = append(o.extensions, extension...)
 	}
 }
 
 // Replace uses the template engine to replace files following the optional configuration.
-func Replace(engine Engine, opts ...option) (err error) {
+func Replace(engine Engine, opts ...Option) (err error) {
 	o := &options{
 		Options: replace.Options{
 			Directory: ".",
@@ -154,29 +205,30 @@ func Replace(engine Engine, opts ...option) (err error) {
 	for _, opt := range opts {
 		opt(o)
 	}
-	var replacements string
 	switch engine {
 	case Golang:
 		o.Engine = replace.Golang
 	case Jinja2:
 		o.Engine = replace.Jinja2
 	}
+	var contents []string
 	if o.includeStdinInReplacements {
-		if len(o.replacementFiles) > 0 {
-			replacements, err = merge.MergeStdinWithFiles(o.replacementFiles)
-		} else {
-			replacements, err = io.ReadStdin()
+		var stdin string
+		if stdin, err = io.ReadStdin(); err != nil {
+			return
 		}
-	} else {
-		switch len(o.replacementFiles) {
-		case 0:
-			err = fmt.Errorf("no replacement files defined")
-		case 1:
-			replacements, err = io.ReadAsString(o.replacementFiles[0])
-		default:
-			replacements, err = merge.MergeAllFiles(o.replacementFiles)
+		contents = append(contents, stdin)
+	} else if len(o.replacementFiles) == 0 && o.envPrefix == "" && len(o.sets) == 0 {
+		return fmt.Errorf("no replacement files defined")
+	}
+	for _, file := range o.replacementFiles {
+		var content string
+		if content, err = markdown.ReadYAML(file); err != nil {
+			return
 		}
+		contents = append(contents, content)
 	}
+	replacements, err := o.mergeReplacements(contents)
 	if err != nil {
 		return
 	}
@@ -216,3 +268,60 @@ func Replace(engine Engine, opts ...option) (err error) {
 	}
 	return replace.Replace(o.Options)
 }
+
+// mergeReplacements merges every document of the replacement contents, in
+// ascending level of importance, into a single yaml with the environment
+// overlay and the values set. A single document with nothing to merge is
+// returned as it is, so its values are read as in a plain yaml file.
+func (o *options) mergeReplacements(contents []string) (string, error) {
+	if len(contents) == 1 && o.envPrefix == "" && len(o.sets) == 0 {
+		if stream, err := yaml.ParseNodes(contents[0]); err == nil && len(stream) <= 1 {
+			return contents[0], nil
+		}
+	}
+	var docs []*yaml.Node
+	for _, content := range contents {
+		stream, err := yaml.ParseNodes(content)
+		if err != nil {
+			return "", err
+		}
+		for _, doc := range stream {
+			if err = yaml.Normalize(doc); err != nil {
+				return "", err
+			}
+		}
+		docs = append(docs, stream...)
+	}
+	merged, err := yaml.Flatten(docs, yaml.MergeOptions{})
+	if err != nil {
+		return "", err
+	}
+	if o.envPrefix != "" {
+		for _, v := range yaml.ParseEnv(os.Environ(), o.envPrefix, o.envSeparator) {
+			if merged, err = yaml.MergeNodes(merged, v.Document(merged), yaml.MergeOptions{}); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, s := range o.sets {
+		p, err := path.Parse(s.path)
+		if err != nil {
+			return "", err
+		}
+		value, err := yaml.ParseValue(s.value, s.kind)
+		if err != nil {
+			return "", fmt.Errorf("invalid value of %s: %w", s.path, err)
+		}
+		changes := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{value}}
+		if merged, err = yaml.SetNode(merged, p, changes, yaml.MergeOptions{}); err != nil {
+			return "", err
+		}
+	}
+	// Replacements are accessed by name
+	if root := yaml.Root(merged); root != nil && root.Kind == yaml.SequenceNode {
+		return "", errors.New("replacements must be a mapping, not a sequence")
+	} else if root != nil && root.Kind != yaml.MappingNode {
+		return "", errors.New("replacements must be a mapping, not a scalar")
+	}
+	return yaml.ComposeNode(merged, yaml.Style{})
+}
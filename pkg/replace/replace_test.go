@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2026 amplia-iiot
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package replace
+
+import (
+	"path/filepath"
+	"testing"
+
+	itesting "github.com/amplia-iiot/yutil/internal/testing"
+)
+
+func TestReplaceQuotedStrings(t *testing.T) {
+	for name, i := range map[string]struct {
+		replacements []string
+		expected     string
+	}{
+		"single file": {
+			replacements: []string{"a: \"on\"\nb: 'yes'\nc: \"n\"\nd: on\n"},
+			expected:     "on yes n true",
+		},
+		"merged files": {
+			replacements: []string{"a: \"on\"\nb: 'yes'\n", "c: \"n\"\nd: true\n"},
+			expected:     "on yes n true",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			var files []string
+			for j, content := range i.replacements {
+				files = append(files, itesting.WriteFile(t, dir, "values"+string(rune('1'+j))+".yml", content))
+			}
+			template := itesting.WriteFile(t, dir, "templates/values.txt", "{{ .a }} {{ .b }} {{ .c }} {{ .d }}")
+			templates := filepath.Dir(template)
+			err := Replace(Golang, WithDirectory(templates), WithReplacementFiles(files...))
+			itesting.AssertError(t, "", err)
+			itesting.AssertEqual(t, i.expected, itesting.ReadFile(t, template))
+		})
+	}
+}